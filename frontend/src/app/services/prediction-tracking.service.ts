@@ -2,6 +2,7 @@ import { Injectable } from '@angular/core';
 import { HttpClient, HttpParams } from '@angular/common/http';
 import { Observable } from 'rxjs';
 import { environment } from '../../environments/environment';
+import { PredictionInterval } from './stock-prediction.service';
 
 export interface PredictionAccuracySummary {
   symbol: string;
@@ -13,6 +14,25 @@ export interface PredictionAccuracySummary {
   best_accuracy: number;
   worst_accuracy: number;
   last_prediction_date?: string;
+  horizons?: HorizonAccuracy[];
+  intervals?: IntervalAccuracy[];
+}
+
+export interface HorizonAccuracy {
+  horizon_days: number; // Trading days ahead when predicted
+  total_predictions: number;
+  predictions_with_actual: number;
+  accuracy_mape: number;
+  direction_accuracy: number;
+  intervals?: IntervalAccuracy[];
+}
+
+export interface IntervalAccuracy {
+  coverage: number; // Nominal, e.g. 0.8
+  total_intervals: number;
+  intervals_with_actual: number;
+  observed_coverage: number; // Percentage of actual closes inside the band
+  average_width_percent: number; // Band width as a percentage of the predicted price
 }
 
 export interface PredictionPerformanceMetrics {
@@ -24,6 +44,8 @@ export interface PredictionPerformanceMetrics {
   symbol_summaries: PredictionAccuracySummary[];
   last_execution_date?: string;
   last_execution_status: string;
+  horizons?: HorizonAccuracy[];
+  intervals?: IntervalAccuracy[];
 }
 
 export interface DailyPredictionStatus {
@@ -41,13 +63,19 @@ export interface DailyPredictionStatus {
 export interface PredictionTracking {
   id: number;
   symbol: string;
-  prediction_date: string;
+  prediction_date: string; // Session whose close is predicted
+  horizon_days: number; // 1, 5 or 20 trading days ahead
   predicted_price?: number;
   predicted_direction?: string;
   confidence?: number;
   actual_close?: number;
   accuracy_mape?: number;
   direction_correct?: boolean;
+  residual_percent?: number; // Signed error, (actual - predicted) / predicted * 100
+  intervals?: PredictionInterval[];
+  model?: string; // Model that produced the prediction
+  model_version?: string;
+  model_version_id?: number; // Model registry id, when registered
   market_was_open: boolean;
   prediction_timestamp: string;
   actual_price_timestamp?: string;
@@ -122,10 +150,11 @@ export class PredictionTrackingService {
     return this.http.get<PredictionPerformanceMetrics>(`${this.baseUrl}/predictions/performance`);
   }
 
-  getPredictionHistory(symbol: string, startDate?: string, endDate?: string): Observable<PredictionTracking[]> {
+  getPredictionHistory(symbol: string, startDate?: string, endDate?: string, horizon?: number): Observable<PredictionTracking[]> {
     let params = new HttpParams();
     if (startDate) params = params.set('start_date', startDate);
     if (endDate) params = params.set('end_date', endDate);
+    if (horizon) params = params.set('horizon', horizon.toString());
     
     return this.http.get<PredictionTracking[]>(`${this.baseUrl}/predictions/history/${symbol}`, { params });
   }
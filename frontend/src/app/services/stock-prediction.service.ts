@@ -10,12 +10,155 @@ export interface PredictionResponse {
   trading_signal: string; // Backend uses 'trading_signal'
   confidence: number;
   prediction_time: string; // Backend uses 'prediction_time'
-  model_version: string;
+  model_version: string; // Production registry version, or the built-in version
+  model_version_id?: number; // Model registry id, when registered
+  model?: string; // Model that produced the prediction
+  data_quality?: DataQualityReport;
+  asset_class?: string; // equity, index, crypto, currency or future
+  exchange?: string; // Listing exchange code, e.g. 'US' or 'TWSE'
+  currency?: string; // Currency of the prices, e.g. 'TWD' or 'GBp' (pence)
+  native_currency?: string; // Quote currency when converted with ?currency=
+  fx_rate?: number;
+  event_risk?: EventRisk; // Corporate events on or just before the target date
+  diagnostics?: PredictionDiagnostics; // Only with ?debug=true
+  forecasts?: ForecastPoint[]; // Only with ?horizons=
+  intervals?: PredictionInterval[]; // Bands around predicted_price at each configured coverage
+  ensemble?: EnsembleResult; // Only from the ensemble model
   // Extended properties for UI
   signal?: string; // Alias for trading_signal
   timestamp?: Date; // Converted from prediction_time
 }
 
+export interface ForecastPoint {
+  horizon_days: number; // Trading days after the last bar
+  target_date: string; // Session whose close is forecast
+  predicted_price: number;
+  change_percent: number; // From the current price
+  trading_signal: string;
+  confidence: number; // Falls with the horizon
+  intervals?: PredictionInterval[];
+}
+
+export interface PredictionInterval {
+  coverage: number; // Nominal coverage, e.g. 0.8
+  lower: number;
+  upper: number;
+  source: string; // 'model' quantiles or past 'residuals'
+  covered?: boolean; // Whether the actual close fell inside, once scored
+}
+
+export interface EnsembleMember {
+  model: string;
+  model_version?: string;
+  model_version_id?: number;
+  predicted_price?: number;
+  weight: number; // Share of the blend; 0 when the model failed
+  rolling_mape?: number; // Over its recent scored predictions for the symbol
+  samples: number;
+  error?: string;
+}
+
+export interface EnsembleResult {
+  members: EnsembleMember[];
+  weighting: string; // 'inverse_mape' or 'equal'
+  disagreement: number; // Weighted std dev of member predictions, % of the blend
+}
+
+export interface ModelQuantile {
+  level: number; // e.g. 0.1 for the 10th percentile
+  price: number;
+}
+
+export interface ModelOutput {
+  version: number; // 0 for legacy scripts printing a bare price
+  predicted_price: number;
+  quantiles?: ModelQuantile[];
+  model_version?: string;
+  features?: { [name: string]: number };
+  warnings?: string[];
+  timing_ms?: { [stage: string]: number };
+  ensemble?: EnsembleResult;
+}
+
+export interface PredictionDiagnostics {
+  backend: string; // script, http, native or ensemble
+  input: string; // closes or ohlcv
+  data_points: number;
+  duration_ms: number;
+  version: string; // of the model that ran
+  version_id?: number; // model registry id
+  artifact?: string; // registry artifact the model loaded
+  output: ModelOutput;
+}
+
+export interface ModelInfo {
+  name: string;
+  version: string;
+  description: string;
+  features: string[];
+  config?: { [key: string]: any }; // backend, default, recommended data points
+  registry?: ModelVersion; // Production registry version, when one is promoted
+}
+
+export interface ModelVersion {
+  id: number;
+  name: string;
+  version: string; // Semantic version, e.g. '1.4.0'
+  artifact_path?: string;
+  sha256?: string;
+  training_start?: string;
+  training_end?: string;
+  metrics?: { [name: string]: number };
+  status: string; // 'staging', 'production' or 'retired'
+  created_at: string;
+  promoted_at?: string;
+  rolled_back_at?: string;
+}
+
+export interface ModelsResponse {
+  default: string;
+  models: ModelInfo[];
+}
+
+export interface CorporateEvent {
+  symbol: string;
+  date: string;
+  type: string; // 'earnings', 'ex_dividend' or 'split'
+  timing?: string; // Earnings: 'bmo' (before open) or 'amc' (after close)
+  description?: string;
+  source: string;
+  updated_at: string;
+}
+
+export interface EventRisk {
+  target_date: string;
+  flagged: boolean;
+  events?: CorporateEvent[];
+  confidence_factor?: number; // Applied to confidence when down-weighting is enabled
+}
+
+export interface UpcomingEventsResponse {
+  symbol: string;
+  days: number;
+  events: CorporateEvent[];
+  count: number;
+}
+
+export interface BarIssue {
+  timestamp: string;
+  reason: string;
+  value?: number;
+}
+
+export interface DataQualityReport {
+  symbol: string;
+  gap_policy?: string;
+  total_bars: number;
+  gaps: BarIssue[];
+  fills: BarIssue[];
+  suspicious: BarIssue[];
+}
+
 export interface HistoricalDataItem {
   symbol: string;
   timestamp: string; // Backend uses 'timestamp'
@@ -25,6 +168,7 @@ export interface HistoricalDataItem {
   low: number;
   close: number;
   volume: number;
+  filled?: boolean; // Synthesised by the backend gap policy
   change?: number; // Calculated field
 }
 
@@ -33,6 +177,42 @@ export interface HistoricalData {
   count: number; // Backend includes count
   days: number;  // Backend includes days
   data: HistoricalDataItem[];
+  data_quality?: DataQualityReport;
+  currency?: string;
+  native_currency?: string;
+}
+
+export interface SymbolMetadata {
+  symbol: string;
+  long_name?: string;
+  short_name?: string;
+  asset_class: string;
+  exchange_code: string; // Parsed from the ticker, e.g. 'TWSE'
+  exchange?: string; // Provider exchange name, e.g. 'NasdaqGS'
+  currency?: string;
+  timezone?: string;
+  instrument_type?: string;
+  first_trade_date?: string;
+  source: string;
+  updated_at: string;
+}
+
+export interface SymbolSearchResponse {
+  query: string;
+  results: SymbolMetadata[];
+  count: number;
+}
+
+export interface StreamQuote {
+  symbol: string;
+  price: number;
+  previous_close?: number;
+  change: number;
+  change_percent: number;
+  currency: string;
+  market_open: boolean;
+  timestamp: string;
+  synthetic?: boolean;
 }
 
 export interface ServiceStats {
@@ -42,16 +222,30 @@ export interface ServiceStats {
   average_response_time: string;
   active_symbols: string[];
   last_prediction_time: string;
+  python_workers?: PythonWorkerStats;
   response_time?: string; // Alias for average_response_time
 }
 
+export interface PythonWorkerStats {
+  size: number;
+  live: number; // Running worker processes
+  busy: number;
+  started: number; // Includes replacements after crashes, timeouts and recycling
+  requests: number;
+  failures: number;
+  timeouts: number;
+  crashes: number;
+  recycled: number;
+}
+
 export interface HealthStatus {
   status: string;
   timestamp: string;
   version: string;
   services: {
     prediction_service: string;
-    yahoo_api: string;
+    market_data: string;
+    market_data_provider: string;
   };
 }
 
@@ -73,8 +267,19 @@ export class StockPredictionService {
   /**
    * Get stock prediction
    */
-  getPrediction(symbol: string): Observable<PredictionResponse> {
-    return this.http.get<PredictionResponse>(`${this.apiUrl}/api/v1/predict/${symbol}`)
+  getPrediction(symbol: string, currency?: string, model?: string, horizons?: number[]): Observable<PredictionResponse> {
+    const params: string[] = [];
+    if (currency) {
+      params.push(`currency=${encodeURIComponent(currency)}`);
+    }
+    if (model) {
+      params.push(`model=${encodeURIComponent(model)}`);
+    }
+    if (horizons && horizons.length) {
+      params.push(`horizons=${horizons.join(',')}`);
+    }
+    const query = params.length ? `?${params.join('&')}` : '';
+    return this.http.get<PredictionResponse>(`${this.apiUrl}/api/v1/predict/${encodeURIComponent(symbol)}${query}`)
       .pipe(
         retry(2),
         catchError(this.handleError)
@@ -84,8 +289,9 @@ export class StockPredictionService {
   /**
    * Get historical stock data
    */
-  getHistoricalData(symbol: string, days: number = 60): Observable<HistoricalDataItem[]> {
-    return this.http.get<HistoricalData>(`${this.apiUrl}/api/v1/historical/${symbol}?days=${days}`)
+  getHistoricalData(symbol: string, days: number = 60, currency?: string): Observable<HistoricalDataItem[]> {
+    const query = currency ? `&currency=${encodeURIComponent(currency)}` : '';
+    return this.http.get<HistoricalData>(`${this.apiUrl}/api/v1/historical/${encodeURIComponent(symbol)}?days=${days}${query}`)
       .pipe(
         retry(2),
         catchError(this.handleError),
@@ -94,6 +300,69 @@ export class StockPredictionService {
       );
   }
 
+  /**
+   * Get instrument metadata; a 404 means the symbol is unknown
+   */
+  getSymbol(symbol: string): Observable<SymbolMetadata> {
+    return this.http.get<SymbolMetadata>(`${this.apiUrl}/api/v1/symbols/${encodeURIComponent(symbol)}`)
+      .pipe(
+        catchError(this.handleError)
+      );
+  }
+
+  /**
+   * Search symbols by ticker prefix or company name
+   */
+  searchSymbols(query: string, limit: number = 10): Observable<SymbolMetadata[]> {
+    return this.http.get<SymbolSearchResponse>(`${this.apiUrl}/api/v1/symbols/search?q=${encodeURIComponent(query)}&limit=${limit}`)
+      .pipe(
+        catchError(this.handleError),
+        map((response: SymbolSearchResponse) => response.results || [])
+      );
+  }
+
+  /**
+   * List the prediction models that can be requested
+   */
+  getModels(): Observable<ModelsResponse> {
+    return this.http.get<ModelsResponse>(`${this.apiUrl}/api/v1/models`)
+      .pipe(
+        catchError(this.handleError)
+      );
+  }
+
+  /**
+   * Get earnings, ex-dividend and split dates over the next N days
+   */
+  getUpcomingEvents(symbol: string, days: number = 90): Observable<CorporateEvent[]> {
+    return this.http.get<UpcomingEventsResponse>(`${this.apiUrl}/api/v1/events/${encodeURIComponent(symbol)}?days=${days}`)
+      .pipe(
+        catchError(this.handleError),
+        map((response: UpcomingEventsResponse) => response.events || [])
+      );
+  }
+
+  /**
+   * Stream live quotes over Server-Sent Events. EventSource reconnects on its
+   * own and resumes from the last received event; unsubscribing closes it.
+   */
+  streamQuotes(symbols: string[]): Observable<StreamQuote> {
+    const url = `${this.apiUrl}/api/v1/stream/quotes?symbols=${symbols.map(encodeURIComponent).join(',')}`;
+    return new Observable<StreamQuote>(subscriber => {
+      const source = new EventSource(url);
+      source.addEventListener('quote', (event: MessageEvent) => {
+        subscriber.next(JSON.parse(event.data) as StreamQuote);
+      });
+      source.onerror = () => {
+        // CLOSED means the server rejected the request rather than dropped it
+        if (source.readyState === EventSource.CLOSED) {
+          subscriber.error(new Error('Quote stream closed by the server.'));
+        }
+      };
+      return () => source.close();
+    });
+  }
+
   /**
    * Get service health status
    */
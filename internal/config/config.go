@@ -5,16 +5,23 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// ModeSandbox serves synthetic market data and marks every response as synthetic
+const ModeSandbox = "sandbox"
+
 type Config struct {
+	Mode string `json:"mode"` // live or sandbox
+
 	Server struct {
 		Port         int           `json:"port"`
 		ReadTimeout  time.Duration `json:"read_timeout"`
 		WriteTimeout time.Duration `json:"write_timeout"`
+		AdminToken   string        `json:"admin_token"` // Bearer token for admin endpoints; empty allows local clients only
 	} `json:"server"`
 
 	Stock struct {
@@ -28,22 +35,93 @@ type Config struct {
 
 	API struct {
 		Timeout     time.Duration `json:"timeout"`
+		AttemptTimeout time.Duration `json:"attempt_timeout"` // Deadline for a single fetch attempt
+		MaxRetryAfter  time.Duration `json:"max_retry_after"` // Cap on server-requested Retry-After waits
 		UserAgent   string        `json:"user_agent"`
 		BaseURL     string        `json:"base_url"`
+		// Record/replay of vendor HTTP exchanges for offline development and CI
+		FixtureMode string `json:"fixture_mode"` // live, record or replay
+		FixtureDir  string `json:"fixture_dir"`
 	} `json:"api"`
 
+	MarketData struct {
+		Providers []string `json:"providers"` // Ordered failover chain: yahoo, csv
+		CSVDir    string   `json:"csv_dir"`   // Directory of <SYMBOL>.csv files for the csv provider
+		// Persistent bar store in front of the providers
+		StoreEnabled bool          `json:"store_enabled"`
+		SyncInterval time.Duration `json:"sync_interval"` // Minimum time between incremental syncs per symbol
+		BackfillDays int           `json:"backfill_days"` // Days fetched on the first sync of a symbol
+		AdjustPrices bool          `json:"adjust_prices"` // Default to split/dividend adjusted series
+		BatchWorkers int           `json:"batch_workers"` // Concurrent fetches for multi-symbol requests
+		// Circuit breaker around the Yahoo Finance client
+		BreakerEnabled          bool          `json:"breaker_enabled"`
+		BreakerFailureThreshold int           `json:"breaker_failure_threshold"`  // Consecutive failures before opening
+		BreakerOpenTimeout      time.Duration `json:"breaker_open_timeout"`       // Time open before probing again
+		BreakerHalfOpenRequests int           `json:"breaker_half_open_requests"` // Concurrent probes while half-open
+		// Data quality
+		GapPolicy      string  `json:"gap_policy"`      // Missing closes: drop, forward_fill, interpolate
+		SpikeThreshold float64 `json:"spike_threshold"` // Bar-to-bar move flagged as suspicious (fraction)
+		// Daily FX rates for ?currency= conversion, fetched as currency pairs
+		FXRateTTL time.Duration `json:"fx_rate_ttl"` // How long a fetched rate series is reused
+	} `json:"market_data"`
+
+	// Synthetic price process used in sandbox mode
+	Sandbox struct {
+		Drift         float64 `json:"drift"`          // Annualised drift
+		Volatility    float64 `json:"volatility"`     // Annualised volatility
+		JumpIntensity float64 `json:"jump_intensity"` // Expected jumps per year
+		JumpSize      float64 `json:"jump_size"`      // Jump standard deviation in log price
+		BaseVolume    int64   `json:"base_volume"`    // Typical daily volume
+		Seed          int64   `json:"seed"`
+	} `json:"sandbox"`
+
+	// Server-Sent Events quote stream
+	Stream struct {
+		OpenInterval   time.Duration `json:"open_interval"`   // Poll cadence while the market is open
+		ClosedInterval time.Duration `json:"closed_interval"` // Poll cadence outside market hours
+		Heartbeat      time.Duration `json:"heartbeat"`       // Comment sent to idle connections
+		BufferSize     int           `json:"buffer_size"`     // Events queued per subscriber before dropping it
+		History        int           `json:"history"`         // Events kept per symbol for Last-Event-ID replay
+	} `json:"stream"`
+
+	// Corporate event calendar used to flag predictions around earnings
+	Events struct {
+		PostEventDays    int           `json:"post_event_days"`   // Trading days after an event that stay flagged
+		DownWeight       bool          `json:"down_weight"`       // Scale confidence of flagged predictions
+		ConfidenceFactor float64       `json:"confidence_factor"` // Scale applied when down-weighting
+		RefreshInterval  time.Duration `json:"refresh_interval"`  // Minimum time between provider refreshes per symbol
+		LookaheadDays    int           `json:"lookahead_days"`    // Default window of the upcoming events endpoint
+	} `json:"events"`
+
 	ML struct {
 		PythonScript    string        `json:"python_script"`
 		ModelPath       string        `json:"model_path"`
 		ScalerPath      string        `json:"scaler_path"`
+		ModelRoot       string        `json:"model_root"` // Directory registered model artifacts must be under
 		PredictionTTL   time.Duration `json:"prediction_ttl"`
 		// New prediction model configuration
 		Model           string `json:"model"`           // simple, enhanced, advanced
-		UseOHLCVData    bool   `json:"use_ohlcv_data"`  // Use full OHLCV data instead of just close prices
+		UseOHLCVData    bool   `json:"use_ohlcv_data"`  // Send OHLCV bars to models that declare they take them
 		MaxDataPoints   int    `json:"max_data_points"` // Maximum historical data points to use
 		MinDataPoints   int    `json:"min_data_points"` // Minimum historical data points required
-		EnableEnsemble  bool   `json:"enable_ensemble"` // Enable ensemble prediction
+		EnableEnsemble  bool   `json:"enable_ensemble"` // Serve the ensemble model, and make it the default
 		DebugMode       bool   `json:"debug_mode"`      // Enable debug output
+		// Persistent Python worker pool
+		WorkerScript      string        `json:"worker_script"`       // Worker loop hosting the model scripts
+		WorkerPoolSize    int           `json:"worker_pool_size"`    // Number of Python worker processes
+		WorkerTimeout     time.Duration `json:"worker_timeout"`      // Deadline per prediction before the worker is killed
+		WorkerMaxRequests int           `json:"worker_max_requests"` // Recycle a worker after this many predictions (0 never)
+		// Additional models, each "name=script:path" or "name=http:base-url"
+		Models      []string      `json:"models"`
+		HTTPTimeout time.Duration `json:"http_timeout"` // Deadline per request to a remote model server
+		// Prediction intervals, from model quantiles or else past residuals
+		IntervalCoverages      []float64 `json:"interval_coverages"`       // Nominal coverage of each band, e.g. 0.8 and 0.95
+		IntervalResidualWindow int       `json:"interval_residual_window"` // Most recent scored predictions used for residual bands
+		IntervalMinResiduals   int       `json:"interval_min_residuals"`   // Fewer scored predictions give no residual band
+		// Ensemble of models weighted by their recent tracked error
+		EnsembleModels     []string `json:"ensemble_models"`      // Member model names
+		EnsembleWindow     int      `json:"ensemble_window"`      // Most recent scored predictions behind each member's rolling MAPE
+		EnsembleMinSamples int      `json:"ensemble_min_samples"` // Fewer scored predictions give the member an average weight
 	} `json:"ml"`
 
 	Logging struct {
@@ -59,9 +137,12 @@ func Load() (*Config, error) {
 	config := &Config{}
 
 	// Set defaults
+	config.Mode = getEnvString("SERVICE_MODE", "live")
+
 	config.Server.Port = getEnvInt("SERVER_PORT", 8080)
 	config.Server.ReadTimeout = getEnvDuration("SERVER_READ_TIMEOUT", 10*time.Second)
 	config.Server.WriteTimeout = getEnvDuration("SERVER_WRITE_TIMEOUT", 10*time.Second)
+	config.Server.AdminToken = getEnvString("SERVER_ADMIN_TOKEN", "")
 
 	config.Stock.Symbol = getEnvString("STOCK_SYMBOL", "NVDA")
 	config.Stock.LookbackDays = getEnvInt("STOCK_LOOKBACK_DAYS", 5)
@@ -71,19 +152,70 @@ func Load() (*Config, error) {
 	config.Stock.RequestsPerSec = getEnvInt("STOCK_REQUESTS_PER_SEC", 10)
 
 	config.API.Timeout = getEnvDuration("API_TIMEOUT", 30*time.Second)
+	config.API.AttemptTimeout = getEnvDuration("API_ATTEMPT_TIMEOUT", 10*time.Second)
+	config.API.MaxRetryAfter = getEnvDuration("API_MAX_RETRY_AFTER", 60*time.Second)
 	config.API.UserAgent = getEnvString("API_USER_AGENT", "StockPredictor/3.0")
 	config.API.BaseURL = getEnvString("API_BASE_URL", "https://query1.finance.yahoo.com")
+	config.API.FixtureMode = getEnvString("API_FIXTURE_MODE", "live")
+	config.API.FixtureDir = getEnvString("API_FIXTURE_DIR", "testdata/fixtures/yahoo")
+
+	config.MarketData.Providers = getEnvList("MARKET_DATA_PROVIDERS", []string{"yahoo"})
+	config.MarketData.CSVDir = getEnvString("MARKET_DATA_CSV_DIR", "persistent_data/market_data")
+	config.MarketData.StoreEnabled = getEnvBool("MARKET_DATA_STORE_ENABLED", true)
+	config.MarketData.SyncInterval = getEnvDuration("MARKET_DATA_SYNC_INTERVAL", 15*time.Minute)
+	config.MarketData.BackfillDays = getEnvInt("MARKET_DATA_BACKFILL_DAYS", 730)
+	config.MarketData.AdjustPrices = getEnvBool("MARKET_DATA_ADJUST_PRICES", true)
+	config.MarketData.BatchWorkers = getEnvInt("MARKET_DATA_BATCH_WORKERS", 4)
+	config.MarketData.BreakerEnabled = getEnvBool("MARKET_DATA_BREAKER_ENABLED", true)
+	config.MarketData.BreakerFailureThreshold = getEnvInt("MARKET_DATA_BREAKER_FAILURE_THRESHOLD", 5)
+	config.MarketData.BreakerOpenTimeout = getEnvDuration("MARKET_DATA_BREAKER_OPEN_TIMEOUT", 30*time.Second)
+	config.MarketData.BreakerHalfOpenRequests = getEnvInt("MARKET_DATA_BREAKER_HALF_OPEN_REQUESTS", 1)
+	config.MarketData.GapPolicy = getEnvString("MARKET_DATA_GAP_POLICY", "forward_fill")
+	config.MarketData.SpikeThreshold = getEnvFloat("MARKET_DATA_SPIKE_THRESHOLD", 0.25)
+	config.MarketData.FXRateTTL = getEnvDuration("MARKET_DATA_FX_RATE_TTL", time.Hour)
+
+	config.Sandbox.Drift = getEnvFloat("SANDBOX_DRIFT", 0.08)
+	config.Sandbox.Volatility = getEnvFloat("SANDBOX_VOLATILITY", 0.35)
+	config.Sandbox.JumpIntensity = getEnvFloat("SANDBOX_JUMP_INTENSITY", 3)
+	config.Sandbox.JumpSize = getEnvFloat("SANDBOX_JUMP_SIZE", 0.05)
+	config.Sandbox.BaseVolume = int64(getEnvInt("SANDBOX_BASE_VOLUME", 5000000))
+	config.Sandbox.Seed = int64(getEnvInt("SANDBOX_SEED", 42))
+
+	config.Stream.OpenInterval = getEnvDuration("STREAM_OPEN_INTERVAL", 15*time.Second)
+	config.Stream.ClosedInterval = getEnvDuration("STREAM_CLOSED_INTERVAL", 5*time.Minute)
+	config.Stream.Heartbeat = getEnvDuration("STREAM_HEARTBEAT", 15*time.Second)
+	config.Stream.BufferSize = getEnvInt("STREAM_BUFFER_SIZE", 32)
+	config.Stream.History = getEnvInt("STREAM_HISTORY", 50)
+
+	config.Events.PostEventDays = getEnvInt("EVENTS_POST_EVENT_DAYS", 1)
+	config.Events.DownWeight = getEnvBool("EVENTS_DOWN_WEIGHT", false)
+	config.Events.ConfidenceFactor = getEnvFloat("EVENTS_CONFIDENCE_FACTOR", 0.75)
+	config.Events.RefreshInterval = getEnvDuration("EVENTS_REFRESH_INTERVAL", 12*time.Hour)
+	config.Events.LookaheadDays = getEnvInt("EVENTS_LOOKAHEAD_DAYS", 90)
 
 	config.ML.PythonScript = getEnvString("ML_PYTHON_SCRIPT", "scripts/ml/predict.py")
 	config.ML.ModelPath = getEnvString("ML_MODEL_PATH", "persistent_data/ml_models/nvda_lstm_model")
 	config.ML.ScalerPath = getEnvString("ML_SCALER_PATH", "persistent_data/scalers/scaler.pkl")
+	config.ML.ModelRoot = getEnvString("ML_MODEL_ROOT", "persistent_data/ml_models")
 	config.ML.PredictionTTL = getEnvDuration("ML_PREDICTION_TTL", 5*time.Minute)
 	config.ML.Model = getEnvString("ML_MODEL", "simple")
-	config.ML.UseOHLCVData = getEnvBool("ML_USE_OHLCV_DATA", false)
+	config.ML.UseOHLCVData = getEnvBool("ML_USE_OHLCV_DATA", true)
 	config.ML.MaxDataPoints = getEnvInt("ML_MAX_DATA_POINTS", 30)
 	config.ML.MinDataPoints = getEnvInt("ML_MIN_DATA_POINTS", 5)
 	config.ML.EnableEnsemble = getEnvBool("ML_ENABLE_ENSEMBLE", false)
 	config.ML.DebugMode = getEnvBool("ML_DEBUG_MODE", false)
+	config.ML.WorkerScript = getEnvString("ML_WORKER_SCRIPT", "scripts/ml/worker.py")
+	config.ML.WorkerPoolSize = getEnvInt("ML_WORKER_POOL_SIZE", 2)
+	config.ML.WorkerTimeout = getEnvDuration("ML_WORKER_TIMEOUT", 30*time.Second)
+	config.ML.WorkerMaxRequests = getEnvInt("ML_WORKER_MAX_REQUESTS", 1000)
+	config.ML.Models = getEnvList("ML_MODELS", nil)
+	config.ML.HTTPTimeout = getEnvDuration("ML_HTTP_TIMEOUT", 10*time.Second)
+	config.ML.IntervalCoverages = getEnvFloatList("ML_INTERVAL_COVERAGES", []float64{0.8, 0.95})
+	config.ML.IntervalResidualWindow = getEnvInt("ML_INTERVAL_RESIDUAL_WINDOW", 120)
+	config.ML.IntervalMinResiduals = getEnvInt("ML_INTERVAL_MIN_RESIDUALS", 20)
+	config.ML.EnsembleModels = getEnvList("ML_ENSEMBLE_MODELS", []string{"simple", "enhanced", "advanced"})
+	config.ML.EnsembleWindow = getEnvInt("ML_ENSEMBLE_WINDOW", 60)
+	config.ML.EnsembleMinSamples = getEnvInt("ML_ENSEMBLE_MIN_SAMPLES", 10)
 
 	config.Logging.Level = getEnvString("LOG_LEVEL", "info")
 	config.Logging.Format = getEnvString("LOG_FORMAT", "json")
@@ -98,6 +230,11 @@ func Load() (*Config, error) {
 	return config, nil
 }
 
+// IsSandbox reports whether the service runs on synthetic market data
+func (c *Config) IsSandbox() bool {
+	return strings.EqualFold(c.Mode, ModeSandbox)
+}
+
 func loadFromFile(config *Config, filename string) error {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -116,6 +253,36 @@ func getEnvString(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvList(key string, defaultValue []string) []string {
+	if value := os.Getenv(key); value != "" {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		if len(items) > 0 {
+			return items
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloatList(key string, defaultValue []float64) []float64 {
+	var values []float64
+	for _, item := range getEnvList(key, nil) {
+		floatValue, err := strconv.ParseFloat(item, 64)
+		if err != nil {
+			return defaultValue
+		}
+		values = append(values, floatValue)
+	}
+	if len(values) > 0 {
+		return values
+	}
+	return defaultValue
+}
+
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
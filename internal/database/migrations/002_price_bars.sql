@@ -0,0 +1,22 @@
+-- Migration: 002_price_bars.sql
+-- Description: Persistent daily OHLCV bar store with incremental sync
+-- Version: v3.5.0
+-- Created: 2026-10-16
+
+-- Daily price bars fetched from market data providers
+CREATE TABLE IF NOT EXISTS price_bars (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    symbol VARCHAR(20) NOT NULL,
+    date DATE NOT NULL,
+    open REAL,
+    high REAL,
+    low REAL,
+    close REAL NOT NULL,
+    adj_close REAL,
+    volume INTEGER,
+    source VARCHAR(50) NOT NULL, -- provider that supplied the bar
+    fetched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(symbol, date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_bars_symbol_date ON price_bars(symbol, date);
@@ -0,0 +1,20 @@
+-- Migration: 003_corporate_actions.sql
+-- Description: Store splits and dividends used to adjust price series
+-- Version: v3.5.0
+-- Created: 2026-10-16
+
+-- Corporate actions keyed by ex-date
+CREATE TABLE IF NOT EXISTS corporate_actions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    symbol VARCHAR(20) NOT NULL,
+    ex_date DATE NOT NULL,
+    action_type VARCHAR(20) NOT NULL, -- 'split', 'dividend'
+    amount REAL,       -- dividend per share
+    numerator REAL,    -- split new shares
+    denominator REAL,  -- split old shares
+    source VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(symbol, ex_date, action_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_corporate_actions_symbol_date ON corporate_actions(symbol, ex_date);
@@ -0,0 +1,7 @@
+-- Migration: 004_price_bar_quality.sql
+-- Description: Flag stored bars synthesised by the market data gap policy
+-- Version: v3.5.0
+-- Created: 2026-10-16
+
+-- Filled bars stand in for vendor bars with a null close
+ALTER TABLE price_bars ADD COLUMN filled BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1,22 @@
+-- Migration: 005_symbol_metadata.sql
+-- Description: Instrument metadata captured from market data providers
+-- Version: v3.5.0
+-- Created: 2026-10-16
+
+-- One row per symbol, refreshed when bars are synced
+CREATE TABLE IF NOT EXISTS symbol_metadata (
+    symbol VARCHAR(20) PRIMARY KEY,
+    long_name VARCHAR(255),
+    short_name VARCHAR(255),
+    asset_class VARCHAR(20) NOT NULL, -- 'equity', 'index', 'crypto', 'currency', 'future'
+    exchange_code VARCHAR(20) NOT NULL, -- parsed from the ticker suffix
+    exchange VARCHAR(50), -- provider exchange name
+    currency VARCHAR(10),
+    timezone VARCHAR(50),
+    instrument_type VARCHAR(20),
+    first_trade_date DATE,
+    source VARCHAR(50) NOT NULL, -- provider that supplied the metadata
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_symbol_metadata_long_name ON symbol_metadata(long_name);
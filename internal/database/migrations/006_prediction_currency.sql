@@ -0,0 +1,19 @@
+-- Migration: 006_prediction_currency.sql
+-- Description: Record the quote currency of tracked predictions
+-- Version: v3.5.0
+-- Created: 2026-10-16
+
+-- Predicted and actual prices are in the listing's quote currency
+ALTER TABLE prediction_tracking ADD COLUMN currency VARCHAR(10) NOT NULL DEFAULT 'USD';
+
+-- Backfill non-US listings tracked before the column existed
+UPDATE prediction_tracking SET currency = 'TWD' WHERE symbol LIKE '%.TW' OR symbol LIKE '%.TWO' OR symbol IN ('^TWII', '^TWOII');
+UPDATE prediction_tracking SET currency = 'JPY' WHERE symbol LIKE '%.T' OR symbol = '^N225';
+UPDATE prediction_tracking SET currency = 'HKD' WHERE symbol LIKE '%.HK' OR symbol = '^HSI';
+UPDATE prediction_tracking SET currency = 'KRW' WHERE symbol LIKE '%.KS' OR symbol = '^KS11';
+UPDATE prediction_tracking SET currency = 'GBp' WHERE symbol LIKE '%.L' OR symbol = '^FTSE';
+UPDATE prediction_tracking SET currency = 'EUR' WHERE symbol LIKE '%.DE' OR symbol LIKE '%.PA' OR symbol LIKE '%.AS' OR symbol IN ('^GDAXI', '^FCHI', '^AEX');
+UPDATE prediction_tracking SET currency = 'CAD' WHERE symbol LIKE '%.TO' OR symbol = '^GSPTSE';
+UPDATE prediction_tracking SET currency = 'AUD' WHERE symbol LIKE '%.AX' OR symbol = '^AXJO';
+
+CREATE INDEX IF NOT EXISTS idx_prediction_tracking_currency ON prediction_tracking(currency);
@@ -0,0 +1,22 @@
+-- Migration: 007_corporate_events.sql
+-- Description: Earnings, ex-dividend and split calendar used to flag event-driven predictions
+-- Version: v3.5.0
+-- Created: 2026-10-17
+
+-- Events keyed by exchange-local date; imports and provider refreshes upsert
+CREATE TABLE IF NOT EXISTS corporate_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    symbol VARCHAR(20) NOT NULL,
+    event_date DATE NOT NULL,
+    event_type VARCHAR(20) NOT NULL, -- 'earnings', 'ex_dividend', 'split'
+    timing VARCHAR(10),              -- earnings: 'bmo', 'amc'
+    description VARCHAR(255),
+    source VARCHAR(50) NOT NULL,     -- provider name, 'csv' or 'ics'
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(symbol, event_date, event_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_corporate_events_symbol_date ON corporate_events(symbol, event_date);
+
+-- Comma-separated event types near the target date when the prediction was made
+ALTER TABLE prediction_tracking ADD COLUMN event_types VARCHAR(100);
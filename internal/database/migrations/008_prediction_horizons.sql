@@ -0,0 +1,56 @@
+-- Migration: 008_prediction_horizons.sql
+-- Description: Track 1, 5 and 20 trading day forecasts as separate predictions
+-- Version: v3.5.0
+-- Created: 2026-10-17
+
+-- prediction_date stays the session whose close is forecast; horizon_days is
+-- how many trading days ahead it was when the prediction was made. SQLite
+-- cannot alter a UNIQUE constraint, so the table is rebuilt, in a transaction
+-- so a failure part way leaves the old table in place.
+BEGIN;
+
+CREATE TABLE prediction_tracking_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    symbol VARCHAR(10) NOT NULL,
+    prediction_date DATE NOT NULL,
+    horizon_days INTEGER NOT NULL DEFAULT 1,
+    predicted_price DECIMAL(10,2),
+    predicted_direction VARCHAR(10), -- 'up', 'down', 'hold'
+    confidence DECIMAL(5,4),
+    actual_close DECIMAL(10,2),
+    accuracy_mape DECIMAL(5,4), -- Mean Absolute Percentage Error
+    direction_correct BOOLEAN,
+    market_was_open BOOLEAN DEFAULT TRUE,
+    prediction_timestamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    actual_price_timestamp TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    currency VARCHAR(10) NOT NULL DEFAULT 'USD',
+    event_types VARCHAR(100),
+    UNIQUE(symbol, prediction_date, horizon_days)
+);
+
+-- Existing predictions were all for the next session
+INSERT INTO prediction_tracking_new (
+    id, symbol, prediction_date, horizon_days, predicted_price, predicted_direction,
+    confidence, actual_close, accuracy_mape, direction_correct, market_was_open,
+    prediction_timestamp, actual_price_timestamp, created_at, updated_at,
+    currency, event_types
+)
+SELECT
+    id, symbol, prediction_date, 1, predicted_price, predicted_direction,
+    confidence, actual_close, accuracy_mape, direction_correct, market_was_open,
+    prediction_timestamp, actual_price_timestamp, created_at, updated_at,
+    currency, event_types
+FROM prediction_tracking;
+
+DROP TABLE prediction_tracking;
+ALTER TABLE prediction_tracking_new RENAME TO prediction_tracking;
+
+CREATE INDEX IF NOT EXISTS idx_prediction_tracking_symbol_date ON prediction_tracking(symbol, prediction_date);
+CREATE INDEX IF NOT EXISTS idx_prediction_tracking_date ON prediction_tracking(prediction_date);
+CREATE INDEX IF NOT EXISTS idx_prediction_tracking_symbol ON prediction_tracking(symbol);
+CREATE INDEX IF NOT EXISTS idx_prediction_tracking_currency ON prediction_tracking(currency);
+CREATE INDEX IF NOT EXISTS idx_prediction_tracking_horizon ON prediction_tracking(horizon_days);
+
+COMMIT;
@@ -0,0 +1,22 @@
+-- Migration: 009_prediction_intervals.sql
+-- Description: Store prediction intervals and whether the actual close fell inside them
+-- Version: v3.5.0
+-- Created: 2026-10-17
+
+-- Signed error of the split-adjusted prediction, (actual - predicted) / predicted * 100;
+-- empirical intervals are built from recent values
+ALTER TABLE prediction_tracking ADD COLUMN residual_pct DECIMAL(8,4);
+
+-- One band per configured coverage, replaced whenever the prediction is re-run
+CREATE TABLE IF NOT EXISTS prediction_intervals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    prediction_id INTEGER NOT NULL REFERENCES prediction_tracking(id) ON DELETE CASCADE,
+    coverage DECIMAL(4,3) NOT NULL, -- nominal, e.g. 0.8
+    lower_price DECIMAL(10,2) NOT NULL,
+    upper_price DECIMAL(10,2) NOT NULL,
+    source VARCHAR(20) NOT NULL,    -- 'model' or 'residuals'
+    covered BOOLEAN,                -- set when the actual close is scored
+    UNIQUE(prediction_id, coverage)
+);
+
+CREATE INDEX IF NOT EXISTS idx_prediction_intervals_prediction ON prediction_intervals(prediction_id);
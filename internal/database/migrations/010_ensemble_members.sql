@@ -0,0 +1,18 @@
+-- Migration: 010_ensemble_members.sql
+-- Description: Track each ensemble member's prediction so members can be weighted by their error
+-- Version: v3.5.0
+-- Created: 2026-10-17
+
+-- One row per member of an ensemble prediction, replaced whenever the prediction is re-run
+CREATE TABLE IF NOT EXISTS prediction_members (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    prediction_id INTEGER NOT NULL REFERENCES prediction_tracking(id) ON DELETE CASCADE,
+    model VARCHAR(50) NOT NULL,
+    predicted_price DECIMAL(10,2) NOT NULL,
+    weight DECIMAL(5,4) NOT NULL,   -- share of the blend when predicted
+    accuracy_mape DECIMAL(5,4),     -- set when the actual close is scored
+    UNIQUE(prediction_id, model)
+);
+
+CREATE INDEX IF NOT EXISTS idx_prediction_members_prediction ON prediction_members(prediction_id);
+CREATE INDEX IF NOT EXISTS idx_prediction_members_model ON prediction_members(model);
@@ -0,0 +1,34 @@
+-- Migration: 011_model_registry.sql
+-- Description: Register versioned model artifacts and record the version behind each prediction
+-- Version: v3.5.0
+-- Created: 2026-10-17
+
+CREATE TABLE IF NOT EXISTS model_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(50) NOT NULL,      -- prediction model name, e.g. 'enhanced'
+    version VARCHAR(50) NOT NULL,   -- semantic version, e.g. '1.4.0'
+    artifact_path TEXT,
+    sha256 CHAR(64),                -- of the artifact when registered
+    training_start DATE,
+    training_end DATE,
+    metrics TEXT,                   -- JSON object of offline evaluation metrics
+    status VARCHAR(20) NOT NULL DEFAULT 'staging', -- 'staging', 'production', 'retired'
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    promoted_at TIMESTAMP,          -- last promotion to production
+    rolled_back_at TIMESTAMP,       -- last rollback out of production
+    UNIQUE(name, version)
+);
+
+-- At most one production version per model
+CREATE UNIQUE INDEX IF NOT EXISTS idx_model_versions_production ON model_versions(name) WHERE status = 'production';
+
+-- The model and version that produced each prediction; model_version_id is
+-- NULL for models with no production version registered
+ALTER TABLE prediction_tracking ADD COLUMN model VARCHAR(50);
+ALTER TABLE prediction_tracking ADD COLUMN model_version VARCHAR(50);
+ALTER TABLE prediction_tracking ADD COLUMN model_version_id INTEGER REFERENCES model_versions(id);
+
+ALTER TABLE prediction_members ADD COLUMN model_version VARCHAR(50);
+ALTER TABLE prediction_members ADD COLUMN model_version_id INTEGER REFERENCES model_versions(id);
+
+CREATE INDEX IF NOT EXISTS idx_prediction_tracking_model_version ON prediction_tracking(model_version_id);
@@ -151,7 +151,7 @@ func (s *SQLiteDB) GetStats() (map[string]interface{}, error) {
 	}
 
 	// Get table counts
-	tables := []string{"prediction_tracking", "market_calendar", "daily_execution_log"}
+	tables := []string{"prediction_tracking", "market_calendar", "daily_execution_log", "price_bars", "corporate_actions", "symbol_metadata", "corporate_events"}
 	for _, table := range tables {
 		var count int
 		query := fmt.Sprintf("SELECT COUNT(*) FROM %s", table)
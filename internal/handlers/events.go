@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"stock-prediction-us/internal/config"
+	"stock-prediction-us/internal/models"
+	"stock-prediction-us/internal/services/events"
+)
+
+// maxEventImportBytes bounds the size of an uploaded event file
+const maxEventImportBytes = 4 << 20
+
+// maxEventLookaheadDays bounds the upcoming events window
+const maxEventLookaheadDays = 730
+
+// EventsHandler serves the corporate event calendar
+type EventsHandler struct {
+	config   *config.Config
+	logger   *logrus.Logger
+	calendar *events.Calendar
+	admin    mux.MiddlewareFunc // Guards the import, which replaces stored events
+}
+
+// NewEventsHandler creates a new events handler
+func NewEventsHandler(cfg *config.Config, logger *logrus.Logger, calendar *events.Calendar, admin mux.MiddlewareFunc) *EventsHandler {
+	return &EventsHandler{
+		config:   cfg,
+		logger:   logger,
+		calendar: calendar,
+		admin:    admin,
+	}
+}
+
+// RegisterRoutes registers the event calendar routes. Importing events is
+// an admin operation.
+func (h *EventsHandler) RegisterRoutes(router *mux.Router) {
+	router.Handle("/api/v1/events/import", h.admin(http.HandlerFunc(h.ImportEventsHandler))).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/v1/events/{symbol}", h.UpcomingEventsHandler).Methods("GET", "OPTIONS")
+}
+
+// UpcomingEventsHandler lists a symbol's earnings, ex-dividend and split
+// dates from today through ?days= (default EVENTS_LOOKAHEAD_DAYS)
+func (h *EventsHandler) UpcomingEventsHandler(w http.ResponseWriter, r *http.Request) {
+	symbol, err := models.NormalizeSymbol(mux.Vars(r)["symbol"])
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	days := h.config.Events.LookaheadDays
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed < 1 || parsed > maxEventLookaheadDays {
+			h.writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid days: %s (1-%d)", daysStr, maxEventLookaheadDays))
+			return
+		}
+		days = parsed
+	}
+
+	upcoming, err := h.calendar.Upcoming(r.Context(), symbol, days)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to load corporate events")
+		h.writeError(w, http.StatusInternalServerError, "Failed to load corporate events")
+		return
+	}
+
+	response := map[string]interface{}{
+		"symbol": symbol,
+		"days":   days,
+		"events": upcoming,
+		"count":  len(upcoming),
+	}
+	if h.config.IsSandbox() {
+		response["synthetic"] = true
+	}
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+// ImportEventsHandler stores events from a CSV (symbol,date,type[,timing,
+// description]) or iCalendar request body. The format comes from ?format=
+// or the Content-Type; ?symbol= names the symbol for calendar entries that
+// do not carry one.
+func (h *EventsHandler) ImportEventsHandler(w http.ResponseWriter, r *http.Request) {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = events.FormatCSV
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "text/calendar") {
+			format = events.FormatICS
+		}
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxEventImportBytes)
+	var parsed []models.CorporateEvent
+	skipped := 0
+	var err error
+	switch format {
+	case events.FormatCSV:
+		parsed, err = events.ParseCSV(body)
+	case events.FormatICS:
+		parsed, skipped, err = events.ParseICS(body, r.URL.Query().Get("symbol"))
+	default:
+		h.writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid format: %s (valid options: csv, ics)", format))
+		return
+	}
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s import: %v", format, err))
+		return
+	}
+
+	imported, err := h.calendar.Import(parsed)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to import corporate events")
+		h.writeError(w, http.StatusInternalServerError, "Failed to import corporate events")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"format":   format,
+		"imported": imported,
+		"skipped":  skipped,
+	}).Info("Imported corporate events")
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"format":   format,
+		"imported": imported,
+		"skipped":  skipped,
+	})
+}
+
+func (h *EventsHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode JSON response")
+	}
+}
+
+func (h *EventsHandler) writeError(w http.ResponseWriter, status int, message string) {
+	h.writeJSON(w, status, map[string]interface{}{
+		"error":     message,
+		"status":    status,
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
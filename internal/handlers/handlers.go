@@ -2,11 +2,16 @@ package handlers
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -15,8 +20,9 @@ import (
 	"stock-prediction-us/internal/config"
 	"stock-prediction-us/internal/metrics"
 	"stock-prediction-us/internal/models"
+	"stock-prediction-us/internal/services/events"
+	"stock-prediction-us/internal/services/marketdata"
 	"stock-prediction-us/internal/services/prediction"
-	"stock-prediction-us/internal/services/yahoo"
 )
 
 // Handler contains all HTTP handlers
@@ -24,23 +30,48 @@ type Handler struct {
 	config           *config.Config
 	logger           *logrus.Logger
 	metrics          *metrics.Metrics
-	yahooClient      *yahoo.Client
-	predictionService *prediction.Service
+	marketData       marketdata.MarketDataProvider
+	batchFetcher     *marketdata.BatchFetcher
+	fxRates          *marketdata.FXRates
+	eventCalendar    *events.Calendar
+	predictionService *prediction.EnhancedPredictionService
 }
 
+// maxQuoteSymbols limits the number of symbols in one quotes request
+const maxQuoteSymbols = 50
+
+// defaultSearchLimit and maxSearchLimit bound symbol search results
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+// ErrorCodeCircuitOpen marks 503 responses returned while the market data
+// circuit breaker is open
+const ErrorCodeCircuitOpen = "MARKET_DATA_CIRCUIT_OPEN"
+
+// SyntheticDataHeader is set on every response in sandbox mode
+const SyntheticDataHeader = "X-Synthetic-Data"
+
 // NewHandler creates a new handler instance
 func NewHandler(
 	cfg *config.Config,
 	logger *logrus.Logger,
 	metrics *metrics.Metrics,
-	yahooClient *yahoo.Client,
-	predictionService *prediction.Service,
+	marketData marketdata.MarketDataProvider,
+	batchFetcher *marketdata.BatchFetcher,
+	fxRates *marketdata.FXRates,
+	eventCalendar *events.Calendar,
+	predictionService *prediction.EnhancedPredictionService,
 ) *Handler {
 	return &Handler{
 		config:           cfg,
 		logger:           logger,
 		metrics:          metrics,
-		yahooClient:      yahooClient,
+		marketData:       marketData,
+		batchFetcher:     batchFetcher,
+		fxRates:          fxRates,
+		eventCalendar:    eventCalendar,
 		predictionService: predictionService,
 	}
 }
@@ -49,17 +80,25 @@ func NewHandler(
 func (h *Handler) PredictHandler(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	
-	// Extract symbol from URL
+	// Extract and normalise symbol from URL (e.g. brk.b -> BRK-B)
 	vars := mux.Vars(r)
-	symbol := vars["symbol"]
+	parsed, err := models.ParseSymbol(vars["symbol"])
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	symbol := parsed.Ticker
 	
-	if symbol == "" {
-		h.writeErrorResponse(w, http.StatusBadRequest, "symbol is required")
+	// Get bar interval from query parameter (default to daily bars)
+	interval, err := models.ParseInterval(r.URL.Query().Get("interval"))
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
 		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
 		return
 	}
 	
-	// Get lookback days from query parameter (default to config value)
+	// Get lookback bars from query parameter (default to config value)
 	lookbackDays := h.config.Stock.LookbackDays
 	if days := r.URL.Query().Get("days"); days != "" {
 		if parsed, err := strconv.Atoi(days); err == nil && parsed > 0 && parsed <= 365 {
@@ -67,16 +106,70 @@ func (h *Handler) PredictHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	
+	// Get raw vs split/dividend adjusted series (default to config value)
+	adjusted, err := h.parseAdjusted(r)
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	
+	// Get optional reporting currency (default to the quote currency)
+	currency, err := parseCurrency(r)
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	
+	// Get optional model override (default to the service's default model)
+	model, err := h.parseModel(r)
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	
+	// Model diagnostics are only returned on request
+	debug, err := parseDebug(r)
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	
+	// Get optional forecast horizons in trading days (daily bars only)
+	horizons, err := parseHorizons(r, interval)
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	
 	h.logger.WithFields(logrus.Fields{
 		"symbol":        symbol,
 		"lookback_days": lookbackDays,
+		"interval":      interval,
+		"adjusted":      adjusted,
+		"currency":      currency,
+		"model":         model,
+		"horizons":      horizons,
 		"client_ip":     r.RemoteAddr,
 	}).Info("Processing prediction request")
 	
-	// Fetch stock data
-	stockData, err := h.yahooClient.FetchStockData(symbol, h.getPeriodFromDays(lookbackDays))
+	// Fetch bars covering the lookback window
+	sessions := (lookbackDays + interval.BarsPerSession() - 1) / interval.BarsPerSession()
+	bars, err := h.marketData.FetchHistoricalData(r.Context(), symbol, sessions, interval)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to fetch stock data")
+		h.writeMarketDataError(w, err, "Failed to fetch stock data")
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	
+	stockData := models.ClosePrices(bars, adjusted)
+	if err := models.ValidateStockData(stockData, 1); err != nil {
+		h.logger.WithError(err).Error("Invalid stock data")
 		h.writeErrorResponse(w, http.StatusServiceUnavailable, "Failed to fetch stock data")
 		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
 		return
@@ -87,19 +180,43 @@ func (h *Handler) PredictHandler(w http.ResponseWriter, r *http.Request) {
 		lookbackDays = len(stockData)
 	}
 	lastData := stockData[len(stockData)-lookbackDays:]
+	lastBars := bars[len(bars)-lookbackDays:]
+	if adjusted {
+		lastBars = models.AdjustedBars(lastBars)
+	}
 	
 	// Create prediction request
 	predReq := &models.PredictionRequest{
 		Symbol:         symbol,
 		HistoricalData: lastData,
+		Interval:       interval,
+		Adjusted:       adjusted,
 		RequestTime:    time.Now(),
+		Model:          model,
+		Input:          models.NewModelInput(parsed, interval, adjusted, lastBars),
+	}
+	if len(horizons) > 0 {
+		predReq.Horizons = horizons
+		predReq.ForecastDates = h.forecastDates(parsed, lastBars[len(lastBars)-1], models.MaxHorizon(horizons))
+	}
+	
+	// Flag forecasts whose target session falls on or just after a corporate
+	// event; the calendar is advisory and never fails the prediction
+	if h.eventCalendar != nil {
+		target := h.predictionTargetDate(parsed, lastBars[len(lastBars)-1], interval)
+		risk, err := h.eventCalendar.Risk(r.Context(), symbol, target)
+		if err != nil {
+			h.logger.WithError(err).Warn("Failed to check corporate events")
+		} else {
+			predReq.EventRisk = risk
+		}
 	}
 	
 	// Make prediction with timeout
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 	
-	prediction, err := h.predictionService.PredictStock(ctx, predReq)
+	prediction, err := h.predictionService.Predict(ctx, predReq)
 	if err != nil {
 		h.logger.WithError(err).Error("Prediction failed")
 		h.writeErrorResponse(w, http.StatusInternalServerError, "Prediction failed")
@@ -107,8 +224,45 @@ func (h *Handler) PredictHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	// Attach the quality report to a copy so cached responses stay untouched
+	response := *prediction
+	response.DataQuality = h.assessDataQuality(symbol, lastBars)
+	response.AssetClass = parsed.AssetClass
+	response.Exchange = parsed.Exchange.Code
+	response.Synthetic = h.config.IsSandbox()
+	if !debug {
+		response.Diagnostics = nil
+	}
+	response.Currency = parsed.Currency()
+	if last := lastBars[len(lastBars)-1]; last.Currency != "" {
+		response.Currency = last.Currency
+	}
+	
+	// Prices are converted at the latest rate; the model always sees native prices
+	if currency != "" && currency != response.Currency {
+		rate, err := h.fxRates.Rate(r.Context(), response.Currency, currency, time.Now())
+		if err != nil {
+			h.logger.WithError(err).Error("Currency conversion failed")
+			h.writeMarketDataError(w, err, "Failed to convert currency")
+			h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+			return
+		}
+		response.NativeCurrency = response.Currency
+		response.Currency = currency
+		response.FXRate = rate
+		response.CurrentPrice *= rate
+		response.PredictedPrice *= rate
+		response.Intervals = convertIntervals(response.Intervals, rate)
+		response.Forecasts = append([]models.ForecastPoint(nil), response.Forecasts...)
+		for i := range response.Forecasts {
+			response.Forecasts[i].PredictedPrice *= rate
+			response.Forecasts[i].Intervals = convertIntervals(response.Forecasts[i].Intervals, rate)
+		}
+		response.Ensemble = convertEnsemble(response.Ensemble, rate)
+	}
+	
 	// Write response
-	h.writeJSONResponse(w, http.StatusOK, prediction)
+	h.writeJSONResponse(w, http.StatusOK, &response)
 	h.metrics.RecordAPIRequest(time.Since(start).Seconds(), true)
 	
 	h.logger.WithFields(logrus.Fields{
@@ -130,12 +284,24 @@ func (h *Handler) HealthHandler(w http.ResponseWriter, r *http.Request) {
 		Services:  make(map[string]string),
 	}
 	
-	// Check Yahoo Finance API
-	if err := h.yahooClient.HealthCheck(); err != nil {
-		status.Services["yahoo_api"] = fmt.Sprintf("unhealthy: %v", err)
+	// Check market data provider
+	if err := h.marketData.HealthCheck(r.Context()); err != nil {
+		status.Services["market_data"] = fmt.Sprintf("unhealthy: %v", err)
 		status.Status = "degraded"
 	} else {
-		status.Services["yahoo_api"] = "healthy"
+		status.Services["market_data"] = "healthy"
+	}
+	status.Services["market_data_provider"] = h.marketData.Name()
+	// yahoo_api is the documented key; keep it for existing health checks
+	status.Services["yahoo_api"] = status.Services["market_data"]
+	if h.config.IsSandbox() {
+		status.Services["mode"] = config.ModeSandbox
+	}
+	for _, breaker := range h.breakerSnapshots() {
+		status.Services["circuit_breaker_"+breaker.Provider] = string(breaker.State)
+		if breaker.State == marketdata.BreakerOpen {
+			status.Status = "degraded"
+		}
 	}
 	
 	// Check prediction service
@@ -165,8 +331,14 @@ func (h *Handler) StatsHandler(w http.ResponseWriter, r *http.Request) {
 	stats := map[string]interface{}{
 		"cache":  h.predictionService.GetCacheStats(),
 		"model":  h.predictionService.GetModelInfo(),
+		"python_workers": h.predictionService.GetWorkerPoolStats(),
 		"system": h.getSystemStats(),
+		"market_data": map[string]interface{}{
+			"provider":         h.marketData.Name(),
+			"circuit_breakers": h.breakerSnapshots(),
+		},
 	}
+	h.markSynthetic(stats)
 	
 	h.writeJSONResponse(w, http.StatusOK, stats)
 	h.metrics.RecordAPIRequest(time.Since(start).Seconds(), true)
@@ -195,18 +367,72 @@ func (h *Handler) ClearCacheHandler(w http.ResponseWriter, r *http.Request) {
 	h.logger.Info("Cache cleared via API request")
 }
 
+// ModelsHandler lists the prediction models that can be requested
+func (h *Handler) ModelsHandler(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	
+	response := map[string]interface{}{
+		"default": h.predictionService.DefaultModel(),
+		"models":  h.predictionService.ListModels(),
+	}
+	
+	h.writeJSONResponse(w, http.StatusOK, response)
+	h.metrics.RecordAPIRequest(time.Since(start).Seconds(), true)
+}
+
+// SwitchModelRequest is the body of the default model switch
+type SwitchModelRequest struct {
+	Model string `json:"model"`
+}
+
+// SwitchModelHandler changes the default prediction model. Predictions in
+// flight finish on the previous model.
+func (h *Handler) SwitchModelHandler(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	
+	var req SwitchModelRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	
+	previous := h.predictionService.DefaultModel()
+	model := models.PredictionModel(strings.ToLower(strings.TrimSpace(req.Model)))
+	if err := h.predictionService.SwitchModel(model); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	
+	h.logger.WithFields(logrus.Fields{
+		"model":     model,
+		"previous":  previous,
+		"client_ip": r.RemoteAddr,
+	}).Warn("Default prediction model switched")
+	
+	response := map[string]interface{}{
+		"previous": previous,
+		"model":    h.predictionService.GetModelInfo(),
+		"time":     time.Now().Format(time.RFC3339),
+	}
+	
+	h.writeJSONResponse(w, http.StatusOK, response)
+	h.metrics.RecordAPIRequest(time.Since(start).Seconds(), true)
+}
+
 // HistoricalDataHandler handles historical data requests
 func (h *Handler) HistoricalDataHandler(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	
 	vars := mux.Vars(r)
-	symbol := vars["symbol"]
-	
-	if symbol == "" {
-		h.writeErrorResponse(w, http.StatusBadRequest, "symbol is required")
+	parsed, err := models.ParseSymbol(vars["symbol"])
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
 		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
 		return
 	}
+	symbol := parsed.Ticker
 	
 	// Get days from query parameter
 	days := 30 // default
@@ -216,21 +442,380 @@ func (h *Handler) HistoricalDataHandler(w http.ResponseWriter, r *http.Request)
 		}
 	}
 	
+	// Get bar interval from query parameter (default to daily bars)
+	interval, err := models.ParseInterval(r.URL.Query().Get("interval"))
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	
+	// Get raw vs split/dividend adjusted series (default to config value)
+	adjusted, err := h.parseAdjusted(r)
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	
+	// Get optional reporting currency (default to the quote currency)
+	currency, err := parseCurrency(r)
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	
 	// Fetch historical data
-	data, err := h.yahooClient.FetchHistoricalData(symbol, days)
+	data, err := h.marketData.FetchHistoricalData(r.Context(), symbol, days, interval)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to fetch historical data")
-		h.writeErrorResponse(w, http.StatusServiceUnavailable, "Failed to fetch historical data")
+		h.writeMarketDataError(w, err, "Failed to fetch historical data")
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	if adjusted {
+		data = models.AdjustedBars(data)
+	}
+	dataQuality := h.assessDataQuality(symbol, data)
+	
+	nativeCurrency := parsed.Currency()
+	if len(data) > 0 && data[len(data)-1].Currency != "" {
+		nativeCurrency = data[len(data)-1].Currency
+	}
+	if currency != "" && currency != nativeCurrency {
+		data, err = h.convertBars(r.Context(), data, currency)
+		if err != nil {
+			h.logger.WithError(err).Error("Currency conversion failed")
+			h.writeMarketDataError(w, err, "Failed to convert currency")
+			h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+			return
+		}
+	} else {
+		currency = nativeCurrency
+	}
+	
+	// Corporate actions are informational; a failure does not fail the request
+	actions, err := h.marketData.FetchCorporateActions(r.Context(), symbol, days*7/5+7)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to fetch corporate actions")
+	}
+	
+	response := map[string]interface{}{
+		"symbol":            symbol,
+		"asset_class":       parsed.AssetClass,
+		"exchange":          parsed.Exchange,
+		"days":              days,
+		"interval":          interval,
+		"adjusted":          adjusted,
+		"data":              data,
+		"count":             len(data),
+		"corporate_actions": actions,
+		"data_quality":      dataQuality,
+		"currency":          currency,
+		"native_currency":   nativeCurrency,
+	}
+	h.markSynthetic(response)
+	
+	h.writeJSONResponse(w, http.StatusOK, response)
+	h.metrics.RecordAPIRequest(time.Since(start).Seconds(), true)
+}
+
+// QuotesHandler handles multi-symbol quote requests
+func (h *Handler) QuotesHandler(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	
+	currency, err := parseCurrency(r)
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	
+	parsed, invalid := parseSymbolList(r.URL.Query().Get("symbols"))
+	symbols := make([]string, 0, len(parsed))
+	parsedSymbols := make(map[string]models.Symbol, len(parsed))
+	for _, symbol := range parsed {
+		symbols = append(symbols, symbol.Ticker)
+		parsedSymbols[symbol.Ticker] = symbol
+	}
+	
+	if len(symbols)+len(invalid) == 0 {
+		h.writeErrorResponse(w, http.StatusBadRequest, "symbols is required")
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	if len(symbols)+len(invalid) > maxQuoteSymbols {
+		h.writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("too many symbols: %d (maximum %d)", len(symbols)+len(invalid), maxQuoteSymbols))
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	
+	results := h.batchFetcher.FetchStockData(r.Context(), symbols, "7d", models.Interval1d)
+	
+	quotes := make([]map[string]interface{}, 0, len(results)+len(invalid))
+	failed := 0
+	for _, result := range results {
+		parsed := parsedSymbols[result.Symbol]
+		quote := map[string]interface{}{
+			"symbol":      result.Symbol,
+			"asset_class": parsed.AssetClass,
+			"exchange":    parsed.Exchange.Code,
+			"currency":    parsed.Currency(),
+		}
+		
+		// Quotes are converted at the latest rate
+		rate := 1.0
+		if result.Err == nil && currency != "" && currency != parsed.Currency() {
+			rate, result.Err = h.fxRates.Rate(r.Context(), parsed.Currency(), currency, time.Now())
+			if result.Err == nil {
+				quote["currency"] = currency
+				quote["native_currency"] = parsed.Currency()
+				quote["fx_rate"] = rate
+			}
+		}
+		
+		switch {
+		case result.Err != nil:
+			quote["error"] = result.Err.Error()
+			if errors.Is(result.Err, marketdata.ErrCircuitOpen) {
+				quote["code"] = ErrorCodeCircuitOpen
+			}
+			failed++
+		case len(result.Prices) == 0:
+			quote["error"] = "no price data available"
+			failed++
+		default:
+			price := result.Prices[len(result.Prices)-1] * rate
+			quote["price"] = price
+			if len(result.Prices) > 1 {
+				previousClose := result.Prices[len(result.Prices)-2] * rate
+				quote["previous_close"] = previousClose
+				quote["change"] = price - previousClose
+				quote["change_percent"] = (price - previousClose) / previousClose * 100
+			}
+		}
+		
+		quotes = append(quotes, quote)
+	}
+	
+	// Unparsable symbols are reported alongside fetch failures
+	quotes = append(quotes, invalid...)
+	failed += len(invalid)
+	
+	response := map[string]interface{}{
+		"quotes":    quotes,
+		"count":     len(quotes),
+		"failed":    failed,
+		"timestamp": time.Now().Format(time.RFC3339),
+	}
+	h.markSynthetic(response)
+	
+	// Partial failures still return 200 with per-symbol errors
+	status := http.StatusOK
+	if failed == len(quotes) {
+		status = http.StatusServiceUnavailable
+	}
+	
+	h.writeJSONResponse(w, status, response)
+	h.metrics.RecordAPIRequest(time.Since(start).Seconds(), status == http.StatusOK)
+}
+
+// predictionTargetDate returns the session a prediction is for: the next
+// trading day after the last daily bar, or the last bar's own session for
+// intraday bars
+func (h *Handler) predictionTargetDate(symbol models.Symbol, last models.StockData, interval models.Interval) time.Time {
+	if interval.IsIntraday() {
+		return symbol.SessionDate(last.Timestamp)
+	}
+	return h.forecastDates(symbol, last, 1)[0]
+}
+
+// forecastDates returns the n trading sessions of the symbol's market after
+// the last daily bar
+func (h *Handler) forecastDates(symbol models.Symbol, last models.StockData, n int) []time.Time {
+	var isUSTradingDay func(time.Time) bool
+	if h.eventCalendar != nil {
+		isUSTradingDay = h.eventCalendar.IsTradingDay
+	}
+	return symbol.TradingDaysAfter(symbol.SessionDate(last.Timestamp), n, isUSTradingDay)
+}
+
+// parseHorizons reads the optional ?horizons= list of forecast horizons
+func parseHorizons(r *http.Request, interval models.Interval) ([]int, error) {
+	raw := r.URL.Query().Get("horizons")
+	if raw == "" {
+		return nil, nil
+	}
+	if interval.IsIntraday() {
+		return nil, fmt.Errorf("horizons are in trading days and need daily bars (interval=1d)")
+	}
+	return models.ParseHorizons(raw)
+}
+
+// parseCurrency reads the optional ?currency= reporting currency
+func parseCurrency(r *http.Request) (string, error) {
+	raw := r.URL.Query().Get("currency")
+	if raw == "" {
+		return "", nil
+	}
+	return models.NormalizeCurrency(raw)
+}
+
+// convertIntervals returns a copy of intervals with bounds scaled by rate,
+// leaving the cached response untouched
+func convertIntervals(intervals []models.PredictionInterval, rate float64) []models.PredictionInterval {
+	if intervals == nil {
+		return nil
+	}
+	converted := make([]models.PredictionInterval, len(intervals))
+	for i, interval := range intervals {
+		interval.Lower *= rate
+		interval.Upper *= rate
+		converted[i] = interval
+	}
+	return converted
+}
+
+// convertEnsemble returns a copy of an ensemble result with member
+// predictions scaled by rate
+func convertEnsemble(ensemble *models.EnsembleResult, rate float64) *models.EnsembleResult {
+	if ensemble == nil {
+		return nil
+	}
+	converted := *ensemble
+	converted.Members = append([]models.EnsembleMember(nil), ensemble.Members...)
+	for i := range converted.Members {
+		converted.Members[i].PredictedPrice *= rate
+	}
+	return &converted
+}
+
+// convertBars restates bar prices in currency at each bar's daily FX rate.
+// Copies are returned so cached and stored bars stay in their quote currency.
+func (h *Handler) convertBars(ctx context.Context, bars []models.StockData, currency string) ([]models.StockData, error) {
+	converted := make([]models.StockData, len(bars))
+	for i, bar := range bars {
+		from := bar.Currency
+		if from == "" {
+			from = models.CurrencyOf(bar.Symbol)
+		}
+		rate, err := h.fxRates.Rate(ctx, from, currency, bar.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		bar.Open *= rate
+		bar.High *= rate
+		bar.Low *= rate
+		bar.Close *= rate
+		bar.AdjClose *= rate
+		bar.Currency = currency
+		converted[i] = bar
+	}
+	return converted, nil
+}
+
+// parseSymbolList parses a comma-separated symbol list, ignoring blanks and
+// duplicates. Unparsable entries are returned with their error.
+func parseSymbolList(raw string) ([]models.Symbol, []map[string]interface{}) {
+	var symbols []models.Symbol
+	var invalid []map[string]interface{}
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(raw, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		parsed, err := models.ParseSymbol(entry)
+		if err != nil {
+			invalid = append(invalid, map[string]interface{}{
+				"symbol": strings.TrimSpace(entry),
+				"error":  err.Error(),
+			})
+			continue
+		}
+		if seen[parsed.Ticker] {
+			continue
+		}
+		seen[parsed.Ticker] = true
+		symbols = append(symbols, parsed)
+	}
+	return symbols, invalid
+}
+
+// SymbolHandler returns instrument metadata for a symbol
+func (h *Handler) SymbolHandler(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	
+	vars := mux.Vars(r)
+	parsed, err := models.ParseSymbol(vars["symbol"])
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	
+	directory, ok := h.marketData.(marketdata.SymbolDirectory)
+	if !ok {
+		h.writeErrorResponse(w, http.StatusNotImplemented, "symbol lookup is not supported by the configured market data provider")
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	
+	metadata, err := directory.FetchSymbolMetadata(r.Context(), parsed.Ticker)
+	if err != nil {
+		if errors.Is(err, marketdata.ErrSymbolNotFound) {
+			h.writeErrorResponse(w, http.StatusNotFound, fmt.Sprintf("symbol not found: %s", parsed.Ticker))
+		} else {
+			h.logger.WithError(err).Error("Failed to fetch symbol metadata")
+			h.writeMarketDataError(w, err, "Failed to fetch symbol metadata")
+		}
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	
+	h.writeJSONResponse(w, http.StatusOK, metadata)
+	h.metrics.RecordAPIRequest(time.Since(start).Seconds(), true)
+}
+
+// SymbolSearchHandler searches symbols by ticker prefix or name
+func (h *Handler) SymbolSearchHandler(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "q is required")
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	
+	limit := defaultSearchLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 && parsed <= maxSearchLimit {
+			limit = parsed
+		}
+	}
+	
+	directory, ok := h.marketData.(marketdata.SymbolDirectory)
+	if !ok {
+		h.writeErrorResponse(w, http.StatusNotImplemented, "symbol search is not supported by the configured market data provider")
+		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
+		return
+	}
+	
+	results, err := directory.SearchSymbols(r.Context(), query, limit)
+	if err != nil {
+		h.logger.WithError(err).Error("Symbol search failed")
+		h.writeMarketDataError(w, err, "Symbol search failed")
 		h.metrics.RecordAPIRequest(time.Since(start).Seconds(), false)
 		return
 	}
 	
 	response := map[string]interface{}{
-		"symbol": symbol,
-		"days":   days,
-		"data":   data,
-		"count":  len(data),
+		"query":   query,
+		"results": results,
+		"count":   len(results),
 	}
+	h.markSynthetic(response)
 	
 	h.writeJSONResponse(w, http.StatusOK, response)
 	h.metrics.RecordAPIRequest(time.Since(start).Seconds(), true)
@@ -238,6 +823,106 @@ func (h *Handler) HistoricalDataHandler(w http.ResponseWriter, r *http.Request)
 
 // Helper methods
 
+// markSynthetic flags a map response as synthetic in sandbox mode
+func (h *Handler) markSynthetic(response map[string]interface{}) {
+	if h.config.IsSandbox() {
+		response["synthetic"] = true
+	}
+}
+
+// breakerSnapshots returns circuit breaker state from the market data provider
+func (h *Handler) breakerSnapshots() []marketdata.BreakerSnapshot {
+	if reporter, ok := h.marketData.(marketdata.BreakerReporter); ok {
+		return reporter.BreakerSnapshots()
+	}
+	return []marketdata.BreakerSnapshot{}
+}
+
+// writeMarketDataError writes a 503 for a market data failure. While the
+// circuit breaker is open the response carries ErrorCodeCircuitOpen and a
+// Retry-After header so clients can back off instead of retrying.
+func (h *Handler) writeMarketDataError(w http.ResponseWriter, err error, message string) {
+	var openErr *marketdata.CircuitOpenError
+	if !errors.As(err, &openErr) {
+		h.writeErrorResponse(w, http.StatusServiceUnavailable, message)
+		return
+	}
+	
+	retryAfter := int(math.Ceil(openErr.RetryAfter.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	
+	errorResp := map[string]interface{}{
+		"error":               fmt.Sprintf("Market data provider %s is temporarily unavailable", openErr.Provider),
+		"code":                ErrorCodeCircuitOpen,
+		"status":              http.StatusServiceUnavailable,
+		"retry_after_seconds": retryAfter,
+		"timestamp":           time.Now().Format(time.RFC3339),
+	}
+	
+	h.writeJSONResponse(w, http.StatusServiceUnavailable, errorResp)
+}
+
+// assessDataQuality builds the data quality report for a bar series
+func (h *Handler) assessDataQuality(symbol string, bars []models.StockData) *models.DataQualityReport {
+	report := models.AssessDataQuality(symbol, bars, h.config.MarketData.SpikeThreshold)
+	if policy, err := models.ParseGapPolicy(h.config.MarketData.GapPolicy); err == nil {
+		report.GapPolicy = policy
+	}
+	
+	if !report.IsClean() {
+		h.logger.WithFields(logrus.Fields{
+			"symbol":     symbol,
+			"gaps":       len(report.Gaps),
+			"fills":      len(report.Fills),
+			"suspicious": len(report.Suspicious),
+		}).Warn("Data quality issues in price series")
+	}
+	
+	return report
+}
+
+// parseModel reads the model query parameter; empty means the default model
+func (h *Handler) parseModel(r *http.Request) (string, error) {
+	model := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("model")))
+	if model == "" {
+		return "", nil
+	}
+	if !h.predictionService.Models().Has(model) {
+		return "", fmt.Errorf("invalid model value: %s (valid options: %s)", model,
+			strings.Join(h.predictionService.Models().Names(), ", "))
+	}
+	return model, nil
+}
+
+// parseDebug reads the debug query parameter, defaulting to false
+func parseDebug(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("debug")
+	if value == "" {
+		return false, nil
+	}
+	debug, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid debug value: %s (valid options: true, false)", value)
+	}
+	return debug, nil
+}
+
+// parseAdjusted reads the adjusted query parameter, defaulting to config
+func (h *Handler) parseAdjusted(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("adjusted")
+	if value == "" {
+		return h.config.MarketData.AdjustPrices, nil
+	}
+	adjusted, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid adjusted value: %s (valid options: true, false)", value)
+	}
+	return adjusted, nil
+}
+
 func (h *Handler) writeJSONResponse(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -257,23 +942,6 @@ func (h *Handler) writeErrorResponse(w http.ResponseWriter, status int, message
 	h.writeJSONResponse(w, status, errorResp)
 }
 
-func (h *Handler) getPeriodFromDays(days int) string {
-	switch {
-	case days <= 7:
-		return "7d"
-	case days <= 30:
-		return "1mo"
-	case days <= 90:
-		return "3mo"
-	case days <= 180:
-		return "6mo"
-	case days <= 365:
-		return "1y"
-	default:
-		return "2y"
-	}
-}
-
 func (h *Handler) getSystemStats() map[string]interface{} {
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
@@ -311,6 +979,46 @@ func (h *Handler) LoggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// SandboxMiddleware marks every response as built from synthetic market data
+func (h *Handler) SandboxMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(SyntheticDataHeader, "true")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AdminMiddleware guards endpoints that change what the service serves.
+// With SERVER_ADMIN_TOKEN set they need "Authorization: Bearer <token>";
+// without one they only answer clients on the local host.
+func (h *Handler) AdminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions && !h.isAdmin(r) {
+			h.logger.WithFields(logrus.Fields{
+				"path":      r.URL.Path,
+				"client_ip": r.RemoteAddr,
+			}).Warn("Rejected unauthorized admin request")
+			h.writeErrorResponse(w, http.StatusUnauthorized, "Admin authorization required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isAdmin reports whether a request may use the admin endpoints
+func (h *Handler) isAdmin(r *http.Request) bool {
+	token := h.config.Server.AdminToken
+	if token == "" {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			return false
+		}
+		ip := net.ParseIP(host)
+		return ip != nil && ip.IsLoopback()
+	}
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
 // CORSMiddleware handles CORS headers with comprehensive support
 func (h *Handler) CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -329,7 +1037,7 @@ func (h *Handler) CORSMiddleware(next http.Handler) http.Handler {
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
 		
 		// Allow common headers including those used by Angular HttpClient
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Requested-With, Origin")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Requested-With, Origin, Last-Event-ID")
 		
 		// Allow exposure of custom headers
 		w.Header().Set("Access-Control-Expose-Headers", "Content-Length, Content-Type")
@@ -359,7 +1067,7 @@ func (h *Handler) OptionsHandler(w http.ResponseWriter, r *http.Request) {
 	
 	w.Header().Set("Access-Control-Allow-Credentials", "true")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-	w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Requested-With, Origin")
+	w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Requested-With, Origin, Last-Event-ID")
 	w.Header().Set("Access-Control-Expose-Headers", "Content-Length, Content-Type")
 	w.Header().Set("Access-Control-Max-Age", "86400")
 	
@@ -376,3 +1084,9 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap exposes the underlying writer to http.ResponseController, which the
+// quote stream uses to flush events and extend write deadlines
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
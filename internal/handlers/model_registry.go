@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"stock-prediction-us/internal/models"
+	"stock-prediction-us/internal/services"
+	"stock-prediction-us/internal/services/prediction"
+)
+
+// ModelRegistryHandler serves the model registry: registering model
+// versions and moving them in and out of production
+type ModelRegistryHandler struct {
+	logger            *logrus.Logger
+	registry          *services.ModelRegistryService
+	predictionService *prediction.EnhancedPredictionService
+	admin             mux.MiddlewareFunc // Guards the routes that change the registry
+}
+
+// NewModelRegistryHandler creates a new model registry handler
+func NewModelRegistryHandler(logger *logrus.Logger, registry *services.ModelRegistryService, predictionService *prediction.EnhancedPredictionService, admin mux.MiddlewareFunc) *ModelRegistryHandler {
+	return &ModelRegistryHandler{
+		logger:            logger,
+		registry:          registry,
+		predictionService: predictionService,
+		admin:             admin,
+	}
+}
+
+// RegisterRoutes registers the model registry routes. Registering,
+// promoting and rolling back versions are admin operations.
+func (h *ModelRegistryHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/models/registry", h.ListVersionsHandler).Methods("GET", "OPTIONS")
+	router.Handle("/api/v1/models/registry", h.admin(http.HandlerFunc(h.RegisterVersionHandler))).Methods("POST", "OPTIONS")
+	router.Handle("/api/v1/models/registry/{name}/{version}/promote", h.admin(http.HandlerFunc(h.PromoteHandler))).Methods("POST", "OPTIONS")
+	router.Handle("/api/v1/models/registry/{name}/rollback", h.admin(http.HandlerFunc(h.RollbackHandler))).Methods("POST", "OPTIONS")
+}
+
+// ListVersionsHandler lists registered model versions, optionally for one
+// model with ?name=
+func (h *ModelRegistryHandler) ListVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	name := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("name")))
+	versions, err := h.registry.List(r.Context(), name)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list model versions")
+		h.writeError(w, http.StatusInternalServerError, "Failed to list model versions")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"versions": versions,
+		"count":    len(versions),
+	})
+}
+
+// RegisterVersionHandler registers a model version in staging. The model
+// must be one the prediction service serves from an artifact, so that
+// promoting the version changes what makes predictions.
+func (h *ModelRegistryHandler) RegisterVersionHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.RegisterModelRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !h.predictionService.Models().Has(req.Name) {
+		h.writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown prediction model: %s, valid options: %v",
+			req.Name, h.predictionService.Models().Names()))
+		return
+	}
+	if !h.predictionService.LoadsArtifact(req.Name) {
+		h.writeError(w, http.StatusBadRequest, fmt.Sprintf("model %s does not load an artifact and cannot be versioned", req.Name))
+		return
+	}
+
+	version, err := h.registry.Register(r.Context(), req)
+	if err != nil {
+		h.writeRegistryError(w, err, "Failed to register model version")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"model":   version.Name,
+		"version": version.Version,
+		"sha256":  version.SHA256,
+	}).Info("Model version registered")
+	h.writeJSON(w, http.StatusCreated, version)
+}
+
+// PromoteHandler puts a model version into production
+func (h *ModelRegistryHandler) PromoteHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	previous, _ := h.registry.Production(vars["name"])
+	version, err := h.registry.Promote(r.Context(), vars["name"], vars["version"])
+	if err != nil {
+		h.writeRegistryError(w, err, "Failed to promote model version")
+		return
+	}
+	h.changedProduction(w, r, "Model version promoted", version, previous)
+}
+
+// RollbackHandler restores the production version that preceded the
+// current one
+func (h *ModelRegistryHandler) RollbackHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	previous, _ := h.registry.Production(name)
+	version, err := h.registry.Rollback(r.Context(), name)
+	if err != nil {
+		h.writeRegistryError(w, err, "Failed to roll back model version")
+		return
+	}
+	h.changedProduction(w, r, "Model version rolled back", version, previous)
+}
+
+// changedProduction clears cached predictions, which name the version that
+// made them, and reports the new production version
+func (h *ModelRegistryHandler) changedProduction(w http.ResponseWriter, r *http.Request, message string, version, previous *models.ModelVersion) {
+	h.predictionService.ClearCache()
+
+	fields := logrus.Fields{
+		"model":     version.Name,
+		"version":   version.Version,
+		"client_ip": r.RemoteAddr,
+	}
+	response := map[string]interface{}{
+		"production": version,
+		"time":       time.Now().Format(time.RFC3339),
+	}
+	if previous != nil {
+		fields["previous"] = previous.Version
+		response["previous"] = previous.Version
+	}
+	h.logger.WithFields(fields).Warn(message)
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+// writeRegistryError maps model registry errors to status codes
+func (h *ModelRegistryHandler) writeRegistryError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrModelVersionNotFound):
+		h.writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrModelVersionExists), errors.Is(err, services.ErrInvalidModelTransition):
+		h.writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrInvalidModelArtifact):
+		h.writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.WithError(err).Error(message)
+		h.writeError(w, http.StatusInternalServerError, message)
+	}
+}
+
+func (h *ModelRegistryHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode JSON response")
+	}
+}
+
+func (h *ModelRegistryHandler) writeError(w http.ResponseWriter, status int, message string) {
+	h.writeJSON(w, status, map[string]interface{}{
+		"error":     message,
+		"status":    status,
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
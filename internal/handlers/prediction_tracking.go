@@ -74,7 +74,7 @@ func (h *PredictionTrackingHandler) ExecuteDailyPredictions(w http.ResponseWrite
 	req.ExecutionType = models.ExecutionTypeManual
 
 	// Execute predictions
-	result, err := h.predictionTracker.ExecuteDailyPredictions(req)
+	result, err := h.predictionTracker.ExecuteDailyPredictions(r.Context(), req)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to execute predictions: %v", err), http.StatusInternalServerError)
 		return
@@ -99,10 +99,9 @@ func (h *PredictionTrackingHandler) GetDailyStatus(w http.ResponseWriter, r *htt
 // GetAccuracySummary returns accuracy summary for a specific symbol
 func (h *PredictionTrackingHandler) GetAccuracySummary(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	symbol := vars["symbol"]
-
-	if symbol == "" {
-		http.Error(w, "Symbol is required", http.StatusBadRequest)
+	symbol, err := models.NormalizeSymbol(vars["symbol"])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid symbol: %v", err), http.StatusBadRequest)
 		return
 	}
 
@@ -118,7 +117,13 @@ func (h *PredictionTrackingHandler) GetAccuracySummary(w http.ResponseWriter, r
 
 // GetOverallPerformance returns overall performance metrics
 func (h *PredictionTrackingHandler) GetOverallPerformance(w http.ResponseWriter, r *http.Request) {
-	metrics, err := h.accuracyCalculator.GetOverallPerformanceMetrics()
+	currency, err := parseCurrency(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	metrics, err := h.accuracyCalculator.GetOverallPerformanceMetrics(r.Context(), currency)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to get performance metrics: %v", err), http.StatusInternalServerError)
 		return
@@ -182,15 +187,21 @@ func (h *PredictionTrackingHandler) GetAccuracyRange(w http.ResponseWriter, r *h
 // GetPredictionHistory returns prediction history for a specific symbol
 func (h *PredictionTrackingHandler) GetPredictionHistory(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	symbol := vars["symbol"]
+	symbol, err := models.NormalizeSymbol(vars["symbol"])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid symbol: %v", err), http.StatusBadRequest)
+		return
+	}
 
-	if symbol == "" {
-		http.Error(w, "Symbol is required", http.StatusBadRequest)
+	currency, err := parseCurrency(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	query := h.parsePredictionHistoryQuery(r, &symbol)
-	predictions, err := h.predictionTracker.GetPredictionHistory(query)
+	query.Currency = currency
+	predictions, err := h.predictionTracker.GetPredictionHistory(r.Context(), query)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to get prediction history: %v", err), http.StatusInternalServerError)
 		return
@@ -202,8 +213,15 @@ func (h *PredictionTrackingHandler) GetPredictionHistory(w http.ResponseWriter,
 
 // GetAllPredictionHistory returns prediction history for all symbols
 func (h *PredictionTrackingHandler) GetAllPredictionHistory(w http.ResponseWriter, r *http.Request) {
+	currency, err := parseCurrency(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	query := h.parsePredictionHistoryQuery(r, nil)
-	predictions, err := h.predictionTracker.GetPredictionHistory(query)
+	query.Currency = currency
+	predictions, err := h.predictionTracker.GetPredictionHistory(r.Context(), query)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to get prediction history: %v", err), http.StatusInternalServerError)
 		return
@@ -213,7 +231,8 @@ func (h *PredictionTrackingHandler) GetAllPredictionHistory(w http.ResponseWrite
 	json.NewEncoder(w).Encode(predictions)
 }
 
-// UpdateActualPrice updates the actual closing price for a prediction
+// UpdateActualPrice updates the actual closing price for a prediction. An
+// actual_close of 0, or none, uses the stored close for the date.
 func (h *PredictionTrackingHandler) UpdateActualPrice(w http.ResponseWriter, r *http.Request) {
 	var req models.UpdateActualPriceRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -226,12 +245,13 @@ func (h *PredictionTrackingHandler) UpdateActualPrice(w http.ResponseWriter, r *
 		return
 	}
 
-	if req.ActualClose <= 0 {
-		http.Error(w, "Actual close price must be positive", http.StatusBadRequest)
+	// A missing actual_close is reconciled from stored market data
+	if req.ActualClose < 0 {
+		http.Error(w, "Actual close price must not be negative", http.StatusBadRequest)
 		return
 	}
 
-	err := h.predictionTracker.UpdateActualPrice(req)
+	err := h.predictionTracker.UpdateActualPrice(r.Context(), req)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to update actual price: %v", err), http.StatusInternalServerError)
 		return
@@ -243,7 +263,13 @@ func (h *PredictionTrackingHandler) UpdateActualPrice(w http.ResponseWriter, r *
 
 // GetPerformanceMetrics returns overall performance metrics
 func (h *PredictionTrackingHandler) GetPerformanceMetrics(w http.ResponseWriter, r *http.Request) {
-	metrics, err := h.accuracyCalculator.GetOverallPerformanceMetrics()
+	currency, err := parseCurrency(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	metrics, err := h.accuracyCalculator.GetOverallPerformanceMetrics(r.Context(), currency)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to get performance metrics: %v", err), http.StatusInternalServerError)
 		return
@@ -256,10 +282,9 @@ func (h *PredictionTrackingHandler) GetPerformanceMetrics(w http.ResponseWriter,
 // GetAccuracyTrends returns accuracy trends for a symbol
 func (h *PredictionTrackingHandler) GetAccuracyTrends(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	symbol := vars["symbol"]
-
-	if symbol == "" {
-		http.Error(w, "Symbol is required", http.StatusBadRequest)
+	symbol, err := models.NormalizeSymbol(vars["symbol"])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid symbol: %v", err), http.StatusBadRequest)
 		return
 	}
 
@@ -324,6 +349,13 @@ func (h *PredictionTrackingHandler) parsePredictionHistoryQuery(r *http.Request,
 		}
 	}
 
+	// Parse horizon
+	if horizonStr := urlQuery.Get("horizon"); horizonStr != "" {
+		if horizon, err := strconv.Atoi(horizonStr); err == nil && horizon > 0 {
+			query.Horizon = &horizon
+		}
+	}
+
 	// Parse limit
 	if limitStr := urlQuery.Get("limit"); limitStr != "" {
 		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
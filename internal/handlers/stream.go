@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"stock-prediction-us/internal/config"
+	"stock-prediction-us/internal/services/stream"
+)
+
+// streamRetry is the reconnect delay suggested to EventSource clients
+const streamRetry = 3 * time.Second
+
+// StreamHandler serves Server-Sent Events streams backed by the quote hub
+type StreamHandler struct {
+	config *config.Config
+	logger *logrus.Logger
+	hub    *stream.Hub
+}
+
+// streamQuote is the data payload of a quote event
+type streamQuote struct {
+	stream.Quote
+	Synthetic bool `json:"synthetic,omitempty"`
+}
+
+// NewStreamHandler creates a new stream handler
+func NewStreamHandler(cfg *config.Config, logger *logrus.Logger, hub *stream.Hub) *StreamHandler {
+	return &StreamHandler{
+		config: cfg,
+		logger: logger,
+		hub:    hub,
+	}
+}
+
+// RegisterRoutes registers the streaming routes
+func (h *StreamHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/v1/stream/quotes", h.QuotesStreamHandler).Methods("GET", "OPTIONS")
+}
+
+// QuotesStreamHandler streams quote updates for ?symbols= as Server-Sent
+// Events. Reconnecting clients send Last-Event-ID (or ?lastEventId= when the
+// header cannot be set) and receive the retained events they missed; idle
+// connections get a heartbeat comment.
+func (h *StreamHandler) QuotesStreamHandler(w http.ResponseWriter, r *http.Request) {
+	symbols, invalid := parseSymbolList(r.URL.Query().Get("symbols"))
+	switch {
+	case len(invalid) > 0:
+		h.writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid symbol %v: %v", invalid[0]["symbol"], invalid[0]["error"]))
+		return
+	case len(symbols) == 0:
+		h.writeError(w, http.StatusBadRequest, "symbols is required")
+		return
+	case len(symbols) > maxQuoteSymbols:
+		h.writeError(w, http.StatusBadRequest, fmt.Sprintf("too many symbols: %d (maximum %d)", len(symbols), maxQuoteSymbols))
+		return
+	}
+
+	lastEventID, resume, err := parseLastEventID(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	controller := http.NewResponseController(w)
+	sub, replay := h.hub.Subscribe(symbols, lastEventID, resume)
+	defer h.hub.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable proxy buffering
+	w.WriteHeader(http.StatusOK)
+
+	if err := h.write(w, controller, fmt.Sprintf("retry: %d\n\n", streamRetry.Milliseconds())); err != nil {
+		return
+	}
+	for _, event := range replay {
+		if err := h.writeEvent(w, controller, event); err != nil {
+			return
+		}
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"symbols":  sub.Symbols(),
+		"resume":   resume,
+		"replayed": len(replay),
+	}).Debug("Quote stream opened")
+
+	interval := h.config.Stream.Heartbeat
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-sub.Done():
+			// Dropped for falling behind or shutting down; the client
+			// reconnects and resumes from its last event
+			return
+		case event := <-sub.Events():
+			if err := h.writeEvent(w, controller, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := h.write(w, controller, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeEvent writes one quote event
+func (h *StreamHandler) writeEvent(w http.ResponseWriter, controller *http.ResponseController, event stream.Event) error {
+	data, err := json.Marshal(streamQuote{Quote: event.Quote, Synthetic: h.config.IsSandbox()})
+	if err != nil {
+		return err
+	}
+	return h.write(w, controller, fmt.Sprintf("id: %d\nevent: quote\ndata: %s\n\n", event.ID, data))
+}
+
+// write sends a frame and flushes it. Each write gets its own deadline so the
+// server write timeout bounds stalled clients instead of the whole stream.
+func (h *StreamHandler) write(w http.ResponseWriter, controller *http.ResponseController, frame string) error {
+	if timeout := h.config.Server.WriteTimeout; timeout > 0 {
+		err := controller.SetWriteDeadline(time.Now().Add(timeout))
+		if err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+	}
+	if _, err := w.Write([]byte(frame)); err != nil {
+		return err
+	}
+	return controller.Flush()
+}
+
+// writeError writes a JSON error before the stream has started
+func (h *StreamHandler) writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":     message,
+		"status":    status,
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// parseLastEventID reads the resume position from the Last-Event-ID header
+// or the lastEventId query parameter
+func parseLastEventID(r *http.Request) (uint64, bool, error) {
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = r.URL.Query().Get("lastEventId")
+	}
+	if raw == "" {
+		return 0, false, nil
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid Last-Event-ID: %s", raw)
+	}
+	return id, true, nil
+}
@@ -28,6 +28,23 @@ type Metrics struct {
 	StockDataErrors      prometheus.Counter
 	StockDataLatency     prometheus.Histogram
 	
+	// Circuit breaker metrics
+	CircuitBreakerState       *prometheus.GaugeVec
+	CircuitBreakerTransitions *prometheus.CounterVec
+	
+	// Quote stream metrics
+	StreamSubscribers   prometheus.Gauge
+	StreamPollers       prometheus.Gauge
+	StreamDroppedEvents prometheus.Counter
+	
+	// Python worker pool metrics
+	PythonWorkersLive       prometheus.Gauge
+	PythonWorkersBusy       prometheus.Gauge
+	PythonWorkerStarts      prometheus.Counter
+	PythonWorkerStops       *prometheus.CounterVec
+	PythonWorkerRequests    *prometheus.CounterVec
+	PythonWorkerLatency     prometheus.Histogram
+	
 	// System metrics
 	ActiveConnections    prometheus.Gauge
 	MemoryUsage          prometheus.Gauge
@@ -109,6 +126,65 @@ func NewMetrics() *Metrics {
 			Buckets: []float64{0.1, 0.5, 1.0, 2.0, 5.0},
 		}),
 		
+		// Circuit breaker metrics
+		CircuitBreakerState: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "market_data_circuit_breaker_state",
+			Help: "Market data circuit breaker state (0 closed, 1 half-open, 2 open)",
+		}, []string{"provider"}),
+		
+		CircuitBreakerTransitions: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "market_data_circuit_breaker_transitions_total",
+			Help: "Total number of market data circuit breaker state transitions",
+		}, []string{"provider", "from", "to"}),
+		
+		// Quote stream metrics
+		StreamSubscribers: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "quote_stream_subscribers",
+			Help: "Number of connected quote stream subscribers",
+		}),
+		
+		StreamPollers: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "quote_stream_pollers",
+			Help: "Number of symbols polled for quote stream subscribers",
+		}),
+		
+		StreamDroppedEvents: promauto.NewCounter(prometheus.CounterOpts{
+			Name: "quote_stream_dropped_events_total",
+			Help: "Total number of quote events dropped for slow subscribers",
+		}),
+		
+		// Python worker pool metrics
+		PythonWorkersLive: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "python_workers_live",
+			Help: "Number of running Python model worker processes",
+		}),
+		
+		PythonWorkersBusy: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "python_workers_busy",
+			Help: "Number of Python model workers serving a request",
+		}),
+		
+		PythonWorkerStarts: promauto.NewCounter(prometheus.CounterOpts{
+			Name: "python_worker_starts_total",
+			Help: "Total number of Python model worker processes started",
+		}),
+		
+		PythonWorkerStops: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "python_worker_stops_total",
+			Help: "Total number of Python model workers stopped, by reason (crash, timeout, cancelled, recycle)",
+		}, []string{"reason"}),
+		
+		PythonWorkerRequests: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "python_worker_requests_total",
+			Help: "Total number of Python model worker requests, by result",
+		}, []string{"result"}),
+		
+		PythonWorkerLatency: promauto.NewHistogram(prometheus.HistogramOpts{
+			Name:    "python_worker_request_seconds",
+			Help:    "Duration of Python model worker requests, including waiting for a free worker",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1.0, 2.0, 5.0, 10.0},
+		}),
+		
 		// System metrics
 		ActiveConnections: promauto.NewGauge(prometheus.GaugeOpts{
 			Name: "active_connections",
@@ -169,6 +245,54 @@ func (m *Metrics) RecordStockDataFetch(duration float64, success bool) {
 	}
 }
 
+// RecordCircuitBreakerTransition records a circuit breaker state change
+func (m *Metrics) RecordCircuitBreakerTransition(provider, from, to string, state float64) {
+	m.CircuitBreakerTransitions.WithLabelValues(provider, from, to).Inc()
+	m.CircuitBreakerState.WithLabelValues(provider).Set(state)
+}
+
+// UpdateCircuitBreakerState sets the circuit breaker state gauge
+func (m *Metrics) UpdateCircuitBreakerState(provider string, state float64) {
+	m.CircuitBreakerState.WithLabelValues(provider).Set(state)
+}
+
+// UpdateStreamStats sets the quote stream subscriber and poller gauges
+func (m *Metrics) UpdateStreamStats(subscribers, pollers int) {
+	m.StreamSubscribers.Set(float64(subscribers))
+	m.StreamPollers.Set(float64(pollers))
+}
+
+// UpdatePythonWorkers sets the running and busy Python worker gauges
+func (m *Metrics) UpdatePythonWorkers(live, busy int) {
+	m.PythonWorkersLive.Set(float64(live))
+	m.PythonWorkersBusy.Set(float64(busy))
+}
+
+// RecordPythonWorkerStart records a Python worker process start
+func (m *Metrics) RecordPythonWorkerStart() {
+	m.PythonWorkerStarts.Inc()
+}
+
+// RecordPythonWorkerStop records a Python worker stopped for a reason
+func (m *Metrics) RecordPythonWorkerStop(reason string) {
+	m.PythonWorkerStops.WithLabelValues(reason).Inc()
+}
+
+// RecordPythonWorkerRequest records a model run on a Python worker
+func (m *Metrics) RecordPythonWorkerRequest(duration float64, success bool) {
+	result := "success"
+	if !success {
+		result = "error"
+	}
+	m.PythonWorkerRequests.WithLabelValues(result).Inc()
+	m.PythonWorkerLatency.Observe(duration)
+}
+
+// RecordStreamDroppedEvent records a quote event dropped for a slow subscriber
+func (m *Metrics) RecordStreamDroppedEvent() {
+	m.StreamDroppedEvents.Inc()
+}
+
 // UpdatePredictionAccuracy updates the prediction accuracy metric
 func (m *Metrics) UpdatePredictionAccuracy(accuracy float64) {
 	m.PredictionAccuracy.Set(accuracy)
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+)
+
+// CorporateAction represents a split or cash dividend affecting a price series
+type CorporateAction struct {
+	Symbol      string    `json:"symbol"`
+	Date        time.Time `json:"date"` // ex-date
+	Type        string    `json:"type"` // 'split', 'dividend'
+	Amount      float64   `json:"amount,omitempty"`      // dividend per share
+	Numerator   float64   `json:"numerator,omitempty"`   // split new shares
+	Denominator float64   `json:"denominator,omitempty"` // split old shares
+}
+
+// Constants for corporate action types
+const (
+	ActionSplit    = "split"
+	ActionDividend = "dividend"
+)
+
+// SplitRatio returns new shares per old share, or 1 for non-split actions
+func (a CorporateAction) SplitRatio() float64 {
+	if a.Type != ActionSplit || a.Numerator <= 0 || a.Denominator <= 0 {
+		return 1
+	}
+	return a.Numerator / a.Denominator
+}
+
+// SplitFactorBetween returns the cumulative split ratio for splits with an
+// ex-date in (after, upTo]. Prices quoted before the window divide by it.
+func SplitFactorBetween(actions []CorporateAction, after, upTo time.Time) float64 {
+	factor := 1.0
+	for _, action := range actions {
+		if action.Type != ActionSplit {
+			continue
+		}
+		if action.Date.After(after) && !action.Date.After(upTo) {
+			factor *= action.SplitRatio()
+		}
+	}
+	return factor
+}
+
+// ClosePrices extracts raw or split/dividend adjusted closes from bars.
+// Bars without an adjusted close fall back to the raw close.
+func ClosePrices(bars []StockData, adjusted bool) []float64 {
+	closes := make([]float64, len(bars))
+	for i, bar := range bars {
+		closes[i] = bar.Close
+		if adjusted && bar.AdjClose > 0 {
+			closes[i] = bar.AdjClose
+		}
+	}
+	return closes
+}
+
+// AdjustedBars returns a copy of bars with open, high, low and close scaled
+// onto the adjusted close basis. Volume is left as reported.
+func AdjustedBars(bars []StockData) []StockData {
+	adjusted := make([]StockData, len(bars))
+	for i, bar := range bars {
+		adjusted[i] = bar
+		if bar.AdjClose <= 0 || bar.Close <= 0 {
+			continue
+		}
+		ratio := bar.AdjClose / bar.Close
+		adjusted[i].Open = bar.Open * ratio
+		adjusted[i].High = bar.High * ratio
+		adjusted[i].Low = bar.Low * ratio
+		adjusted[i].Close = bar.AdjClose
+	}
+	return adjusted
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitFactorBetween(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 0, 0, 0, 0, time.UTC) }
+	actions := []CorporateAction{
+		{Symbol: "NVDA", Date: day(10), Type: ActionSplit, Numerator: 10, Denominator: 1},
+		{Symbol: "NVDA", Date: day(12), Type: ActionDividend, Amount: 0.01},
+	}
+
+	assert.Equal(t, 10.0, SplitFactorBetween(actions, day(7), day(10)))
+	assert.Equal(t, 10.0, SplitFactorBetween(actions, day(7), day(14)))
+	assert.Equal(t, 1.0, SplitFactorBetween(actions, day(10), day(14)))
+	assert.Equal(t, 1.0, SplitFactorBetween(actions, day(3), day(7)))
+}
+
+func TestAdjustedSeries(t *testing.T) {
+	bars := []StockData{
+		{Open: 1200, High: 1210, Low: 1190, Close: 1200, AdjClose: 120, Volume: 100},
+		{Open: 121, High: 122, Low: 120, Close: 121, Volume: 1000},
+	}
+
+	assert.Equal(t, []float64{1200, 121}, ClosePrices(bars, false))
+	assert.Equal(t, []float64{120, 121}, ClosePrices(bars, true))
+
+	adjusted := AdjustedBars(bars)
+	assert.InDelta(t, 121.0, adjusted[0].High, 1e-9)
+	assert.Equal(t, int64(100), adjusted[0].Volume)
+	assert.Equal(t, bars[1], adjusted[1])
+	assert.Equal(t, 1200.0, bars[0].Close)
+}
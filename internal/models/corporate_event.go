@@ -0,0 +1,126 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// CorporateEvent is a scheduled or past event that tends to move a stock:
+// an earnings release, an ex-dividend date or a split
+type CorporateEvent struct {
+	Symbol      string    `json:"symbol"`
+	Date        time.Time `json:"date"`             // exchange-local calendar date
+	Type        string    `json:"type"`             // 'earnings', 'ex_dividend', 'split'
+	Timing      string    `json:"timing,omitempty"` // earnings: 'bmo' (before open), 'amc' (after close)
+	Description string    `json:"description,omitempty"`
+	Source      string    `json:"source"` // provider name, 'csv' or 'ics'
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Constants for corporate event types
+const (
+	EventEarnings   = "earnings"
+	EventExDividend = "ex_dividend"
+	EventSplit      = "split"
+)
+
+// Constants for earnings release timing
+const (
+	TimingBeforeOpen = "bmo"
+	TimingAfterClose = "amc"
+)
+
+// ParseEventType normalises an event type, accepting common spellings
+// such as "Earnings Call", "ex-dividend" or "stock split"
+func ParseEventType(value string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case normalized == "":
+		return "", fmt.Errorf("event type cannot be empty")
+	case strings.Contains(normalized, "earning"):
+		return EventEarnings, nil
+	case strings.Contains(normalized, "dividend"):
+		return EventExDividend, nil
+	case strings.Contains(normalized, "split"):
+		return EventSplit, nil
+	default:
+		return "", fmt.Errorf("unknown event type: %s (valid options: %s, %s, %s)", value, EventEarnings, EventExDividend, EventSplit)
+	}
+}
+
+// ParseEventTiming normalises an earnings timing; unknown values return ""
+func ParseEventTiming(value string) string {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "bmo", "before open", "before market open", "pre-market", "premarket":
+		return TimingBeforeOpen
+	case "amc", "after close", "after market close", "post-market", "postmarket":
+		return TimingAfterClose
+	default:
+		return ""
+	}
+}
+
+// EventsFromCorporateActions converts past splits and dividends into events
+func EventsFromCorporateActions(actions []CorporateAction, source string) []CorporateEvent {
+	events := make([]CorporateEvent, 0, len(actions))
+	for _, action := range actions {
+		event := CorporateEvent{
+			Symbol: action.Symbol,
+			Date:   action.Date,
+			Source: source,
+		}
+		switch action.Type {
+		case ActionSplit:
+			event.Type = EventSplit
+			event.Description = fmt.Sprintf("%g:%g split", action.Numerator, action.Denominator)
+		case ActionDividend:
+			event.Type = EventExDividend
+			event.Description = fmt.Sprintf("dividend %.4f per share", action.Amount)
+		default:
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// EventRisk describes the corporate events near a prediction's target date.
+// A forecast is flagged when an event falls on the target date or within
+// the few trading days before it, while the market is still reacting.
+type EventRisk struct {
+	TargetDate       time.Time        `json:"target_date"`
+	Flagged          bool             `json:"flagged"`
+	Events           []CorporateEvent `json:"events,omitempty"`
+	ConfidenceFactor float64          `json:"confidence_factor,omitempty"` // Applied to confidence when down-weighting
+}
+
+// EventTypes returns the distinct event types behind the flag
+func (r *EventRisk) EventTypes() []string {
+	if r == nil {
+		return nil
+	}
+	var types []string
+	seen := make(map[string]bool)
+	for _, event := range r.Events {
+		if !seen[event.Type] {
+			seen[event.Type] = true
+			types = append(types, event.Type)
+		}
+	}
+	return types
+}
+
+// CalculateEventAwareConfidence is CalculateAdvancedConfidence with the
+// prediction's event risk applied: flagged forecasts are scaled by the
+// risk's confidence factor when down-weighting is enabled
+func CalculateEventAwareConfidence(currentPrice, predictedPrice float64, historicalPrices []float64, risk *EventRisk) float64 {
+	confidence := CalculateAdvancedConfidence(currentPrice, predictedPrice, historicalPrices)
+	if risk == nil || !risk.Flagged || risk.ConfidenceFactor <= 0 || risk.ConfidenceFactor >= 1 {
+		return confidence
+	}
+
+	// Down-weighting may take confidence below the usual 0.15 floor
+	return math.Max(0.05, confidence*risk.ConfidenceFactor)
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEventType(t *testing.T) {
+	for input, expected := range map[string]string{
+		"Earnings Call": EventEarnings,
+		"ex-dividend":   EventExDividend,
+		"EX_DIVIDEND":   EventExDividend,
+		"Stock Split":   EventSplit,
+	} {
+		parsed, err := ParseEventType(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, parsed, input)
+	}
+
+	_, err := ParseEventType("conference")
+	assert.Error(t, err)
+}
+
+func TestCalculateEventAwareConfidence(t *testing.T) {
+	prices := []float64{100, 101, 102, 101.5, 103}
+	base := CalculateAdvancedConfidence(103, 104, prices)
+	target := time.Date(2026, 11, 20, 0, 0, 0, 0, time.UTC)
+
+	// Flagging alone leaves confidence untouched
+	flagged := &EventRisk{TargetDate: target, Flagged: true}
+	assert.Equal(t, base, CalculateEventAwareConfidence(103, 104, prices, flagged))
+	assert.Equal(t, base, CalculateEventAwareConfidence(103, 104, prices, nil))
+
+	flagged.ConfidenceFactor = 0.5
+	assert.InDelta(t, base*0.5, CalculateEventAwareConfidence(103, 104, prices, flagged), 1e-9)
+
+	// The factor only applies to flagged predictions
+	clear := &EventRisk{TargetDate: target, ConfidenceFactor: 0.5}
+	assert.Equal(t, base, CalculateEventAwareConfidence(103, 104, prices, clear))
+}
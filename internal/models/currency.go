@@ -0,0 +1,70 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultCurrency is assumed for tickers that do not parse
+const DefaultCurrency = "USD"
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// minorUnits maps Yahoo's sub-unit quote currencies to their major currency
+// and the size of one sub-unit, e.g. LSE listings are quoted in pence
+var minorUnits = map[string]struct {
+	Major string
+	Scale float64
+}{
+	"GBp": {"GBP", 0.01},
+	"GBX": {"GBP", 0.01},
+	"ZAc": {"ZAR", 0.01},
+	"ILA": {"ILS", 0.01},
+}
+
+// peggedCurrencies are crypto quote legs treated as their fiat peg
+var peggedCurrencies = map[string]string{
+	"USDT": "USD",
+	"USDC": "USD",
+}
+
+// Currency returns the currency the symbol's prices are quoted in
+func (s Symbol) Currency() string {
+	if s.Exchange.Currency == "" {
+		return DefaultCurrency
+	}
+	return s.Exchange.Currency
+}
+
+// CurrencyOf returns the quote currency of a ticker, defaulting to USD for
+// tickers that do not parse
+func CurrencyOf(ticker string) string {
+	if symbol, err := ParseSymbol(ticker); err == nil {
+		return symbol.Currency()
+	}
+	return DefaultCurrency
+}
+
+// NormalizeCurrency validates a reporting currency such as "usd" and returns
+// its ISO 4217 code
+func NormalizeCurrency(raw string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(raw))
+	if !currencyCodePattern.MatchString(code) {
+		return "", fmt.Errorf("invalid currency: %q (expected an ISO 4217 code such as USD)", raw)
+	}
+	return code, nil
+}
+
+// MajorCurrency resolves sub-unit and pegged quote currencies to the
+// currency FX rates are published for, with the value of one quoted unit in
+// that currency: GBp is 0.01 GBP and USDT is 1 USD.
+func MajorCurrency(currency string) (string, float64) {
+	if minor, ok := minorUnits[currency]; ok {
+		return minor.Major, minor.Scale
+	}
+	if peg, ok := peggedCurrencies[currency]; ok {
+		return peg, 1
+	}
+	return strings.ToUpper(currency), 1
+}
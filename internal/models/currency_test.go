@@ -0,0 +1,46 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyOf(t *testing.T) {
+	assert.Equal(t, "USD", CurrencyOf("NVDA"))
+	assert.Equal(t, "TWD", CurrencyOf("2330.TW"))
+	assert.Equal(t, "GBp", CurrencyOf("VOD.L"))
+	assert.Equal(t, "EUR", CurrencyOf("BTC-EUR"))
+	assert.Equal(t, DefaultCurrency, CurrencyOf("not a ticker"))
+}
+
+func TestNormalizeCurrency(t *testing.T) {
+	code, err := NormalizeCurrency(" usd ")
+	require.NoError(t, err)
+	assert.Equal(t, "USD", code)
+
+	for _, raw := range []string{"", "US", "DOLLAR", "U$D"} {
+		_, err := NormalizeCurrency(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestMajorCurrency(t *testing.T) {
+	tests := []struct {
+		currency string
+		major    string
+		scale    float64
+	}{
+		{"USD", "USD", 1},
+		{"GBp", "GBP", 0.01},
+		{"ZAc", "ZAR", 0.01},
+		{"USDT", "USD", 1},
+		{"twd", "TWD", 1},
+	}
+	for _, tt := range tests {
+		major, scale := MajorCurrency(tt.currency)
+		assert.Equal(t, tt.major, major, tt.currency)
+		assert.Equal(t, tt.scale, scale, tt.currency)
+	}
+}
@@ -0,0 +1,200 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// GapPolicy controls how bars with a missing close are handled
+type GapPolicy string
+
+const (
+	GapPolicyDrop        GapPolicy = "drop"
+	GapPolicyForwardFill GapPolicy = "forward_fill"
+	GapPolicyInterpolate GapPolicy = "interpolate"
+)
+
+// ParseGapPolicy parses a gap policy string, defaulting to forward fill
+func ParseGapPolicy(s string) (GapPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "forward_fill", "forward-fill", "ffill":
+		return GapPolicyForwardFill, nil
+	case "drop":
+		return GapPolicyDrop, nil
+	case "interpolate":
+		return GapPolicyInterpolate, nil
+	default:
+		return "", fmt.Errorf("unsupported gap policy: %s (valid options: drop, forward_fill, interpolate)", s)
+	}
+}
+
+// BarIssue describes a gap, fill or suspicious bar in a price series
+type BarIssue struct {
+	Timestamp time.Time `json:"timestamp"`
+	Reason    string    `json:"reason"`
+	Value     float64   `json:"value,omitempty"`
+}
+
+// DataQualityReport lists gaps, filled bars and suspicious bars in a series
+type DataQualityReport struct {
+	Symbol     string     `json:"symbol"`
+	GapPolicy  GapPolicy  `json:"gap_policy,omitempty"`
+	TotalBars  int        `json:"total_bars"`
+	Gaps       []BarIssue `json:"gaps"`
+	Fills      []BarIssue `json:"fills"`
+	Suspicious []BarIssue `json:"suspicious"`
+}
+
+// IsClean returns true when no gaps, fills or suspicious bars were found
+func (r *DataQualityReport) IsClean() bool {
+	return len(r.Gaps) == 0 && len(r.Fills) == 0 && len(r.Suspicious) == 0
+}
+
+// ApplyGapPolicy resolves bars whose close is missing. missing[i] marks
+// bars[i] as having no close. Filled bars are flat at the filled close and
+// flagged with Filled; bars that cannot be filled (leading gaps) are dropped.
+// Other missing fields on present bars fall back to the close.
+func ApplyGapPolicy(bars []StockData, missing []bool, policy GapPolicy) []StockData {
+	result := make([]StockData, 0, len(bars))
+	lastValid := -1
+
+	for i, bar := range bars {
+		if !missing[i] {
+			if bar.Open <= 0 {
+				bar.Open = bar.Close
+			}
+			if bar.High <= 0 {
+				bar.High = math.Max(bar.Open, bar.Close)
+			}
+			if bar.Low <= 0 {
+				bar.Low = math.Min(bar.Open, bar.Close)
+			}
+			result = append(result, bar)
+			lastValid = i
+			continue
+		}
+
+		if policy == GapPolicyDrop || lastValid < 0 {
+			continue
+		}
+
+		previous := bars[lastValid]
+		fill := previous.Close
+		adjFill := previous.AdjClose
+
+		if policy == GapPolicyInterpolate {
+			if next := nextPresent(missing, i); next >= 0 {
+				weight := float64(i-lastValid) / float64(next-lastValid)
+				fill = previous.Close + weight*(bars[next].Close-previous.Close)
+				if previous.AdjClose > 0 && bars[next].AdjClose > 0 {
+					adjFill = previous.AdjClose + weight*(bars[next].AdjClose-previous.AdjClose)
+				}
+			}
+		}
+
+		bar.Open, bar.High, bar.Low, bar.Close = fill, fill, fill, fill
+		bar.AdjClose = adjFill
+		bar.Volume = 0
+		bar.Filled = true
+		result = append(result, bar)
+	}
+
+	return result
+}
+
+// nextPresent returns the index of the next bar with a close, or -1
+func nextPresent(missing []bool, from int) int {
+	for j := from + 1; j < len(missing); j++ {
+		if !missing[j] {
+			return j
+		}
+	}
+	return -1
+}
+
+// AssessDataQuality reports filled bars, missing sessions and suspicious
+// bars in a series. A close-to-close move larger than spikeThreshold (as a
+// fraction, e.g. 0.25) is flagged as a possible spike; zero disables it.
+func AssessDataQuality(symbol string, bars []StockData, spikeThreshold float64) *DataQualityReport {
+	report := &DataQualityReport{
+		Symbol:     symbol,
+		TotalBars:  len(bars),
+		Gaps:       []BarIssue{},
+		Fills:      []BarIssue{},
+		Suspicious: []BarIssue{},
+	}
+	isTradingDay := tradingDayFunc(symbol)
+
+	for i, bar := range bars {
+		if bar.Filled {
+			report.Fills = append(report.Fills, BarIssue{
+				Timestamp: bar.Timestamp,
+				Reason:    "missing close filled",
+				Value:     bar.Close,
+			})
+		}
+
+		if bar.High > 0 && bar.Low > 0 && (bar.High < bar.Low || bar.Close > bar.High*1.001 || bar.Close < bar.Low*0.999) {
+			report.Suspicious = append(report.Suspicious, BarIssue{
+				Timestamp: bar.Timestamp,
+				Reason:    "close outside high/low range",
+				Value:     bar.Close,
+			})
+		}
+
+		if i == 0 {
+			continue
+		}
+		previous := bars[i-1]
+
+		if gap := missingSessions(previous, bar, isTradingDay); gap != "" {
+			report.Gaps = append(report.Gaps, BarIssue{
+				Timestamp: previous.Timestamp,
+				Reason:    gap,
+			})
+		}
+
+		if spikeThreshold > 0 && previous.Close > 0 && !bar.Filled {
+			change := bar.Close/previous.Close - 1
+			if math.Abs(change) > spikeThreshold {
+				report.Suspicious = append(report.Suspicious, BarIssue{
+					Timestamp: bar.Timestamp,
+					Reason:    fmt.Sprintf("close moved %.1f%% from previous bar", change*100),
+					Value:     bar.Close,
+				})
+			}
+		}
+	}
+
+	return report
+}
+
+// missingSessions describes bars missing between two consecutive bars, or
+// returns an empty string when they are adjacent on the symbol's calendar
+func missingSessions(previous, current StockData, isTradingDay func(time.Time) bool) string {
+	if current.Interval.IsIntraday() {
+		// Only gaps within a session count; overnight breaks are expected
+		if previous.Timestamp.UTC().Format("2006-01-02") != current.Timestamp.UTC().Format("2006-01-02") {
+			return ""
+		}
+		step := current.Interval.Duration()
+		elapsed := current.Timestamp.Sub(previous.Timestamp)
+		if elapsed <= step*3/2 {
+			return ""
+		}
+		return fmt.Sprintf("%d bar(s) missing before %s", int(elapsed/step)-1, current.Timestamp.UTC().Format(time.RFC3339))
+	}
+
+	missing := 0
+	for day := previous.Timestamp.UTC().AddDate(0, 0, 1); day.Format("2006-01-02") < current.Timestamp.UTC().Format("2006-01-02"); day = day.AddDate(0, 0, 1) {
+		if isTradingDay(day) {
+			missing++
+		}
+	}
+	if missing == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d trading day(s) missing before %s", missing, current.Timestamp.UTC().Format("2006-01-02"))
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func qualityBars(closes ...float64) ([]StockData, []bool) {
+	// Monday 2024-06-03 onwards, one bar per trading day
+	start := time.Date(2024, 6, 3, 13, 30, 0, 0, time.UTC)
+	bars := make([]StockData, len(closes))
+	missing := make([]bool, len(closes))
+	day := start
+	for i, c := range closes {
+		for !IsUSTradingDay(day) {
+			day = day.AddDate(0, 0, 1)
+		}
+		bars[i] = StockData{Symbol: "NVDA", Timestamp: day, Open: c, High: c, Low: c, Close: c, Volume: 100, Interval: Interval1d}
+		missing[i] = c == 0
+		day = day.AddDate(0, 0, 1)
+	}
+	return bars, missing
+}
+
+func TestApplyGapPolicy(t *testing.T) {
+	tests := []struct {
+		policy GapPolicy
+		want   []float64
+	}{
+		{GapPolicyDrop, []float64{100, 106}},
+		{GapPolicyForwardFill, []float64{100, 100, 100, 106}},
+		{GapPolicyInterpolate, []float64{100, 102, 104, 106}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			bars, missing := qualityBars(0, 100, 0, 0, 106)
+			cleaned := ApplyGapPolicy(bars, missing, tt.policy)
+			assert.Equal(t, tt.want, ClosePrices(cleaned, false))
+			assert.NoError(t, ValidateStockData(ClosePrices(cleaned, false), 1))
+		})
+	}
+}
+
+func TestParseGapPolicy(t *testing.T) {
+	policy, err := ParseGapPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, GapPolicyForwardFill, policy)
+
+	_, err = ParseGapPolicy("zero")
+	assert.Error(t, err)
+}
+
+func TestAssessDataQuality(t *testing.T) {
+	bars, missing := qualityBars(100, 0, 101, 150, 102, 103)
+	cleaned := ApplyGapPolicy(bars, missing, GapPolicyForwardFill)
+
+	// Drop a session to leave a hole in the calendar
+	cleaned = append(cleaned[:4], cleaned[5:]...)
+
+	report := AssessDataQuality("NVDA", cleaned, 0.25)
+	assert.Equal(t, 5, report.TotalBars)
+	assert.Len(t, report.Fills, 1)
+	assert.Len(t, report.Gaps, 1)
+	assert.Contains(t, report.Gaps[0].Reason, "1 trading day(s) missing")
+
+	// The jump to 150 and the fall back both exceed 25%
+	assert.Len(t, report.Suspicious, 2)
+	assert.False(t, report.IsClean())
+
+	clean := AssessDataQuality("NVDA", cleaned[:2], 0.25)
+	assert.Len(t, clean.Gaps, 0)
+	assert.Len(t, clean.Suspicious, 0)
+}
@@ -0,0 +1,126 @@
+package models
+
+import "math"
+
+// How an ensemble's member weights were chosen
+const (
+	EnsembleWeightingInverseMAPE = "inverse_mape" // From each member's recent tracked error
+	EnsembleWeightingEqual       = "equal"        // Not enough tracked predictions yet
+)
+
+// MinEnsembleMAPE floors a member's rolling MAPE, in percent, so a short
+// lucky streak cannot take over the blend
+const MinEnsembleMAPE = 0.1
+
+// EnsembleDisagreementScale is the member disagreement, in percent, at
+// which an ensemble's confidence is halved
+const EnsembleDisagreementScale = 2.0
+
+// EnsembleMember is one model's part in an ensemble prediction
+type EnsembleMember struct {
+	Model          string   `json:"model"`
+	ModelVersion   string   `json:"model_version,omitempty"`
+	ModelVersionID int64    `json:"model_version_id,omitempty"` // Model registry id, when registered
+	PredictedPrice float64  `json:"predicted_price,omitempty"`
+	Weight         float64  `json:"weight"`                 // Share of the blend; 0 when the model failed
+	RollingMAPE    *float64 `json:"rolling_mape,omitempty"` // Over its recent scored predictions for the symbol
+	Samples        int      `json:"samples"`                // Scored predictions behind RollingMAPE
+	Error          string   `json:"error,omitempty"`
+}
+
+// EnsembleResult describes how an ensemble prediction was blended
+type EnsembleResult struct {
+	Members      []EnsembleMember `json:"members"`
+	Weighting    string           `json:"weighting"`    // inverse_mape or equal
+	Disagreement float64          `json:"disagreement"` // Weighted standard deviation of member predictions, as a percentage of the blend
+}
+
+// ModelAccuracy is a model's rolling error on one symbol
+type ModelAccuracy struct {
+	Model   string  `json:"model"`
+	MAPE    float64 `json:"mape"`
+	Samples int     `json:"samples"`
+}
+
+// EnsembleWeights weights members by the inverse of their rolling MAPE.
+// Members with fewer than minSamples scored predictions get the average
+// weight of those with enough; when none have enough, weights are equal.
+// The weights sum to 1.
+func EnsembleWeights(members []string, accuracy map[string]ModelAccuracy, minSamples int) ([]float64, string) {
+	weights := make([]float64, len(members))
+	if len(members) == 0 {
+		return weights, EnsembleWeightingEqual
+	}
+
+	var total float64
+	var tracked int
+	for i, member := range members {
+		if a, ok := accuracy[member]; ok && a.Samples >= minSamples && a.Samples > 0 {
+			weights[i] = 1 / math.Max(a.MAPE, MinEnsembleMAPE)
+			total += weights[i]
+			tracked++
+		}
+	}
+
+	if tracked == 0 {
+		for i := range weights {
+			weights[i] = 1 / float64(len(members))
+		}
+		return weights, EnsembleWeightingEqual
+	}
+
+	average := total / float64(tracked)
+	for i := range weights {
+		if weights[i] == 0 {
+			weights[i] = average
+			total += average
+		}
+	}
+	for i := range weights {
+		weights[i] /= total
+	}
+	return weights, EnsembleWeightingInverseMAPE
+}
+
+// BlendEnsemble renormalizes the weights of the members that produced a
+// prediction and returns their weighted prediction and disagreement.
+// Members that failed keep a weight of 0.
+func BlendEnsemble(members []EnsembleMember) (price, disagreement float64) {
+	var total float64
+	for _, member := range members {
+		if member.Error == "" {
+			total += member.Weight
+		}
+	}
+	if total <= 0 {
+		return 0, 0
+	}
+
+	for i := range members {
+		if members[i].Error != "" {
+			members[i].Weight = 0
+			continue
+		}
+		members[i].Weight /= total
+		price += members[i].Weight * members[i].PredictedPrice
+	}
+	if price <= 0 {
+		return price, 0
+	}
+
+	var variance float64
+	for _, member := range members {
+		deviation := member.PredictedPrice - price
+		variance += member.Weight * deviation * deviation
+	}
+	return price, math.Sqrt(variance) / price * 100
+}
+
+// ApplyDisagreement lowers confidence as ensemble members disagree,
+// halving it at EnsembleDisagreementScale percent
+func ApplyDisagreement(confidence, disagreement float64) float64 {
+	if disagreement <= 0 {
+		return confidence
+	}
+	return math.Max(0.05, confidence/(1+disagreement/EnsembleDisagreementScale))
+}
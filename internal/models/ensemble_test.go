@@ -0,0 +1,56 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnsembleWeights(t *testing.T) {
+	members := []string{"simple", "enhanced", "advanced"}
+
+	weights, weighting := EnsembleWeights(members, nil, 10)
+	assert.Equal(t, EnsembleWeightingEqual, weighting)
+	assert.InDeltaSlice(t, []float64{1.0 / 3, 1.0 / 3, 1.0 / 3}, weights, 1e-12)
+
+	// Inverse MAPE; advanced has too few scored predictions and gets the
+	// average weight of the others
+	weights, weighting = EnsembleWeights(members, map[string]ModelAccuracy{
+		"simple":   {Model: "simple", MAPE: 1, Samples: 20},
+		"enhanced": {Model: "enhanced", MAPE: 3, Samples: 20},
+		"advanced": {Model: "advanced", MAPE: 0.5, Samples: 4},
+	}, 10)
+	assert.Equal(t, EnsembleWeightingInverseMAPE, weighting)
+	assert.InDeltaSlice(t, []float64{0.5, 1.0 / 6, 1.0 / 3}, weights, 1e-12)
+
+	// A perfect record is floored rather than dividing by zero
+	weights, _ = EnsembleWeights(members[:2], map[string]ModelAccuracy{
+		"simple":   {MAPE: 0, Samples: 20},
+		"enhanced": {MAPE: MinEnsembleMAPE, Samples: 20},
+	}, 10)
+	assert.InDeltaSlice(t, []float64{0.5, 0.5}, weights, 1e-12)
+}
+
+func TestBlendEnsemble(t *testing.T) {
+	members := []EnsembleMember{
+		{Model: "simple", PredictedPrice: 100, Weight: 0.5},
+		{Model: "enhanced", PredictedPrice: 104, Weight: 0.25},
+		{Model: "advanced", Weight: 0.25, Error: "worker timeout"},
+	}
+	price, disagreement := BlendEnsemble(members)
+
+	// The failed member's weight is shared out: 2/3 and 1/3
+	assert.InDelta(t, 0, members[2].Weight, 1e-12)
+	assert.InDelta(t, 2.0/3, members[0].Weight, 1e-12)
+	assert.InDelta(t, 304.0/3, price, 1e-9)
+	// Weighted standard deviation of 100 and 104 at 2:1 is sqrt(32/9)
+	assert.InDelta(t, 1.885618083/(304.0/3)*100, disagreement, 1e-6)
+
+	price, disagreement = BlendEnsemble([]EnsembleMember{{Model: "simple", PredictedPrice: 100, Weight: 1}})
+	assert.Equal(t, 100.0, price)
+	assert.Equal(t, 0.0, disagreement)
+
+	assert.Equal(t, 0.8, ApplyDisagreement(0.8, 0))
+	assert.InDelta(t, 0.4, ApplyDisagreement(0.8, EnsembleDisagreementScale), 1e-12)
+	assert.Equal(t, 0.05, ApplyDisagreement(0.2, 100))
+}
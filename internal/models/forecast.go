@@ -0,0 +1,75 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxForecastHorizon is the furthest forecast horizon, in trading days
+const MaxForecastHorizon = 20
+
+// DefaultForecastHorizons are the horizons tracked by the daily run: the
+// next session, next week and next month
+var DefaultForecastHorizons = []int{1, 5, 20}
+
+// ForecastPoint is one point of a forecast path
+type ForecastPoint struct {
+	HorizonDays    int                  `json:"horizon_days"` // Trading days after the last bar
+	TargetDate     time.Time            `json:"target_date"`  // Session whose close is forecast
+	PredictedPrice float64              `json:"predicted_price"`
+	ChangePercent  float64              `json:"change_percent"` // From the current price
+	TradingSignal  string               `json:"trading_signal"`
+	Confidence     float64              `json:"confidence"` // Falls with the horizon; see HorizonConfidence
+	Intervals      []PredictionInterval `json:"intervals,omitempty"`
+}
+
+// HorizonConfidence scales a next-session confidence to a forecast horizon.
+// Forecast errors grow roughly with the square root of the horizon, as the
+// prediction intervals do, so confidence falls by the same factor.
+func HorizonConfidence(confidence float64, horizon int) float64 {
+	if horizon <= 1 {
+		return confidence
+	}
+	return confidence / math.Sqrt(float64(horizon))
+}
+
+// ParseHorizons parses a comma-separated list of horizons in trading days,
+// e.g. "1,5,20". The result is sorted and free of duplicates.
+func ParseHorizons(raw string) ([]int, error) {
+	seen := make(map[int]bool)
+	var horizons []int
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		days, err := strconv.Atoi(part)
+		if err != nil || days < 1 || days > MaxForecastHorizon {
+			return nil, fmt.Errorf("invalid horizon: %s (must be 1-%d trading days)", part, MaxForecastHorizon)
+		}
+		if !seen[days] {
+			seen[days] = true
+			horizons = append(horizons, days)
+		}
+	}
+	if len(horizons) == 0 {
+		return nil, fmt.Errorf("no horizons given (e.g. horizons=1,5,20)")
+	}
+	sort.Ints(horizons)
+	return horizons, nil
+}
+
+// MaxHorizon returns the furthest of horizons, or 0 when there are none
+func MaxHorizon(horizons []int) int {
+	furthest := 0
+	for _, days := range horizons {
+		if days > furthest {
+			furthest = days
+		}
+	}
+	return furthest
+}
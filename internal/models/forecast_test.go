@@ -0,0 +1,27 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHorizons(t *testing.T) {
+	horizons, err := ParseHorizons("20, 1,5,5")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 5, 20}, horizons)
+	assert.Equal(t, 20, MaxHorizon(horizons))
+
+	for _, raw := range []string{"", ",", "0", "21", "1,week", "-5"} {
+		_, err := ParseHorizons(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestHorizonConfidence(t *testing.T) {
+	assert.Equal(t, 0.8, HorizonConfidence(0.8, 1))
+	assert.Equal(t, 0.8, HorizonConfidence(0.8, 0))
+	assert.InDelta(t, 0.4, HorizonConfidence(0.8, 4), 1e-12)
+	assert.Less(t, HorizonConfidence(0.8, 20), HorizonConfidence(0.8, 5))
+}
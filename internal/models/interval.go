@@ -0,0 +1,132 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Interval represents the bar size of a price series
+type Interval string
+
+const (
+	Interval1m  Interval = "1m"
+	Interval5m  Interval = "5m"
+	Interval15m Interval = "15m"
+	Interval30m Interval = "30m"
+	Interval1h  Interval = "1h"
+	Interval1d  Interval = "1d"
+)
+
+// regularSessionMinutes is the length of the US regular trading session
+const regularSessionMinutes = 390
+
+// ParseInterval parses an interval string, defaulting to daily bars
+func ParseInterval(s string) (Interval, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "1d":
+		return Interval1d, nil
+	case "1m":
+		return Interval1m, nil
+	case "5m":
+		return Interval5m, nil
+	case "15m":
+		return Interval15m, nil
+	case "30m":
+		return Interval30m, nil
+	case "1h", "60m":
+		return Interval1h, nil
+	default:
+		return "", fmt.Errorf("unsupported interval: %s (valid options: 1m, 5m, 15m, 30m, 1h, 1d)", s)
+	}
+}
+
+// String returns string representation of the interval
+func (i Interval) String() string {
+	return string(i)
+}
+
+// Duration returns the length of one bar
+func (i Interval) Duration() time.Duration {
+	switch i {
+	case Interval1m:
+		return time.Minute
+	case Interval5m:
+		return 5 * time.Minute
+	case Interval15m:
+		return 15 * time.Minute
+	case Interval30m:
+		return 30 * time.Minute
+	case Interval1h:
+		return time.Hour
+	default:
+		return 24 * time.Hour
+	}
+}
+
+// IsIntraday returns true for bars shorter than one trading session
+func (i Interval) IsIntraday() bool {
+	return i != Interval1d && i != ""
+}
+
+// BarsPerSession returns the approximate number of bars in one regular session
+func (i Interval) BarsPerSession() int {
+	if !i.IsIntraday() {
+		return 1
+	}
+	bars := regularSessionMinutes / int(i.Duration().Minutes())
+	if regularSessionMinutes%int(i.Duration().Minutes()) != 0 {
+		bars++
+	}
+	return bars
+}
+
+// MaxLookbackDays returns the longest calendar range the data vendor serves
+// for this interval, or 0 when unlimited
+func (i Interval) MaxLookbackDays() int {
+	switch i {
+	case Interval1m:
+		return 7
+	case Interval5m, Interval15m, Interval30m:
+		return 60
+	case Interval1h:
+		return 730
+	default:
+		return 0
+	}
+}
+
+// RangeForTradingDays maps a number of trading sessions to a Yahoo-style
+// range string large enough to cover them
+func (i Interval) RangeForTradingDays(days int) string {
+	if !i.IsIntraday() {
+		switch {
+		case days <= 7:
+			return "7d"
+		case days <= 30:
+			return "1mo"
+		case days <= 90:
+			return "3mo"
+		case days <= 180:
+			return "6mo"
+		case days <= 365:
+			return "1y"
+		default:
+			return "2y"
+		}
+	}
+
+	// Allow for weekends and holidays between sessions
+	calendarDays := days*7/5 + 3
+	if maxDays := i.MaxLookbackDays(); calendarDays > maxDays {
+		calendarDays = maxDays
+	}
+	return fmt.Sprintf("%dd", calendarDays)
+}
+
+// RangeForBars maps a number of bars to a range string large enough to cover them
+func (i Interval) RangeForBars(bars int) string {
+	perSession := i.BarsPerSession()
+	sessions := (bars + perSession - 1) / perSession
+	return i.RangeForTradingDays(sessions)
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Interval
+		wantErr bool
+	}{
+		{"", Interval1d, false},
+		{"1d", Interval1d, false},
+		{"5m", Interval5m, false},
+		{"60m", Interval1h, false},
+		{"1H", Interval1h, false},
+		{"2h", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseInterval(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIntervalRanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval Interval
+		bars     int
+		expected string
+	}{
+		{"Daily short lookback", Interval1d, 5, "7d"},
+		{"Daily one year", Interval1d, 300, "1y"},
+		{"Hourly one session", Interval1h, 7, "4d"},
+		{"Hourly two sessions", Interval1h, 8, "5d"},
+		{"One minute capped", Interval1m, 10000, "7d"},
+		{"Five minute", Interval5m, 78, "4d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.interval.RangeForBars(tt.bars))
+		})
+	}
+
+	assert.Equal(t, 7, Interval1h.BarsPerSession())
+	assert.Equal(t, 78, Interval5m.BarsPerSession())
+	assert.False(t, Interval1d.IsIntraday())
+	assert.True(t, Interval15m.IsIntraday())
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"time"
+)
+
+// USMarketHolidays returns a map of US market holidays for a given year,
+// keyed by the observed date
+func USMarketHolidays(year int) map[time.Time]string {
+	holidays := make(map[time.Time]string)
+	
+	// Fixed date holidays
+	holidays[time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)] = "New Year's Day"
+	holidays[time.Date(year, 7, 4, 0, 0, 0, 0, time.UTC)] = "Independence Day"
+	holidays[time.Date(year, 12, 25, 0, 0, 0, 0, time.UTC)] = "Christmas Day"
+	
+	// Juneteenth (June 19th, federal holiday since 2021)
+	if year >= 2021 {
+		holidays[time.Date(year, 6, 19, 0, 0, 0, 0, time.UTC)] = "Juneteenth"
+	}
+	
+	// Variable date holidays
+	holidays[nthWeekdayOfMonth(year, 1, time.Monday, 3)] = "Martin Luther King Jr. Day"
+	holidays[nthWeekdayOfMonth(year, 2, time.Monday, 3)] = "Presidents' Day"
+	holidays[lastWeekdayOfMonth(year, 5, time.Monday)] = "Memorial Day"
+	holidays[nthWeekdayOfMonth(year, 9, time.Monday, 1)] = "Labor Day"
+	holidays[nthWeekdayOfMonth(year, 11, time.Thursday, 4)] = "Thanksgiving Day"
+	
+	// Good Friday (Friday before Easter)
+	easter := easterDate(year)
+	goodFriday := easter.AddDate(0, 0, -2)
+	holidays[goodFriday] = "Good Friday"
+	
+	// Handle holidays that fall on weekends (observed on Friday or Monday)
+	adjustedHolidays := make(map[time.Time]string)
+	for date, name := range holidays {
+		adjustedDate := adjustHolidayForWeekend(date)
+		adjustedHolidays[adjustedDate] = name
+	}
+	
+	return adjustedHolidays
+}
+
+// IsUSTradingDay returns true for weekdays that are not US market holidays
+func IsUSTradingDay(date time.Time) bool {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		return false
+	}
+	_, holiday := USMarketHolidays(day.Year())[day]
+	return !holiday
+}
+
+// nthWeekdayOfMonth returns the nth occurrence of a weekday in a month
+func nthWeekdayOfMonth(year int, month time.Month, weekday time.Weekday, n int) time.Time {
+	firstDay := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	
+	// Find the first occurrence of the weekday
+	daysUntilWeekday := int(weekday - firstDay.Weekday())
+	if daysUntilWeekday < 0 {
+		daysUntilWeekday += 7
+	}
+	
+	firstOccurrence := firstDay.AddDate(0, 0, daysUntilWeekday)
+	return firstOccurrence.AddDate(0, 0, (n-1)*7)
+}
+
+// lastWeekdayOfMonth returns the last occurrence of a weekday in a month
+func lastWeekdayOfMonth(year int, month time.Month, weekday time.Weekday) time.Time {
+	// Start from the last day of the month and work backwards
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC) // Last day of the month
+	
+	daysBack := int(lastDay.Weekday() - weekday)
+	if daysBack < 0 {
+		daysBack += 7
+	}
+	
+	return lastDay.AddDate(0, 0, -daysBack)
+}
+
+// easterDate calculates Easter date for a given year using the algorithm
+func easterDate(year int) time.Time {
+	// Using the algorithm for Western Easter
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := ((h + l - 7*m + 114) % 31) + 1
+	
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// adjustHolidayForWeekend adjusts holiday dates that fall on weekends
+func adjustHolidayForWeekend(date time.Time) time.Time {
+	switch date.Weekday() {
+	case time.Saturday:
+		// Observed on Friday
+		return date.AddDate(0, 0, -1)
+	case time.Sunday:
+		// Observed on Monday
+		return date.AddDate(0, 0, 1)
+	default:
+		return date
+	}
+}
@@ -0,0 +1,113 @@
+package models
+
+import "time"
+
+// ModelInputVersion is the version of the structured model input format.
+// It is bumped when fields change meaning, not when fields are added.
+const ModelInputVersion = 1
+
+// ModelInput is the structured input sent to models that need more than a
+// series of closes. Prices are sent at full precision.
+type ModelInput struct {
+	Version  int         `json:"version"`
+	Symbol   ModelSymbol `json:"symbol"`
+	Interval Interval    `json:"interval"`
+	Adjusted bool        `json:"adjusted"` // OHLC are scaled onto the adjusted close basis
+	Bars     []ModelBar  `json:"bars"`     // Oldest first
+}
+
+// ModelSymbol is the instrument metadata in a model input
+type ModelSymbol struct {
+	Ticker     string `json:"ticker"`
+	Base       string `json:"base"`
+	AssetClass string `json:"asset_class"`
+	Exchange   string `json:"exchange"`
+	Currency   string `json:"currency"`
+}
+
+// ModelBar is one OHLCV bar in a model input
+type ModelBar struct {
+	Timestamp time.Time `json:"timestamp"`
+	Open      float64   `json:"open"`
+	High      float64   `json:"high"`
+	Low       float64   `json:"low"`
+	Close     float64   `json:"close"`
+	AdjClose  float64   `json:"adj_close,omitempty"` // split and dividend adjusted close
+	Volume    int64     `json:"volume"`
+	Filled    bool      `json:"filled,omitempty"` // synthesised by the gap policy
+}
+
+// NewModelInput builds a model input from the bars a prediction is made on.
+// When adjusted is set the bars must already be on the adjusted basis, as
+// returned by AdjustedBars, so that Close matches the predicted series.
+func NewModelInput(symbol Symbol, interval Interval, adjusted bool, bars []StockData) *ModelInput {
+	input := &ModelInput{
+		Version: ModelInputVersion,
+		Symbol: ModelSymbol{
+			Ticker:     symbol.Ticker,
+			Base:       symbol.Base,
+			AssetClass: string(symbol.AssetClass),
+			Exchange:   symbol.Exchange.Code,
+			Currency:   symbol.Currency(),
+		},
+		Interval: interval,
+		Adjusted: adjusted,
+		Bars:     make([]ModelBar, len(bars)),
+	}
+	for i, bar := range bars {
+		input.Bars[i] = ModelBar{
+			Timestamp: bar.Timestamp,
+			Open:      bar.Open,
+			High:      bar.High,
+			Low:       bar.Low,
+			Close:     bar.Close,
+			AdjClose:  bar.AdjClose,
+			Volume:    bar.Volume,
+			Filled:    bar.Filled,
+		}
+	}
+	if n := len(bars); n > 0 && bars[n-1].Currency != "" {
+		input.Symbol.Currency = bars[n-1].Currency
+	}
+	return input
+}
+
+// Closes returns the close of every bar
+func (in *ModelInput) Closes() []float64 {
+	closes := make([]float64, len(in.Bars))
+	for i, bar := range in.Bars {
+		closes[i] = bar.Close
+	}
+	return closes
+}
+
+// Tail returns the input with only its last n bars
+func (in *ModelInput) Tail(n int) *ModelInput {
+	if n >= len(in.Bars) {
+		return in
+	}
+	tail := *in
+	tail.Bars = in.Bars[len(in.Bars)-n:]
+	return &tail
+}
+
+// Append returns the input with a flat bar at price added for a forecast
+// step, marked as filled since no market data backs it. The oldest bar is
+// dropped so the window keeps its length.
+func (in *ModelInput) Append(timestamp time.Time, price float64) *ModelInput {
+	next := *in
+	next.Bars = make([]ModelBar, 0, len(in.Bars))
+	if len(in.Bars) > 0 {
+		next.Bars = append(next.Bars, in.Bars[1:]...)
+	}
+	next.Bars = append(next.Bars, ModelBar{
+		Timestamp: timestamp,
+		Open:      price,
+		High:      price,
+		Low:       price,
+		Close:     price,
+		AdjClose:  price,
+		Filled:    true,
+	})
+	return &next
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewModelInput(t *testing.T) {
+	symbol, err := ParseSymbol("vod.l")
+	require.NoError(t, err)
+
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	bars := []StockData{
+		{Timestamp: day, Open: 0.4512, High: 0.4637, Low: 0.4499, Close: 0.4581, AdjClose: 0.4423, Volume: 1200},
+		{Timestamp: day.AddDate(0, 0, 1), Open: 0.4581, High: 0.4702, Low: 0.4566, Close: 0.4695, AdjClose: 0.4533, Volume: 900, Currency: "GBp"},
+	}
+
+	input := NewModelInput(symbol, Interval1d, true, AdjustedBars(bars))
+	assert.Equal(t, ModelInputVersion, input.Version)
+	assert.Equal(t, "VOD.L", input.Symbol.Ticker)
+	assert.Equal(t, "GBp", input.Symbol.Currency)
+	assert.True(t, input.Adjusted)
+	assert.Equal(t, ClosePrices(bars, true), input.Closes())
+	assert.Equal(t, 0.4533, input.Bars[1].AdjClose)
+
+	// Sub-dollar prices survive the JSON round trip unrounded
+	data, err := json.Marshal(input)
+	require.NoError(t, err)
+	var decoded ModelInput
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, input.Bars, decoded.Bars)
+	assert.Equal(t, 0.4637*0.4423/0.4581, decoded.Bars[0].High)
+
+	tail := input.Tail(1)
+	assert.Len(t, tail.Bars, 1)
+	assert.Equal(t, day.AddDate(0, 0, 1), tail.Bars[0].Timestamp)
+	assert.Len(t, input.Bars, 2)
+	assert.Same(t, input, input.Tail(5))
+}
+
+func TestModelInputAppend(t *testing.T) {
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	input := &ModelInput{Version: ModelInputVersion, Bars: []ModelBar{
+		{Timestamp: day, Close: 10},
+		{Timestamp: day.AddDate(0, 0, 1), Close: 11},
+	}}
+
+	next := input.Append(day.AddDate(0, 0, 2), 12)
+	assert.Equal(t, []float64{11, 12}, next.Closes())
+	assert.True(t, next.Bars[1].Filled)
+	assert.Equal(t, 12.0, next.Bars[1].High)
+	assert.Equal(t, []float64{10, 11}, input.Closes(), "original input is unchanged")
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ModelOutputVersion is the version of the JSON model output format.
+// Version 0 marks legacy output: a bare predicted price.
+const ModelOutputVersion = 1
+
+// ModelOutput is a model's typed result. Scripts print it as one JSON line;
+// only PredictedPrice is required.
+type ModelOutput struct {
+	Version        int                `json:"version"`
+	PredictedPrice float64            `json:"predicted_price"`
+	Quantiles      []Quantile         `json:"quantiles,omitempty"`
+	ModelVersion   string             `json:"model_version,omitempty"` // version reported by the model itself
+	Features       map[string]float64 `json:"features,omitempty"`
+	Warnings       []string           `json:"warnings,omitempty"`
+	TimingMs       map[string]float64 `json:"timing_ms,omitempty"` // stage durations reported by the model
+	Ensemble       *EnsembleResult    `json:"ensemble,omitempty"`  // members blended by the ensemble model
+}
+
+// Quantile is one point of a model's forecast distribution
+type Quantile struct {
+	Level float64 `json:"level"` // e.g. 0.1 for the 10th percentile
+	Price float64 `json:"price"`
+}
+
+// QuantileAt returns the price at a quantile level, if the model reported it
+func (o *ModelOutput) QuantileAt(level float64) (float64, bool) {
+	for _, q := range o.Quantiles {
+		if math.Abs(q.Level-level) < 1e-9 {
+			return q.Price, true
+		}
+	}
+	return 0, false
+}
+
+// ParseModelOutput reads a model's stdout. It takes the last line holding a
+// JSON object with a version, ignoring anything printed before it, and falls
+// back to reading the last non-empty line as a bare price from legacy
+// scripts.
+func ParseModelOutput(stdout string) (*ModelOutput, error) {
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var probe struct {
+			Version *int `json:"version"`
+		}
+		if json.Unmarshal([]byte(line), &probe) != nil || probe.Version == nil {
+			continue
+		}
+		var output ModelOutput
+		if err := json.Unmarshal([]byte(line), &output); err != nil {
+			return nil, fmt.Errorf("invalid model output: %w", err)
+		}
+		if output.Version > ModelOutputVersion {
+			return nil, fmt.Errorf("unsupported model output version %d (supported up to %d)", output.Version, ModelOutputVersion)
+		}
+		return &output, nil
+	}
+
+	// Legacy scripts print only the predicted price, as the last line
+	last := strings.TrimSpace(lines[len(lines)-1])
+	if last == "" {
+		return nil, fmt.Errorf("empty prediction output")
+	}
+	price, err := strconv.ParseFloat(last, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prediction output '%s': %w", last, err)
+	}
+	return &ModelOutput{PredictedPrice: price}, nil
+}
+
+// PredictionDiagnostics describes how a prediction was made. It is
+// returned with ?debug=true.
+type PredictionDiagnostics struct {
+	Backend    string       `json:"backend"`              // script, http or native
+	Input      string       `json:"input"`                // closes or ohlcv
+	DataPoints int          `json:"data_points"`          // bars or closes sent to the model
+	DurationMs float64      `json:"duration_ms"`          // model call as measured by the service
+	Version    string       `json:"version"`              // of the model that ran
+	VersionID  int64        `json:"version_id,omitempty"` // model registry id, 0 for built-in versions
+	Artifact   string       `json:"artifact,omitempty"`   // registry artifact the model loaded
+	Output     *ModelOutput `json:"output"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseModelOutput(t *testing.T) {
+	stdout := "Loaded feature scaler\n" +
+		`{"version": 1, "predicted_price": 0.013384535892061431, "model_version": "simple-1.0.0",` +
+		` "quantiles": [{"level": 0.1, "price": 0.0130}, {"level": 0.9, "price": 0.0137}],` +
+		` "features": {"data_points": 3.0}, "warnings": ["only 3 prices"], "timing_ms": {"total": 0.08}}` + "\n"
+
+	output, err := ParseModelOutput(stdout)
+	require.NoError(t, err)
+	assert.Equal(t, 1, output.Version)
+	assert.Equal(t, 0.013384535892061431, output.PredictedPrice)
+	assert.Equal(t, "simple-1.0.0", output.ModelVersion)
+	assert.Equal(t, 3.0, output.Features["data_points"])
+	assert.Equal(t, []string{"only 3 prices"}, output.Warnings)
+	assert.Equal(t, 0.08, output.TimingMs["total"])
+
+	lower, ok := output.QuantileAt(0.1)
+	assert.True(t, ok)
+	assert.Equal(t, 0.0130, lower)
+	_, ok = output.QuantileAt(0.025)
+	assert.False(t, ok)
+
+	// Stray prints after the output line, including JSON without a version
+	output, err = ParseModelOutput(`{"version": 1, "predicted_price": 101.5}` + "\n{\"debug\": true}\nDone\n")
+	require.NoError(t, err)
+	assert.Equal(t, 101.5, output.PredictedPrice)
+}
+
+func TestParseModelOutputLegacy(t *testing.T) {
+	output, err := ParseModelOutput("Loading model\n105.62054653695546\n\n")
+	require.NoError(t, err)
+	assert.Equal(t, 0, output.Version)
+	assert.Equal(t, 105.62054653695546, output.PredictedPrice)
+
+	_, err = ParseModelOutput("")
+	assert.Error(t, err)
+	_, err = ParseModelOutput("Prediction done")
+	assert.Error(t, err)
+	_, err = ParseModelOutput(`{"version": 2, "predicted_price": 101.5}`)
+	assert.ErrorContains(t, err, "unsupported model output version")
+	_, err = ParseModelOutput(`{"version": 1, "predicted_price": "high"}`)
+	assert.ErrorContains(t, err, "invalid model output")
+}
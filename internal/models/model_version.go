@@ -0,0 +1,83 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Lifecycle of a registered model version. At most one version of a model
+// is in production at a time.
+const (
+	ModelStatusStaging    = "staging"    // Registered, not yet serving
+	ModelStatusProduction = "production" // Serving predictions
+	ModelStatusRetired    = "retired"    // Replaced by a later promotion or rolled back
+)
+
+// semverPattern matches a semantic version, https://semver.org
+var semverPattern = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// sha256Pattern matches a hex-encoded SHA-256 digest
+var sha256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ModelVersion is one registered version of a prediction model
+type ModelVersion struct {
+	ID            int64              `json:"id"`
+	Name          string             `json:"name"`    // Prediction model name, e.g. "enhanced"
+	Version       string             `json:"version"` // Semantic version, e.g. "1.4.0"
+	ArtifactPath  string             `json:"artifact_path,omitempty"`
+	SHA256        string             `json:"sha256,omitempty"` // Of the artifact when registered
+	TrainingStart *time.Time         `json:"training_start,omitempty"`
+	TrainingEnd   *time.Time         `json:"training_end,omitempty"`
+	Metrics       map[string]float64 `json:"metrics,omitempty"` // Offline evaluation, e.g. {"mape": 1.8}
+	Status        string             `json:"status"`
+	CreatedAt     time.Time          `json:"created_at"`
+	PromotedAt    *time.Time         `json:"promoted_at,omitempty"`    // Last promotion to production
+	RolledBackAt  *time.Time         `json:"rolled_back_at,omitempty"` // Last rollback out of production
+}
+
+// RegisterModelRequest registers a new model version in staging
+type RegisterModelRequest struct {
+	Name          string             `json:"name"`
+	Version       string             `json:"version"`
+	ArtifactPath  string             `json:"artifact_path"`
+	SHA256        string             `json:"sha256"` // Checked against the artifact when both are given
+	TrainingStart *time.Time         `json:"training_start"`
+	TrainingEnd   *time.Time         `json:"training_end"`
+	Metrics       map[string]float64 `json:"metrics"`
+}
+
+// ValidateSemver checks that version is a semantic version such as 1.4.0
+// or 2.0.0-rc.1
+func ValidateSemver(version string) error {
+	if !semverPattern.MatchString(version) {
+		return fmt.Errorf("invalid version: %q (must be a semantic version, e.g. 1.4.0)", version)
+	}
+	return nil
+}
+
+// Validate normalizes and validates the request
+func (r *RegisterModelRequest) Validate() error {
+	r.Name = strings.ToLower(strings.TrimSpace(r.Name))
+	r.Version = strings.TrimSpace(r.Version)
+	r.ArtifactPath = strings.TrimSpace(r.ArtifactPath)
+	r.SHA256 = strings.ToLower(strings.TrimSpace(r.SHA256))
+
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if err := ValidateSemver(r.Version); err != nil {
+		return err
+	}
+	if r.SHA256 != "" && !sha256Pattern.MatchString(r.SHA256) {
+		return fmt.Errorf("invalid sha256: must be 64 hex characters")
+	}
+	if r.ArtifactPath == "" {
+		return fmt.Errorf("artifact_path is required")
+	}
+	if r.TrainingStart != nil && r.TrainingEnd != nil && r.TrainingEnd.Before(*r.TrainingStart) {
+		return fmt.Errorf("training_end is before training_start")
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSemver(t *testing.T) {
+	for _, version := range []string{"1.4.0", "0.1.0", "2.0.0-rc.1", "1.0.0+build.7"} {
+		assert.NoError(t, ValidateSemver(version), version)
+	}
+	for _, version := range []string{"", "v1.0.0", "1.0", "01.0.0", "1.0.0-", "latest"} {
+		assert.Error(t, ValidateSemver(version), version)
+	}
+}
+
+func TestRegisterModelRequestValidate(t *testing.T) {
+	request := RegisterModelRequest{
+		Name:         " Enhanced ",
+		Version:      "1.4.0",
+		ArtifactPath: "/models/enhanced.pkl",
+		SHA256:       "13E35C44395F6CADE670C076230362267C5288989D3498E207877D62B859A6D8",
+	}
+	require.NoError(t, request.Validate())
+	assert.Equal(t, "enhanced", request.Name)
+	assert.Equal(t, "13e35c44395f6cade670c076230362267c5288989d3498e207877d62b859a6d8", request.SHA256)
+
+	start := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, -1)
+	for name, request := range map[string]RegisterModelRequest{
+		"missing name":     {Version: "1.0.0"},
+		"bad version":      {Name: "enhanced", Version: "1.0"},
+		"bad sha":          {Name: "enhanced", Version: "1.0.0", ArtifactPath: "/models/a", SHA256: "abc"},
+		"sha without path": {Name: "enhanced", Version: "1.0.0", SHA256: request.SHA256},
+		"inverted window":  {Name: "enhanced", Version: "1.0.0", TrainingStart: &start, TrainingEnd: &end},
+	} {
+		assert.Error(t, request.Validate(), name)
+	}
+}
@@ -12,6 +12,13 @@ const (
 	ModelSimple   PredictionModel = "simple"
 	ModelEnhanced PredictionModel = "enhanced"
 	ModelAdvanced PredictionModel = "advanced"
+	
+	// Pure-Go ports of the simple and enhanced models, run in-process
+	ModelSimpleGo   PredictionModel = "simple-go"
+	ModelEnhancedGo PredictionModel = "enhanced-go"
+	
+	// Accuracy-weighted blend of other models, with ML_ENABLE_ENSEMBLE
+	ModelEnsemble PredictionModel = "ensemble"
 )
 
 // PredictionConfig holds configuration for prediction models
@@ -30,7 +37,7 @@ func DefaultPredictionConfig() *PredictionConfig {
 	return &PredictionConfig{
 		Model:         ModelSimple,
 		ScriptPath:    "scripts/ml/predict.py",
-		UseOHLCVData:  false,
+		UseOHLCVData:  true,
 		MaxDataPoints: 30,
 		MinDataPoints: 5,
 		EnableEnsemble: false,
@@ -45,6 +52,8 @@ func (pc *PredictionConfig) GetScriptPath() string {
 	}
 	
 	switch pc.Model {
+	case ModelSimpleGo, ModelEnhancedGo:
+		return ""
 	case ModelEnhanced:
 		return "scripts/ml/enhanced_predict.py"
 	case ModelAdvanced:
@@ -60,18 +69,8 @@ func (pc *PredictionConfig) Validate() error {
 		return fmt.Errorf("prediction model cannot be empty")
 	}
 	
-	validModels := []PredictionModel{ModelSimple, ModelEnhanced, ModelAdvanced}
-	isValid := false
-	for _, model := range validModels {
-		if pc.Model == model {
-			isValid = true
-			break
-		}
-	}
-	
-	if !isValid {
-		return fmt.Errorf("invalid prediction model: %s, valid options: %v", pc.Model, validModels)
-	}
+	// Which names are valid depends on the configured models, so the
+	// prediction service checks the name against its registry
 	
 	if pc.MaxDataPoints < pc.MinDataPoints {
 		return fmt.Errorf("max_data_points (%d) cannot be less than min_data_points (%d)", 
@@ -99,6 +98,12 @@ func ParsePredictionModel(s string) (PredictionModel, error) {
 		return ModelEnhanced, nil
 	case "advanced":
 		return ModelAdvanced, nil
+	case "simple-go":
+		return ModelSimpleGo, nil
+	case "enhanced-go":
+		return ModelEnhancedGo, nil
+	case "ensemble":
+		return ModelEnsemble, nil
 	default:
 		return "", fmt.Errorf("unknown prediction model: %s", s)
 	}
@@ -113,6 +118,12 @@ func (pm PredictionModel) GetModelDescription() string {
 		return "Enhanced prediction with technical indicators (RSI, MACD, Bollinger Bands)"
 	case ModelAdvanced:
 		return "Advanced prediction using full OHLCV data with support/resistance analysis"
+	case ModelSimpleGo:
+		return "Simple linear regression with basic trend analysis, run in-process in Go"
+	case ModelEnhancedGo:
+		return "Enhanced prediction with technical indicators (RSI, MACD, Bollinger Bands), run in-process in Go"
+	case ModelEnsemble:
+		return "Blend of the simple, enhanced and advanced models weighted by their recent accuracy per symbol"
 	default:
 		return "Unknown model"
 	}
@@ -121,6 +132,10 @@ func (pm PredictionModel) GetModelDescription() string {
 // GetModelFeatures returns list of features for the prediction model
 func (pm PredictionModel) GetModelFeatures() []string {
 	switch pm {
+	case ModelSimpleGo:
+		return append(ModelSimple.GetModelFeatures(), "No Python runtime")
+	case ModelEnhancedGo:
+		return append(ModelEnhanced.GetModelFeatures(), "No Python runtime")
 	case ModelSimple:
 		return []string{
 			"Linear regression",
@@ -146,11 +161,33 @@ func (pm PredictionModel) GetModelFeatures() []string {
 			"Advanced technical indicators (ATR, Stochastic, OBV)",
 			"Dynamic bounds based on ATR",
 		}
+	case ModelEnsemble:
+		return []string{
+			"Members run concurrently",
+			"Per-symbol weights from inverse rolling MAPE",
+			"Member disagreement lowers confidence",
+		}
 	default:
 		return []string{}
 	}
 }
 
+// IsBuiltIn returns true for the models shipped with the service, as
+// opposed to ones added through configuration
+func (pm PredictionModel) IsBuiltIn() bool {
+	switch pm {
+	case ModelSimple, ModelEnhanced, ModelAdvanced, ModelSimpleGo, ModelEnhancedGo, ModelEnsemble:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsInProcess returns true if the model runs in Go without a Python worker
+func (pm PredictionModel) IsInProcess() bool {
+	return pm == ModelSimpleGo || pm == ModelEnhancedGo
+}
+
 // RequiresOHLCVData returns true if the model requires full OHLCV data
 func (pm PredictionModel) RequiresOHLCVData() bool {
 	return pm == ModelAdvanced
@@ -159,9 +196,9 @@ func (pm PredictionModel) RequiresOHLCVData() bool {
 // GetRecommendedDataPoints returns recommended number of data points for the model
 func (pm PredictionModel) GetRecommendedDataPoints() (min, max int) {
 	switch pm {
-	case ModelSimple:
+	case ModelSimple, ModelSimpleGo:
 		return 5, 15
-	case ModelEnhanced:
+	case ModelEnhanced, ModelEnhancedGo:
 		return 10, 25
 	case ModelAdvanced:
 		return 15, 40
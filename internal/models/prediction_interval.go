@@ -0,0 +1,73 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Sources of prediction interval bounds
+const (
+	IntervalSourceModel     = "model"     // The model's own quantiles
+	IntervalSourceResiduals = "residuals" // Errors of past scored predictions
+)
+
+// PredictionInterval is a band expected to contain the actual price with
+// probability Coverage
+type PredictionInterval struct {
+	Coverage float64 `json:"coverage"` // Nominal coverage, e.g. 0.8
+	Lower    float64 `json:"lower"`
+	Upper    float64 `json:"upper"`
+	Source   string  `json:"source"`
+	Covered  *bool   `json:"covered,omitempty"` // Whether the actual close fell inside, once tracked and scored
+}
+
+// ValidateCoverage checks that a coverage is a probability strictly between
+// 0 and 1
+func ValidateCoverage(coverage float64) error {
+	if !(coverage > 0 && coverage < 1) {
+		return fmt.Errorf("invalid interval coverage: %g (must be between 0 and 1)", coverage)
+	}
+	return nil
+}
+
+// CoverageLevels returns the quantile levels bounding a central interval,
+// e.g. 0.1 and 0.9 for 80% coverage
+func CoverageLevels(coverage float64) (lower, upper float64) {
+	tail := (1 - coverage) / 2
+	return tail, 1 - tail
+}
+
+// Contains reports whether price lies within the band
+func (i PredictionInterval) Contains(price float64) bool {
+	return price >= i.Lower && price <= i.Upper
+}
+
+// EmpiricalQuantile returns the level quantile of sorted values, linearly
+// interpolating between order statistics
+func EmpiricalQuantile(sorted []float64, level float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	position := level * float64(len(sorted)-1)
+	below := int(math.Floor(position))
+	if below >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	fraction := position - float64(below)
+	return sorted[below] + fraction*(sorted[below+1]-sorted[below])
+}
+
+// ResidualInterval builds a band around price from relative residuals,
+// (actual - predicted) / predicted, of past predictions
+func ResidualInterval(price float64, residuals []float64, coverage float64) PredictionInterval {
+	sorted := append([]float64(nil), residuals...)
+	sort.Float64s(sorted)
+	lower, upper := CoverageLevels(coverage)
+	return PredictionInterval{
+		Coverage: coverage,
+		Lower:    price * (1 + EmpiricalQuantile(sorted, lower)),
+		Upper:    price * (1 + EmpiricalQuantile(sorted, upper)),
+		Source:   IntervalSourceResiduals,
+	}
+}
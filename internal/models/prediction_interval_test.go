@@ -0,0 +1,41 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmpiricalQuantile(t *testing.T) {
+	sorted := []float64{-0.04, -0.02, 0, 0.01, 0.05}
+	assert.Equal(t, -0.04, EmpiricalQuantile(sorted, 0))
+	assert.Equal(t, 0.05, EmpiricalQuantile(sorted, 1))
+	assert.Equal(t, 0.0, EmpiricalQuantile(sorted, 0.5))
+	assert.InDelta(t, -0.03, EmpiricalQuantile(sorted, 0.125), 1e-12) // Halfway between the first two
+	assert.True(t, math.IsNaN(EmpiricalQuantile(nil, 0.5)))
+}
+
+func TestResidualInterval(t *testing.T) {
+	lower, upper := CoverageLevels(0.8)
+	assert.InDelta(t, 0.1, lower, 1e-12)
+	assert.InDelta(t, 0.9, upper, 1e-12)
+
+	// Residuals of -5% .. +5% in 1% steps, in no particular order
+	var residuals []float64
+	for i := 5; i >= -5; i-- {
+		residuals = append(residuals, float64(i)/100)
+	}
+	interval := ResidualInterval(200, residuals, 0.8)
+	assert.Equal(t, IntervalSourceResiduals, interval.Source)
+	assert.InDelta(t, 192, interval.Lower, 1e-9)
+	assert.InDelta(t, 208, interval.Upper, 1e-9)
+	assert.True(t, interval.Contains(200))
+	assert.False(t, interval.Contains(209))
+	assert.Equal(t, 5.0, residuals[0]*100, "input left unsorted")
+
+	for _, coverage := range []float64{0, 1, -0.5, 95} {
+		assert.Error(t, ValidateCoverage(coverage))
+	}
+	assert.NoError(t, ValidateCoverage(0.95))
+}
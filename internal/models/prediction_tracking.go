@@ -8,14 +8,24 @@ import (
 type PredictionTracking struct {
 	ID                    int       `json:"id" db:"id"`
 	Symbol                string    `json:"symbol" db:"symbol"`
-	PredictionDate        time.Time `json:"prediction_date" db:"prediction_date"`
+	PredictionDate        time.Time `json:"prediction_date" db:"prediction_date"` // Session whose close is predicted
+	HorizonDays           int       `json:"horizon_days" db:"horizon_days"`       // Trading days ahead when predicted
 	PredictedPrice        *float64  `json:"predicted_price" db:"predicted_price"`
 	PredictedDirection    *string   `json:"predicted_direction" db:"predicted_direction"`
 	Confidence            *float64  `json:"confidence" db:"confidence"`
 	ActualClose           *float64  `json:"actual_close" db:"actual_close"`
 	AccuracyMAPE          *float64  `json:"accuracy_mape" db:"accuracy_mape"`
 	DirectionCorrect      *bool     `json:"direction_correct" db:"direction_correct"`
+	ResidualPercent       *float64  `json:"residual_percent,omitempty" db:"residual_pct"` // Signed error, (actual - predicted) / predicted * 100
+	Intervals             []PredictionInterval `json:"intervals,omitempty"`
 	MarketWasOpen         bool      `json:"market_was_open" db:"market_was_open"`
+	Currency              string    `json:"currency" db:"currency"` // Currency of predicted_price and actual_close
+	NativeCurrency        string    `json:"native_currency,omitempty"` // Stored currency when converted for reporting
+	FXRate                *float64  `json:"fx_rate,omitempty"`
+	EventTypes            string    `json:"event_types,omitempty" db:"event_types"` // Comma-separated corporate events near the prediction date
+	Model                 string    `json:"model,omitempty" db:"model"`
+	ModelVersion          string    `json:"model_version,omitempty" db:"model_version"`
+	ModelVersionID        *int64    `json:"model_version_id,omitempty" db:"model_version_id"` // Model registry id, when registered
 	PredictionTimestamp   time.Time `json:"prediction_timestamp" db:"prediction_timestamp"`
 	ActualPriceTimestamp  *time.Time `json:"actual_price_timestamp" db:"actual_price_timestamp"`
 	CreatedAt             time.Time `json:"created_at" db:"created_at"`
@@ -53,6 +63,7 @@ type DailyExecutionLog struct {
 // PredictionAccuracySummary represents accuracy statistics for a symbol
 type PredictionAccuracySummary struct {
 	Symbol                string  `json:"symbol"`
+	Currency              string  `json:"currency,omitempty"`
 	TotalPredictions      int     `json:"total_predictions"`
 	PredictionsWithActual int     `json:"predictions_with_actual"`
 	AverageAccuracyMAPE   float64 `json:"average_accuracy_mape"`
@@ -61,6 +72,29 @@ type PredictionAccuracySummary struct {
 	BestAccuracy          float64 `json:"best_accuracy"`
 	WorstAccuracy         float64 `json:"worst_accuracy"`
 	LastPredictionDate    *time.Time `json:"last_prediction_date"`
+	Horizons              []HorizonAccuracy `json:"horizons,omitempty"`
+	Intervals             []IntervalAccuracy `json:"intervals,omitempty"`
+}
+
+// HorizonAccuracy aggregates predictions made the same number of trading
+// days ahead
+type HorizonAccuracy struct {
+	HorizonDays           int     `json:"horizon_days"`
+	TotalPredictions      int     `json:"total_predictions"`
+	PredictionsWithActual int     `json:"predictions_with_actual"`
+	AccuracyMAPE          float64 `json:"accuracy_mape"`
+	DirectionAccuracy     float64 `json:"direction_accuracy"`
+	Intervals             []IntervalAccuracy `json:"intervals,omitempty"`
+}
+
+// IntervalAccuracy compares the observed coverage of prediction intervals
+// with their nominal coverage
+type IntervalAccuracy struct {
+	Coverage            float64 `json:"coverage"`              // Nominal, e.g. 0.8
+	TotalIntervals      int     `json:"total_intervals"`
+	IntervalsWithActual int     `json:"intervals_with_actual"`
+	ObservedCoverage    float64 `json:"observed_coverage"`     // Percentage of actual closes inside the band
+	AverageWidthPercent float64 `json:"average_width_percent"` // Band width as a percentage of the predicted price
 }
 
 // PredictionPerformanceMetrics represents overall performance metrics
@@ -73,23 +107,50 @@ type PredictionPerformanceMetrics struct {
 	SymbolSummaries       []PredictionAccuracySummary `json:"symbol_summaries"`
 	LastExecutionDate     *time.Time                  `json:"last_execution_date"`
 	LastExecutionStatus   string                      `json:"last_execution_status"`
+	// Price errors are only combined across currencies after conversion;
+	// MAPE and direction accuracy are unit-free and aggregate directly
+	ReportingCurrency     string                      `json:"reporting_currency,omitempty"`
+	MeanAbsoluteError     *float64                    `json:"mean_absolute_error,omitempty"` // In ReportingCurrency
+	Currencies            []CurrencyPerformance       `json:"currencies"`
+	Horizons              []HorizonAccuracy           `json:"horizons"`
+	Intervals             []IntervalAccuracy          `json:"intervals"`
+}
+
+// CurrencyPerformance aggregates predictions quoted in one currency
+type CurrencyPerformance struct {
+	Currency              string   `json:"currency"`
+	TotalSymbols          int      `json:"total_symbols"`
+	TotalPredictions      int      `json:"total_predictions"`
+	PredictionsWithActual int      `json:"predictions_with_actual"`
+	AccuracyMAPE          float64  `json:"accuracy_mape"`
+	DirectionAccuracy     float64  `json:"direction_accuracy"`
+	MeanAbsoluteError     *float64 `json:"mean_absolute_error,omitempty"` // In Currency
 }
 
 // CreatePredictionRequest represents a request to create a new prediction
 type CreatePredictionRequest struct {
 	Symbol             string    `json:"symbol" validate:"required"`
 	PredictionDate     time.Time `json:"prediction_date" validate:"required"`
+	HorizonDays        int       `json:"horizon_days"` // Defaults to 1, the next session
 	PredictedPrice     *float64  `json:"predicted_price"`
 	PredictedDirection *string   `json:"predicted_direction"`
 	Confidence         *float64  `json:"confidence"`
 	MarketWasOpen      bool      `json:"market_was_open"`
+	Currency           string    `json:"currency"` // Defaults to the symbol's quote currency
+	EventTypes         string    `json:"event_types"` // Comma-separated corporate events near the prediction date
+	Intervals          []PredictionInterval `json:"intervals"`
+	Members            []EnsembleMember     `json:"members"` // Ensemble members, scored to weight them later
+	Model              string               `json:"model"`
+	ModelVersion       string               `json:"model_version"`
+	ModelVersionID     int64                `json:"model_version_id"` // Model registry id; 0 when unregistered
 }
 
 // UpdateActualPriceRequest represents a request to update actual closing price
 type UpdateActualPriceRequest struct {
 	Symbol      string   `json:"symbol" validate:"required"`
 	Date        time.Time `json:"date" validate:"required"`
-	ActualClose float64  `json:"actual_close" validate:"required"`
+	ActualClose float64  `json:"actual_close,omitempty"` // 0 or omitted uses the stored close
+	Adjusted    *bool    `json:"adjusted,omitempty"` // Restate predictions straddling a split (default true)
 }
 
 // DailyPredictionRequest represents a request for daily predictions
@@ -109,6 +170,8 @@ type PredictionHistoryQuery struct {
 	Offset    int        `json:"offset"`
 	OrderBy   string     `json:"order_by"` // 'date', 'accuracy', 'confidence'
 	OrderDir  string     `json:"order_dir"` // 'asc', 'desc'
+	Currency  string     `json:"currency"`  // Reporting currency; empty keeps stored currencies
+	Horizon   *int       `json:"horizon"`   // Trading days ahead; nil returns every horizon
 }
 
 // AccuracyRangeQuery represents query parameters for accuracy data in a date range
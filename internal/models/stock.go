@@ -3,7 +3,6 @@ package models
 import (
 	"fmt"
 	"math"
-	"regexp"
 	"time"
 )
 
@@ -15,14 +14,25 @@ type StockData struct {
 	High      float64   `json:"high"`
 	Low       float64   `json:"low"`
 	Close     float64   `json:"close"`
+	AdjClose  float64   `json:"adj_close,omitempty"` // split and dividend adjusted close
 	Volume    int64     `json:"volume"`
+	Interval  Interval  `json:"interval,omitempty"`
+	Filled    bool      `json:"filled,omitempty"` // synthesised by the gap policy
+	Currency  string    `json:"currency,omitempty"` // quote currency, e.g. "TWD" or "GBp"
 }
 
 // PredictionRequest represents a prediction request
 type PredictionRequest struct {
 	Symbol       string    `json:"symbol"`
 	HistoricalData []float64 `json:"historical_data"`
+	Interval     Interval  `json:"interval"`
+	Adjusted     bool      `json:"adjusted"`
 	RequestTime  time.Time `json:"request_time"`
+	EventRisk    *EventRisk `json:"event_risk,omitempty"` // events near the target date, if known
+	Model        string    `json:"model,omitempty"`      // registered model name; empty uses the default
+	Input        *ModelInput `json:"input,omitempty"`    // OHLCV bars and symbol metadata, for models that need them
+	Horizons     []int       `json:"horizons,omitempty"` // trading-day horizons of a forecast path
+	ForecastDates []time.Time `json:"forecast_dates,omitempty"` // sessions after the last bar, one per trading day up to the furthest horizon
 }
 
 // PredictionResponse represents a prediction response
@@ -33,7 +43,23 @@ type PredictionResponse struct {
 	TradingSignal   string    `json:"trading_signal"`
 	Confidence      float64   `json:"confidence"`
 	PredictionTime  time.Time `json:"prediction_time"`
-	ModelVersion    string    `json:"model_version"`
+	ModelVersion    string    `json:"model_version"` // production registry version, or the built-in version when none is registered
+	ModelVersionID  int64     `json:"model_version_id,omitempty"` // model registry id of ModelVersion
+	Model           string    `json:"model,omitempty"`
+	Interval        Interval  `json:"interval"`
+	Adjusted        bool      `json:"adjusted"`
+	DataQuality     *DataQualityReport `json:"data_quality,omitempty"`
+	AssetClass      AssetClass `json:"asset_class,omitempty"`
+	Exchange        string    `json:"exchange,omitempty"` // listing exchange code, e.g. "TWSE"
+	Synthetic       bool      `json:"synthetic,omitempty"` // built from sandbox market data
+	Currency        string    `json:"currency,omitempty"` // currency of the prices above
+	NativeCurrency  string    `json:"native_currency,omitempty"` // quote currency when converted via ?currency=
+	FXRate          float64   `json:"fx_rate,omitempty"` // native to reporting currency rate applied
+	EventRisk       *EventRisk `json:"event_risk,omitempty"` // earnings, dividends or splits near the target date
+	Diagnostics     *PredictionDiagnostics `json:"diagnostics,omitempty"` // model output and timing, with ?debug=true
+	Intervals       []PredictionInterval `json:"intervals,omitempty"` // bands around PredictedPrice at each configured coverage
+	Forecasts       []ForecastPoint `json:"forecasts,omitempty"` // forecast path, with ?horizons=
+	Ensemble        *EnsembleResult `json:"ensemble,omitempty"` // member predictions, weights and disagreement, from the ensemble model
 }
 
 // TradingSignal represents trading recommendations
@@ -48,28 +74,94 @@ const (
 // YahooFinanceResponse represents Yahoo Finance API response
 type YahooFinanceResponse struct {
 	Chart struct {
+		Result []YahooChartResult `json:"result"`
+		Error  *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"chart"`
+}
+
+// YahooChartResult represents one symbol's series in a Yahoo chart response
+type YahooChartResult struct {
+	Meta struct {
+		Symbol               string  `json:"symbol"`
+		Currency             string  `json:"currency"`
+		RegularMarketPrice   float64 `json:"regularMarketPrice"`
+		GmtOffset            int64   `json:"gmtoffset"`
+		ExchangeName         string  `json:"exchangeName"`
+		FullExchangeName     string  `json:"fullExchangeName"`
+		InstrumentType       string  `json:"instrumentType"`
+		FirstTradeDate       *int64  `json:"firstTradeDate"`
+		ExchangeTimezoneName string  `json:"exchangeTimezoneName"`
+		LongName             string  `json:"longName"`
+		ShortName            string  `json:"shortName"`
+	} `json:"meta"`
+	Timestamp  []int64 `json:"timestamp"`
+	Events     struct {
+		Dividends map[string]struct {
+			Amount float64 `json:"amount"`
+			Date   int64   `json:"date"`
+		} `json:"dividends"`
+		Splits map[string]struct {
+			Date        int64   `json:"date"`
+			Numerator   float64 `json:"numerator"`
+			Denominator float64 `json:"denominator"`
+			SplitRatio  string  `json:"splitRatio"`
+		} `json:"splits"`
+	} `json:"events"`
+	Indicators struct {
+		// Entries are null for halted or partial bars
+		Quote []struct {
+			Open   []*float64 `json:"open"`
+			High   []*float64 `json:"high"`
+			Low    []*float64 `json:"low"`
+			Close  []*float64 `json:"close"`
+			Volume []*int64   `json:"volume"`
+		} `json:"quote"`
+		AdjClose []struct {
+			AdjClose []*float64 `json:"adjclose"`
+		} `json:"adjclose"`
+	} `json:"indicators"`
+}
+
+// YahooSearchResponse represents a Yahoo Finance symbol search response
+type YahooSearchResponse struct {
+	Quotes []struct {
+		Symbol    string `json:"symbol"`
+		ShortName string `json:"shortname"`
+		LongName  string `json:"longname"`
+		Exchange  string `json:"exchange"`
+		ExchDisp  string `json:"exchDisp"`
+		QuoteType string `json:"quoteType"`
+	} `json:"quotes"`
+}
+
+// YahooQuoteSummaryResponse represents a Yahoo Finance quoteSummary response
+// for the calendarEvents module
+type YahooQuoteSummaryResponse struct {
+	QuoteSummary struct {
 		Result []struct {
-			Meta struct {
-				Symbol   string  `json:"symbol"`
-				Currency string  `json:"currency"`
-				RegularMarketPrice float64 `json:"regularMarketPrice"`
-			} `json:"meta"`
-			Timestamp  []int64 `json:"timestamp"`
-			Indicators struct {
-				Quote []struct {
-					Open   []float64 `json:"open"`
-					High   []float64 `json:"high"`
-					Low    []float64 `json:"low"`
-					Close  []float64 `json:"close"`
-					Volume []int64   `json:"volume"`
-				} `json:"quote"`
-			} `json:"indicators"`
+			CalendarEvents struct {
+				Earnings struct {
+					EarningsDate           []YahooDateValue `json:"earningsDate"`
+					IsEarningsDateEstimate bool             `json:"isEarningsDateEstimate"`
+				} `json:"earnings"`
+				ExDividendDate *YahooDateValue `json:"exDividendDate"`
+				DividendDate   *YahooDateValue `json:"dividendDate"`
+			} `json:"calendarEvents"`
 		} `json:"result"`
 		Error *struct {
 			Code        string `json:"code"`
 			Description string `json:"description"`
 		} `json:"error"`
-	} `json:"chart"`
+	} `json:"quoteSummary"`
+}
+
+// YahooDateValue is a quoteSummary date: epoch seconds plus a formatted date
+type YahooDateValue struct {
+	Raw int64  `json:"raw"`
+	Fmt string `json:"fmt"`
 }
 
 // HealthStatus represents system health
@@ -82,19 +174,16 @@ type HealthStatus struct {
 
 // Validation functions
 
-// ValidateSymbol validates stock symbol format
+// ValidateSymbol validates that a symbol is a ticker in its normalised form
+// (see ParseSymbol); callers normalise user input first
 func ValidateSymbol(symbol string) error {
-	if symbol == "" {
-		return fmt.Errorf("symbol cannot be empty")
-	}
-	
-	matched, err := regexp.MatchString("^[A-Z]{1,5}$", symbol)
+	parsed, err := ParseSymbol(symbol)
 	if err != nil {
-		return fmt.Errorf("regex error: %w", err)
+		return err
 	}
 	
-	if !matched {
-		return fmt.Errorf("invalid symbol format: %s (must be 1-5 uppercase letters)", symbol)
+	if parsed.Ticker != symbol {
+		return fmt.Errorf("invalid symbol format: %s (expected normalised form %s)", symbol, parsed.Ticker)
 	}
 	
 	return nil
@@ -131,6 +220,15 @@ func (pr *PredictionRequest) Validate() error {
 		return fmt.Errorf("invalid historical data: %w", err)
 	}
 	
+	for _, days := range pr.Horizons {
+		if days < 1 || days > MaxForecastHorizon {
+			return fmt.Errorf("invalid horizon: %d (must be 1-%d trading days)", days, MaxForecastHorizon)
+		}
+	}
+	if furthest := MaxHorizon(pr.Horizons); len(pr.ForecastDates) < furthest {
+		return fmt.Errorf("forecast dates cover %d sessions, need %d", len(pr.ForecastDates), furthest)
+	}
+	
 	return nil
 }
 
@@ -181,4 +279,5 @@ type ModelInfo struct {
 	Description string                 `json:"description"`
 	Features    []string               `json:"features"`
 	Config      map[string]interface{} `json:"config"`
+	Registry    *ModelVersion          `json:"registry,omitempty"` // production version, when registered
 }
@@ -19,8 +19,13 @@ func TestValidateSymbol(t *testing.T) {
 		{"Empty symbol", "", true},
 		{"Lowercase symbol", "aapl", true},
 		{"Too long symbol", "TOOLONG", true},
-		{"Invalid characters", "AA-PL", true},
+		{"Invalid characters", "AA_PL", true},
 		{"Numbers in symbol", "AA1PL", true},
+		{"Share class", "BRK-B", false},
+		{"Index", "^GSPC", false},
+		{"TWSE listing", "2330.TW", false},
+		{"Crypto pair", "BTC-USD", false},
+		{"Unnormalised share class", "BRK.B", true},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,271 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// AssetClass classifies what a symbol trades
+type AssetClass string
+
+const (
+	AssetEquity   AssetClass = "equity"
+	AssetIndex    AssetClass = "index"
+	AssetCrypto   AssetClass = "crypto"
+	AssetCurrency AssetClass = "currency"
+	AssetFuture   AssetClass = "future"
+)
+
+// Exchange describes the venue a symbol is listed on
+type Exchange struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Suffix   string `json:"suffix,omitempty"` // Yahoo Finance ticker suffix, e.g. "TW"
+	Currency string `json:"currency"`
+	Timezone string `json:"timezone"`
+}
+
+// Location returns the exchange time zone, falling back to UTC
+func (e Exchange) Location() *time.Location {
+	if loc, err := time.LoadLocation(e.Timezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+var (
+	// ExchangeUS covers NYSE, Nasdaq and other unsuffixed US listings
+	ExchangeUS = Exchange{Code: "US", Name: "US Exchanges", Currency: "USD", Timezone: "America/New_York"}
+	// ExchangeCrypto is Yahoo's CoinMarketCap-sourced crypto venue
+	ExchangeCrypto = Exchange{Code: "CCC", Name: "CoinMarketCap", Timezone: "UTC"}
+	// ExchangeFX is the interbank currency market
+	ExchangeFX = Exchange{Code: "CCY", Name: "Currency", Timezone: "Europe/London"}
+)
+
+// exchangesBySuffix maps Yahoo Finance ticker suffixes to exchanges
+var exchangesBySuffix = map[string]Exchange{
+	"TW":  {Code: "TWSE", Name: "Taiwan Stock Exchange", Suffix: "TW", Currency: "TWD", Timezone: "Asia/Taipei"},
+	"TWO": {Code: "TPEX", Name: "Taipei Exchange", Suffix: "TWO", Currency: "TWD", Timezone: "Asia/Taipei"},
+	"T":   {Code: "JPX", Name: "Tokyo Stock Exchange", Suffix: "T", Currency: "JPY", Timezone: "Asia/Tokyo"},
+	"HK":  {Code: "HKEX", Name: "Hong Kong Stock Exchange", Suffix: "HK", Currency: "HKD", Timezone: "Asia/Hong_Kong"},
+	"KS":  {Code: "KRX", Name: "Korea Exchange", Suffix: "KS", Currency: "KRW", Timezone: "Asia/Seoul"},
+	"L":   {Code: "LSE", Name: "London Stock Exchange", Suffix: "L", Currency: "GBp", Timezone: "Europe/London"}, // Quoted in pence
+	"DE":  {Code: "XETRA", Name: "Xetra", Suffix: "DE", Currency: "EUR", Timezone: "Europe/Berlin"},
+	"PA":  {Code: "EPA", Name: "Euronext Paris", Suffix: "PA", Currency: "EUR", Timezone: "Europe/Paris"},
+	"AS":  {Code: "AMS", Name: "Euronext Amsterdam", Suffix: "AS", Currency: "EUR", Timezone: "Europe/Amsterdam"},
+	"TO":  {Code: "TSX", Name: "Toronto Stock Exchange", Suffix: "TO", Currency: "CAD", Timezone: "America/Toronto"},
+	"AX":  {Code: "ASX", Name: "Australian Securities Exchange", Suffix: "AX", Currency: "AUD", Timezone: "Australia/Sydney"},
+}
+
+// exchangesByIndex maps non-US index symbols to their home exchange
+var exchangesByIndex = map[string]string{
+	"^TWII":   "TW",
+	"^TWOII":  "TWO",
+	"^N225":   "T",
+	"^HSI":    "HK",
+	"^KS11":   "KS",
+	"^FTSE":   "L",
+	"^GDAXI":  "DE",
+	"^FCHI":   "PA",
+	"^AEX":    "AS",
+	"^GSPTSE": "TO",
+	"^AXJO":   "AX",
+}
+
+// cryptoQuoteCurrencies are the quote legs Yahoo lists crypto pairs against
+var cryptoQuoteCurrencies = map[string]bool{
+	"USD": true, "USDT": true, "USDC": true, "EUR": true, "GBP": true,
+	"JPY": true, "TWD": true, "BTC": true, "ETH": true,
+}
+
+var (
+	indexPattern    = regexp.MustCompile(`^\^[A-Z0-9]{1,10}$`)
+	currencyPattern = regexp.MustCompile(`^([A-Z]{3})?([A-Z]{3})=X$`)
+	futurePattern   = regexp.MustCompile(`^[A-Z0-9]{1,6}=F$`)
+	cryptoPattern   = regexp.MustCompile(`^([A-Z0-9]{2,10})-([A-Z]{3,4})$`)
+	equityPattern   = regexp.MustCompile(`^([A-Z0-9]{1,10})(-[A-Z]{1,2})?(\.([A-Z]{1,3}))?$`)
+	usEquityPattern = regexp.MustCompile(`^[A-Z]{1,5}(-[A-Z]{1,2})?$`)
+	digitsPattern   = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// shareClasses are the dotted class suffixes rewritten to Yahoo's dash form;
+// other single letters are exchange suffixes (e.g. .F for Frankfurt)
+var shareClasses = map[string]bool{"A": true, "B": true, "C": true}
+
+// maxSymbolLength bounds symbols before any pattern matching
+const maxSymbolLength = 20
+
+// Symbol is a parsed ticker in Yahoo Finance notation
+type Symbol struct {
+	Ticker     string     `json:"ticker"` // Normalised ticker, e.g. "2330.TW"
+	Base       string     `json:"base"`   // Ticker without exchange suffix, e.g. "2330"
+	AssetClass AssetClass `json:"asset_class"`
+	Exchange   Exchange   `json:"exchange"`
+}
+
+// ParseSymbol normalises and classifies a ticker. It accepts US equities
+// and share classes (NVDA, BRK-B), exchange-suffixed listings (2330.TW,
+// 6488.TWO, VOD.L), indices (^GSPC, ^TWII), crypto pairs (BTC-USD),
+// currency pairs (EURUSD=X) and futures (GC=F). Input is case-insensitive.
+func ParseSymbol(raw string) (Symbol, error) {
+	ticker := strings.ToUpper(strings.TrimSpace(raw))
+	if ticker == "" {
+		return Symbol{}, fmt.Errorf("symbol cannot be empty")
+	}
+	if len(ticker) > maxSymbolLength {
+		return Symbol{}, fmt.Errorf("invalid symbol format: %s (longer than %d characters)", raw, maxSymbolLength)
+	}
+
+	switch {
+	case indexPattern.MatchString(ticker):
+		exchange := ExchangeUS
+		if suffix, ok := exchangesByIndex[ticker]; ok {
+			exchange = exchangesBySuffix[suffix]
+		}
+		return Symbol{Ticker: ticker, Base: ticker, AssetClass: AssetIndex, Exchange: exchange}, nil
+
+	case currencyPattern.MatchString(ticker):
+		// USD-based pairs drop the base leg: "TWD=X" is USD/TWD
+		match := currencyPattern.FindStringSubmatch(ticker)
+		exchange := ExchangeFX
+		exchange.Currency = match[2]
+		return Symbol{Ticker: ticker, Base: ticker, AssetClass: AssetCurrency, Exchange: exchange}, nil
+
+	case futurePattern.MatchString(ticker):
+		return Symbol{Ticker: ticker, Base: ticker, AssetClass: AssetFuture, Exchange: ExchangeUS}, nil
+	}
+
+	if match := cryptoPattern.FindStringSubmatch(ticker); match != nil && cryptoQuoteCurrencies[match[2]] {
+		exchange := ExchangeCrypto
+		exchange.Currency = match[2]
+		return Symbol{Ticker: ticker, Base: match[1], AssetClass: AssetCrypto, Exchange: exchange}, nil
+	}
+
+	match := equityPattern.FindStringSubmatch(ticker)
+	if match == nil {
+		return Symbol{}, fmt.Errorf("invalid symbol format: %s", raw)
+	}
+
+	base := match[1] + match[2]
+	suffix := match[4]
+	if suffix == "" {
+		// US tickers are 1-5 letters; numeric codes only exist on suffixed exchanges
+		if digitsPattern.MatchString(match[1]) {
+			return Symbol{}, fmt.Errorf("invalid symbol format: %s (numeric tickers need an exchange suffix such as .TW)", raw)
+		}
+		if !usEquityPattern.MatchString(ticker) {
+			return Symbol{}, fmt.Errorf("invalid symbol format: %s (US tickers are 1-5 letters with an optional share class, e.g. BRK-B)", raw)
+		}
+		return Symbol{Ticker: ticker, Base: base, AssetClass: AssetEquity, Exchange: ExchangeUS}, nil
+	}
+
+	exchange, ok := exchangesBySuffix[suffix]
+	if !ok && match[2] == "" && shareClasses[suffix] && usEquityPattern.MatchString(match[1]) {
+		// Dotted share classes (BRK.B) are written with a dash on Yahoo
+		ticker = match[1] + "-" + suffix
+		return Symbol{Ticker: ticker, Base: ticker, AssetClass: AssetEquity, Exchange: ExchangeUS}, nil
+	}
+	if !ok {
+		return Symbol{}, fmt.Errorf("invalid symbol format: %s (unknown exchange suffix .%s)", raw, suffix)
+	}
+	return Symbol{Ticker: ticker, Base: base, AssetClass: AssetEquity, Exchange: exchange}, nil
+}
+
+// NormalizeSymbol returns the canonical form of a ticker
+func NormalizeSymbol(raw string) (string, error) {
+	symbol, err := ParseSymbol(raw)
+	if err != nil {
+		return "", err
+	}
+	return symbol.Ticker, nil
+}
+
+// String returns the normalised ticker
+func (s Symbol) String() string {
+	return s.Ticker
+}
+
+// IsUS reports whether the symbol trades on the US calendar
+func (s Symbol) IsUS() bool {
+	return s.Exchange.Code == ExchangeUS.Code
+}
+
+// IsTradingDay reports whether the symbol's market is expected to produce a
+// daily bar on date. Crypto trades every day, US listings follow the US
+// holiday calendar and other venues are approximated by weekdays.
+func (s Symbol) IsTradingDay(date time.Time) bool {
+	switch {
+	case s.AssetClass == AssetCrypto:
+		return true
+	case s.IsUS():
+		return IsUSTradingDay(date)
+	default:
+		weekday := date.Weekday()
+		return weekday != time.Saturday && weekday != time.Sunday
+	}
+}
+
+// maxMarketClosure bounds the run of consecutive days a market is assumed
+// to stay closed, so a broken calendar cannot stall a date search
+const maxMarketClosure = 10
+
+// SessionDate returns the date of instant t in the exchange's time zone
+func (s Symbol) SessionDate(t time.Time) time.Time {
+	local := t.In(s.Exchange.Location())
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// TradingDaysAfter returns the next n trading days of the symbol's market
+// after a date. isUSTradingDay, when not nil, replaces the built-in US
+// holiday list so closures recorded in the market calendar apply.
+func (s Symbol) TradingDaysAfter(after time.Time, n int, isUSTradingDay func(time.Time) bool) []time.Time {
+	isTradingDay := s.tradingDays(isUSTradingDay)
+	days := make([]time.Time, 0, n)
+	day := time.Date(after.Year(), after.Month(), after.Day(), 0, 0, 0, 0, time.UTC)
+	for len(days) < n {
+		for i := 0; i < maxMarketClosure; i++ {
+			day = day.AddDate(0, 0, 1)
+			if isTradingDay(day) {
+				break
+			}
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+// TradingDayBefore returns the trading day of the symbol's market n
+// sessions before a date
+func (s Symbol) TradingDayBefore(date time.Time, n int, isUSTradingDay func(time.Time) bool) time.Time {
+	isTradingDay := s.tradingDays(isUSTradingDay)
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	for sessions := 0; sessions < n; sessions++ {
+		for i := 0; i < maxMarketClosure; i++ {
+			day = day.AddDate(0, 0, -1)
+			if isTradingDay(day) {
+				break
+			}
+		}
+	}
+	return day
+}
+
+// tradingDays returns the symbol's trading day calendar, using
+// isUSTradingDay for US listings when it is not nil
+func (s Symbol) tradingDays(isUSTradingDay func(time.Time) bool) func(time.Time) bool {
+	if s.IsUS() && isUSTradingDay != nil {
+		return isUSTradingDay
+	}
+	return s.IsTradingDay
+}
+
+// tradingDayFunc returns the calendar for a ticker, defaulting to the US one
+// for tickers that do not parse
+func tradingDayFunc(ticker string) func(time.Time) bool {
+	if symbol, err := ParseSymbol(ticker); err == nil {
+		return symbol.IsTradingDay
+	}
+	return IsUSTradingDay
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// SymbolMetadata describes an instrument as reported by the market data
+// provider, enriched with the asset class and exchange parsed from the ticker
+type SymbolMetadata struct {
+	Symbol         string     `json:"symbol"`
+	LongName       string     `json:"long_name,omitempty"`
+	ShortName      string     `json:"short_name,omitempty"`
+	AssetClass     AssetClass `json:"asset_class"`
+	ExchangeCode   string     `json:"exchange_code"`      // Parsed from the ticker, e.g. "TWSE"
+	Exchange       string     `json:"exchange,omitempty"` // Provider exchange name, e.g. "NasdaqGS"
+	Currency       string     `json:"currency,omitempty"`
+	Timezone       string     `json:"timezone,omitempty"`        // IANA zone, e.g. "America/New_York"
+	InstrumentType string     `json:"instrument_type,omitempty"` // Provider type, e.g. "EQUITY", "ETF"
+	FirstTradeDate *time.Time `json:"first_trade_date,omitempty"`
+	Source         string     `json:"source"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// NewSymbolMetadata returns metadata for a ticker with the fields implied by
+// its grammar filled in, for providers to complete
+func NewSymbolMetadata(symbol Symbol, source string) SymbolMetadata {
+	return SymbolMetadata{
+		Symbol:       symbol.Ticker,
+		AssetClass:   symbol.AssetClass,
+		ExchangeCode: symbol.Exchange.Code,
+		Currency:     symbol.Exchange.Currency,
+		Timezone:     symbol.Exchange.Timezone,
+		Source:       source,
+		UpdatedAt:    time.Now(),
+	}
+}
+
+// DisplayName returns the best available human-readable name
+func (m SymbolMetadata) DisplayName() string {
+	switch {
+	case m.LongName != "":
+		return m.LongName
+	case m.ShortName != "":
+		return m.ShortName
+	default:
+		return m.Symbol
+	}
+}
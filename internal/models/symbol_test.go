@@ -0,0 +1,107 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSymbol(t *testing.T) {
+	tests := []struct {
+		input    string
+		ticker   string
+		class    AssetClass
+		exchange string
+		currency string
+	}{
+		{"NVDA", "NVDA", AssetEquity, "US", "USD"},
+		{" nvda ", "NVDA", AssetEquity, "US", "USD"},
+		{"BRK-B", "BRK-B", AssetEquity, "US", "USD"},
+		{"brk.b", "BRK-B", AssetEquity, "US", "USD"},
+		{"^GSPC", "^GSPC", AssetIndex, "US", "USD"},
+		{"^twii", "^TWII", AssetIndex, "TWSE", "TWD"},
+		{"2330.TW", "2330.TW", AssetEquity, "TWSE", "TWD"},
+		{"00878.tw", "00878.TW", AssetEquity, "TWSE", "TWD"},
+		{"6488.TWO", "6488.TWO", AssetEquity, "TPEX", "TWD"},
+		{"7203.T", "7203.T", AssetEquity, "JPX", "JPY"},
+		{"VOD.L", "VOD.L", AssetEquity, "LSE", "GBp"},
+		{"BTC-USD", "BTC-USD", AssetCrypto, "CCC", "USD"},
+		{"eth-btc", "ETH-BTC", AssetCrypto, "CCC", "BTC"},
+		{"EURUSD=X", "EURUSD=X", AssetCurrency, "CCY", "USD"},
+		{"TWD=X", "TWD=X", AssetCurrency, "CCY", "TWD"},
+		{"GC=F", "GC=F", AssetFuture, "US", "USD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			symbol, err := ParseSymbol(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.ticker, symbol.Ticker)
+			assert.Equal(t, tt.class, symbol.AssetClass)
+			assert.Equal(t, tt.exchange, symbol.Exchange.Code)
+			assert.Equal(t, tt.currency, symbol.Exchange.Currency)
+		})
+	}
+}
+
+func TestParseSymbolRejects(t *testing.T) {
+	for _, input := range []string{"", "2330", "TOOLONG", "AAPL.XX", "../etc", "A B", "NVDA/..", "^", "BTC-", "ABCDEFGHIJKLMNOPQRSTUVWXYZ"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseSymbol(input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSymbolTradingDays(t *testing.T) {
+	saturday := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	thanksgiving := time.Date(2026, 11, 26, 0, 0, 0, 0, time.UTC)
+
+	crypto, _ := ParseSymbol("BTC-USD")
+	us, _ := ParseSymbol("NVDA")
+	tw, _ := ParseSymbol("2330.TW")
+
+	assert.True(t, crypto.IsTradingDay(saturday))
+	assert.False(t, us.IsTradingDay(saturday))
+	assert.False(t, us.IsTradingDay(thanksgiving))
+	assert.True(t, tw.IsTradingDay(thanksgiving))
+	assert.False(t, tw.IsTradingDay(saturday))
+}
+
+func TestSymbolTradingDaysAfter(t *testing.T) {
+	wednesday := time.Date(2026, 11, 25, 0, 0, 0, 0, time.UTC) // Before Thanksgiving
+	us, _ := ParseSymbol("NVDA")
+	tw, _ := ParseSymbol("2330.TW")
+	crypto, _ := ParseSymbol("BTC-USD")
+
+	assert.Equal(t, []time.Time{
+		time.Date(2026, 11, 27, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC),
+	}, us.TradingDaysAfter(wednesday, 2, nil))
+	assert.Equal(t, []time.Time{
+		time.Date(2026, 11, 26, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 11, 27, 0, 0, 0, 0, time.UTC),
+	}, tw.TradingDaysAfter(wednesday, 2, nil))
+	assert.Equal(t, time.Date(2026, 11, 28, 0, 0, 0, 0, time.UTC), crypto.TradingDaysAfter(wednesday, 3, nil)[2])
+
+	// A supplied US calendar overrides the built-in holidays for US listings only
+	closed := func(date time.Time) bool { return date.Day() != 27 && IsUSTradingDay(date) }
+	assert.Equal(t, time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC), us.TradingDaysAfter(wednesday, 1, closed)[0])
+	assert.Equal(t, time.Date(2026, 11, 27, 0, 0, 0, 0, time.UTC), tw.TradingDaysAfter(wednesday, 2, closed)[1])
+
+	monday := time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 11, 25, 0, 0, 0, 0, time.UTC), us.TradingDayBefore(monday, 2, nil))
+	assert.Equal(t, time.Date(2026, 11, 26, 0, 0, 0, 0, time.UTC), tw.TradingDayBefore(monday, 2, nil))
+}
+
+func TestSymbolSessionDate(t *testing.T) {
+	// 22:00 UTC is already the next day in Taipei
+	instant := time.Date(2026, 10, 16, 22, 0, 0, 0, time.UTC)
+	us, _ := ParseSymbol("NVDA")
+	tw, _ := ParseSymbol("2330.TW")
+
+	assert.Equal(t, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), us.SessionDate(instant))
+	assert.Equal(t, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), tw.SessionDate(instant))
+}
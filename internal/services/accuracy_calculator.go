@@ -1,25 +1,32 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"sort"
 	"time"
 
 	"stock-prediction-us/internal/models"
+	"stock-prediction-us/internal/services/marketdata"
 )
 
 type AccuracyCalculatorService struct {
-	db *sql.DB
+	db      *sql.DB
+	fxRates *marketdata.FXRates
 }
 
 // NewAccuracyCalculatorService creates a new accuracy calculator service
-func NewAccuracyCalculatorService(db *sql.DB) *AccuracyCalculatorService {
+func NewAccuracyCalculatorService(db *sql.DB, fxRates *marketdata.FXRates) *AccuracyCalculatorService {
 	return &AccuracyCalculatorService{
-		db: db,
+		db:      db,
+		fxRates: fxRates,
 	}
 }
 
-// GetAccuracySummary returns accuracy summary for a specific symbol
+// GetAccuracySummary returns accuracy summary for a specific symbol. The
+// headline figures cover next-session predictions; longer horizons are
+// broken out in Horizons.
 func (s *AccuracyCalculatorService) GetAccuracySummary(symbol string) (*models.PredictionAccuracySummary, error) {
 	query := `
 		SELECT 
@@ -30,13 +37,14 @@ func (s *AccuracyCalculatorService) GetAccuracySummary(symbol string) (*models.P
 			AVG(CASE WHEN confidence IS NOT NULL THEN confidence END) as avg_confidence,
 			MIN(CASE WHEN accuracy_mape IS NOT NULL THEN accuracy_mape END) as best_accuracy,
 			MAX(CASE WHEN accuracy_mape IS NOT NULL THEN accuracy_mape END) as worst_accuracy,
-			MAX(prediction_date) as last_prediction_date
+			MAX(prediction_date) as last_prediction_date,
+			MAX(currency) as currency
 		FROM prediction_tracking
-		WHERE symbol = ?
+		WHERE symbol = ? AND horizon_days = 1
 	`
 
 	var summary models.PredictionAccuracySummary
-	var lastPredictionDateStr sql.NullString
+	var lastPredictionDateStr, currency sql.NullString
 	var avgAccuracyMAPE, directionAccuracy, avgConfidence sql.NullFloat64
 	var bestAccuracy, worstAccuracy sql.NullFloat64
 
@@ -49,6 +57,7 @@ func (s *AccuracyCalculatorService) GetAccuracySummary(symbol string) (*models.P
 		&bestAccuracy,
 		&worstAccuracy,
 		&lastPredictionDateStr,
+		&currency,
 	)
 
 	if err != nil {
@@ -56,6 +65,7 @@ func (s *AccuracyCalculatorService) GetAccuracySummary(symbol string) (*models.P
 	}
 
 	summary.Symbol = symbol
+	summary.Currency = currency.String
 
 	if avgAccuracyMAPE.Valid {
 		summary.AverageAccuracyMAPE = avgAccuracyMAPE.Float64
@@ -83,11 +93,24 @@ func (s *AccuracyCalculatorService) GetAccuracySummary(symbol string) (*models.P
 		}
 	}
 
+	summary.Horizons, err = s.getHorizonAccuracy(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get horizon breakdown: %v", err)
+	}
+
+	summary.Intervals, err = s.getIntervalAccuracy(symbol, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interval coverage: %v", err)
+	}
+
 	return &summary, nil
 }
 
-// GetOverallPerformanceMetrics returns overall performance metrics for all symbols
-func (s *AccuracyCalculatorService) GetOverallPerformanceMetrics() (*models.PredictionPerformanceMetrics, error) {
+// GetOverallPerformanceMetrics returns overall performance metrics for all
+// symbols' next-session predictions. Price errors are reported per currency; the overall mean absolute
+// error is only given in reportingCurrency, converting each prediction at its
+// date's FX rate, or when every prediction shares one currency.
+func (s *AccuracyCalculatorService) GetOverallPerformanceMetrics(ctx context.Context, reportingCurrency string) (*models.PredictionPerformanceMetrics, error) {
 	// Get overall statistics
 	overallQuery := `
 		SELECT 
@@ -97,6 +120,7 @@ func (s *AccuracyCalculatorService) GetOverallPerformanceMetrics() (*models.Pred
 			AVG(CASE WHEN accuracy_mape IS NOT NULL THEN accuracy_mape END) as overall_accuracy_mape,
 			AVG(CASE WHEN direction_correct IS NOT NULL THEN CAST(direction_correct AS FLOAT) END) as overall_direction_accuracy
 		FROM prediction_tracking
+		WHERE horizon_days = 1
 	`
 
 	var metrics models.PredictionPerformanceMetrics
@@ -145,6 +169,36 @@ func (s *AccuracyCalculatorService) GetOverallPerformanceMetrics() (*models.Pred
 		}
 	}
 
+	// Get per-currency breakdown and the converted price error
+	metrics.Currencies, err = s.getCurrencyPerformance()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get currency breakdown: %v", err)
+	}
+
+	switch {
+	case reportingCurrency != "":
+		mae, err := s.convertedMeanAbsoluteError(ctx, reportingCurrency)
+		if err != nil {
+			return nil, err
+		}
+		metrics.ReportingCurrency = reportingCurrency
+		metrics.MeanAbsoluteError = mae
+	case len(metrics.Currencies) == 1:
+		metrics.ReportingCurrency = metrics.Currencies[0].Currency
+		metrics.MeanAbsoluteError = metrics.Currencies[0].MeanAbsoluteError
+	}
+
+	// Get per-horizon breakdown
+	metrics.Horizons, err = s.getHorizonAccuracy("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get horizon breakdown: %v", err)
+	}
+
+	metrics.Intervals, err = s.getIntervalAccuracy("", 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interval coverage: %v", err)
+	}
+
 	// Get symbol summaries
 	symbols, err := s.getDistinctSymbols()
 	if err != nil {
@@ -165,10 +219,7 @@ func (s *AccuracyCalculatorService) GetOverallPerformanceMetrics() (*models.Pred
 // GetAccuracyInRange returns accuracy data for symbols in a date range
 func (s *AccuracyCalculatorService) GetAccuracyInRange(query models.AccuracyRangeQuery) ([]models.PredictionTracking, error) {
 	sqlQuery := `
-		SELECT id, symbol, prediction_date, predicted_price, predicted_direction,
-			   confidence, actual_close, accuracy_mape, direction_correct,
-			   market_was_open, prediction_timestamp, actual_price_timestamp,
-			   created_at, updated_at
+		SELECT ` + predictionTrackingColumns + `
 		FROM prediction_tracking
 		WHERE prediction_date >= ? AND prediction_date <= ?
 		  AND actual_close IS NOT NULL
@@ -197,33 +248,83 @@ func (s *AccuracyCalculatorService) GetAccuracyInRange(query models.AccuracyRang
 
 	var predictions []models.PredictionTracking
 	for rows.Next() {
-		var p models.PredictionTracking
-		var predictionDateStr string
-		var actualPriceTimestamp sql.NullTime
-
-		err := rows.Scan(
-			&p.ID, &p.Symbol, &predictionDateStr, &p.PredictedPrice, &p.PredictedDirection,
-			&p.Confidence, &p.ActualClose, &p.AccuracyMAPE, &p.DirectionCorrect,
-			&p.MarketWasOpen, &p.PredictionTimestamp, &actualPriceTimestamp,
-			&p.CreatedAt, &p.UpdatedAt,
-		)
+		p, err := scanPredictionTracking(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan prediction row: %v", err)
 		}
+		predictions = append(predictions, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read accuracy range: %v", err)
+	}
+	rows.Close()
 
-		p.PredictionDate, err = time.Parse("2006-01-02", predictionDateStr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse prediction date: %v", err)
-		}
+	if err := loadIntervals(s.db, predictions); err != nil {
+		return nil, err
+	}
 
-		if actualPriceTimestamp.Valid {
-			p.ActualPriceTimestamp = &actualPriceTimestamp.Time
+	return predictions, nil
+}
+
+// Residuals returns up to limit of the most recent relative errors,
+// (actual - predicted) / predicted, of scored predictions for a symbol made
+// horizon trading days ahead. It backs empirical prediction intervals.
+func (s *AccuracyCalculatorService) Residuals(ctx context.Context, symbol string, horizon int, limit int) ([]float64, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT residual_pct
+		FROM prediction_tracking
+		WHERE symbol = ? AND horizon_days = ? AND residual_pct IS NOT NULL
+		ORDER BY prediction_date DESC
+		LIMIT ?
+	`, symbol, horizon, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query residuals: %v", err)
+	}
+	defer rows.Close()
+
+	var residuals []float64
+	for rows.Next() {
+		var residualPct float64
+		if err := rows.Scan(&residualPct); err != nil {
+			return nil, fmt.Errorf("failed to scan residual: %v", err)
 		}
+		residuals = append(residuals, residualPct/100)
+	}
 
-		predictions = append(predictions, p)
+	return residuals, rows.Err()
+}
+
+// MemberAccuracy returns, per model, the MAPE of up to window of its most
+// recent scored ensemble member predictions for a symbol. It backs ensemble
+// weights.
+func (s *AccuracyCalculatorService) MemberAccuracy(ctx context.Context, symbol string, window int) (map[string]models.ModelAccuracy, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT model, AVG(accuracy_mape), COUNT(*)
+		FROM (
+			SELECT m.model, m.accuracy_mape,
+				ROW_NUMBER() OVER (PARTITION BY m.model ORDER BY p.prediction_date DESC) as recency
+			FROM prediction_members m
+			JOIN prediction_tracking p ON p.id = m.prediction_id
+			WHERE p.symbol = ? AND p.horizon_days = 1 AND m.accuracy_mape IS NOT NULL
+		)
+		WHERE recency <= ?
+		GROUP BY model
+	`, symbol, window)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query member accuracy: %v", err)
 	}
+	defer rows.Close()
 
-	return predictions, nil
+	accuracy := make(map[string]models.ModelAccuracy)
+	for rows.Next() {
+		var a models.ModelAccuracy
+		if err := rows.Scan(&a.Model, &a.MAPE, &a.Samples); err != nil {
+			return nil, fmt.Errorf("failed to scan member accuracy: %v", err)
+		}
+		accuracy[a.Model] = a
+	}
+
+	return accuracy, rows.Err()
 }
 
 // GetDailyExecutionStatus returns the status of daily prediction executions
@@ -300,7 +401,7 @@ func (s *AccuracyCalculatorService) GetDailyExecutionStatus() (*models.DailyPred
 	return &status, nil
 }
 
-// CalculateAccuracyTrends calculates accuracy trends over time
+// CalculateAccuracyTrends calculates next-session accuracy trends over time
 func (s *AccuracyCalculatorService) CalculateAccuracyTrends(symbol string, days int) (map[string]interface{}, error) {
 	query := `
 		SELECT 
@@ -310,7 +411,7 @@ func (s *AccuracyCalculatorService) CalculateAccuracyTrends(symbol string, days
 			COUNT(*) as total_predictions,
 			COUNT(actual_close) as predictions_with_actual
 		FROM prediction_tracking
-		WHERE symbol = ? AND prediction_date >= date('now', '-' || ? || ' days')
+		WHERE symbol = ? AND horizon_days = 1 AND prediction_date >= date('now', '-' || ? || ' days')
 		GROUP BY DATE(prediction_date)
 		ORDER BY date DESC
 	`
@@ -358,7 +459,7 @@ func (s *AccuracyCalculatorService) CalculateAccuracyTrends(symbol string, days
 	return result, nil
 }
 
-// GetTopPerformingSymbols returns symbols with best accuracy
+// GetTopPerformingSymbols returns symbols with best next-session accuracy
 func (s *AccuracyCalculatorService) GetTopPerformingSymbols(limit int) ([]models.PredictionAccuracySummary, error) {
 	query := `
 		SELECT 
@@ -370,9 +471,10 @@ func (s *AccuracyCalculatorService) GetTopPerformingSymbols(limit int) ([]models
 			AVG(CASE WHEN confidence IS NOT NULL THEN confidence END) as avg_confidence,
 			MIN(CASE WHEN accuracy_mape IS NOT NULL THEN accuracy_mape END) as best_accuracy,
 			MAX(CASE WHEN accuracy_mape IS NOT NULL THEN accuracy_mape END) as worst_accuracy,
-			MAX(prediction_date) as last_prediction_date
+			MAX(prediction_date) as last_prediction_date,
+			MAX(currency) as currency
 		FROM prediction_tracking
-		WHERE actual_close IS NOT NULL
+		WHERE actual_close IS NOT NULL AND horizon_days = 1
 		GROUP BY symbol
 		HAVING COUNT(actual_close) >= 5  -- At least 5 predictions with actual data
 		ORDER BY avg_accuracy_mape ASC, direction_accuracy DESC
@@ -402,6 +504,7 @@ func (s *AccuracyCalculatorService) GetTopPerformingSymbols(limit int) ([]models
 			&bestAccuracy,
 			&worstAccuracy,
 			&lastPredictionDateStr,
+			&summary.Currency,
 		)
 
 		if err != nil {
@@ -462,3 +565,242 @@ func (s *AccuracyCalculatorService) getDistinctSymbols() ([]string, error) {
 
 	return symbols, nil
 }
+
+// getCurrencyPerformance aggregates next-session predictions per quote
+// currency. The mean absolute error is derived from the stored MAPE so it
+// uses the same split-adjusted prediction as the accuracy figures.
+func (s *AccuracyCalculatorService) getCurrencyPerformance() ([]models.CurrencyPerformance, error) {
+	query := `
+		SELECT 
+			currency,
+			COUNT(DISTINCT symbol) as total_symbols,
+			COUNT(*) as total_predictions,
+			COUNT(actual_close) as predictions_with_actual,
+			AVG(CASE WHEN accuracy_mape IS NOT NULL THEN accuracy_mape END) as accuracy_mape,
+			AVG(CASE WHEN direction_correct IS NOT NULL THEN CAST(direction_correct AS FLOAT) END) as direction_accuracy,
+			AVG(CASE WHEN accuracy_mape IS NOT NULL AND actual_close IS NOT NULL THEN accuracy_mape * actual_close / 100 END) as mean_absolute_error
+		FROM prediction_tracking
+		WHERE horizon_days = 1
+		GROUP BY currency
+		ORDER BY currency
+	`
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	currencies := []models.CurrencyPerformance{}
+	for rows.Next() {
+		var performance models.CurrencyPerformance
+		var accuracyMAPE, directionAccuracy, meanAbsoluteError sql.NullFloat64
+
+		err := rows.Scan(
+			&performance.Currency,
+			&performance.TotalSymbols,
+			&performance.TotalPredictions,
+			&performance.PredictionsWithActual,
+			&accuracyMAPE,
+			&directionAccuracy,
+			&meanAbsoluteError,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		performance.AccuracyMAPE = accuracyMAPE.Float64
+		performance.DirectionAccuracy = directionAccuracy.Float64 * 100
+		if meanAbsoluteError.Valid {
+			mae := meanAbsoluteError.Float64
+			performance.MeanAbsoluteError = &mae
+		}
+
+		currencies = append(currencies, performance)
+	}
+
+	return currencies, rows.Err()
+}
+
+// getHorizonAccuracy aggregates predictions per horizon, for one symbol or
+// all of them when symbol is empty
+func (s *AccuracyCalculatorService) getHorizonAccuracy(symbol string) ([]models.HorizonAccuracy, error) {
+	where, args := "", []interface{}{}
+	if symbol != "" {
+		where, args = "WHERE symbol = ?", append(args, symbol)
+	}
+
+	query := `
+		SELECT 
+			horizon_days,
+			COUNT(*) as total_predictions,
+			COUNT(actual_close) as predictions_with_actual,
+			AVG(CASE WHEN accuracy_mape IS NOT NULL THEN accuracy_mape END) as accuracy_mape,
+			AVG(CASE WHEN direction_correct IS NOT NULL THEN CAST(direction_correct AS FLOAT) END) as direction_accuracy
+		FROM prediction_tracking
+		` + where + `
+		GROUP BY horizon_days
+		ORDER BY horizon_days
+	`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	horizons := []models.HorizonAccuracy{}
+	for rows.Next() {
+		var horizon models.HorizonAccuracy
+		var accuracyMAPE, directionAccuracy sql.NullFloat64
+
+		err := rows.Scan(
+			&horizon.HorizonDays,
+			&horizon.TotalPredictions,
+			&horizon.PredictionsWithActual,
+			&accuracyMAPE,
+			&directionAccuracy,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		horizon.AccuracyMAPE = accuracyMAPE.Float64
+		horizon.DirectionAccuracy = directionAccuracy.Float64 * 100
+		horizons = append(horizons, horizon)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	// Bands widen with the horizon, so their coverage is reported per horizon too
+	for i := range horizons {
+		horizons[i].Intervals, err = s.getIntervalAccuracy(symbol, horizons[i].HorizonDays)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return horizons, nil
+}
+
+// getIntervalAccuracy aggregates prediction intervals per nominal coverage,
+// optionally for one symbol and one horizon
+func (s *AccuracyCalculatorService) getIntervalAccuracy(symbol string, horizon int) ([]models.IntervalAccuracy, error) {
+	where, args := "WHERE 1=1", []interface{}{}
+	if symbol != "" {
+		where += " AND p.symbol = ?"
+		args = append(args, symbol)
+	}
+	if horizon > 0 {
+		where += " AND p.horizon_days = ?"
+		args = append(args, horizon)
+	}
+
+	query := `
+		SELECT 
+			i.coverage,
+			COUNT(*) as total_intervals,
+			COUNT(i.covered) as intervals_with_actual,
+			AVG(CASE WHEN i.covered IS NOT NULL THEN CAST(i.covered AS FLOAT) END) as observed_coverage,
+			AVG(CASE WHEN p.predicted_price > 0 THEN CAST(i.upper_price - i.lower_price AS FLOAT) / p.predicted_price END) as average_width
+		FROM prediction_intervals i
+		JOIN prediction_tracking p ON p.id = i.prediction_id
+		` + where + `
+		GROUP BY i.coverage
+		ORDER BY i.coverage
+	`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	intervals := []models.IntervalAccuracy{}
+	for rows.Next() {
+		var interval models.IntervalAccuracy
+		var observedCoverage, averageWidth sql.NullFloat64
+
+		err := rows.Scan(
+			&interval.Coverage,
+			&interval.TotalIntervals,
+			&interval.IntervalsWithActual,
+			&observedCoverage,
+			&averageWidth,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		interval.ObservedCoverage = observedCoverage.Float64 * 100
+		interval.AverageWidthPercent = averageWidth.Float64 * 100
+		intervals = append(intervals, interval)
+	}
+
+	return intervals, rows.Err()
+}
+
+// convertedMeanAbsoluteError averages absolute price errors after converting
+// each into currency at its prediction date's FX rate
+func (s *AccuracyCalculatorService) convertedMeanAbsoluteError(ctx context.Context, currency string) (*float64, error) {
+	query := `
+		SELECT currency, prediction_date, accuracy_mape * actual_close / 100
+		FROM prediction_tracking
+		WHERE accuracy_mape IS NOT NULL AND actual_close IS NOT NULL AND horizon_days = 1
+	`
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price errors: %v", err)
+	}
+	defer rows.Close()
+
+	type priceError struct {
+		currency string
+		date     string
+		amount   float64
+	}
+	var priceErrors []priceError
+	for rows.Next() {
+		var e priceError
+		if err := rows.Scan(&e.currency, &e.date, &e.amount); err != nil {
+			return nil, fmt.Errorf("failed to scan price error: %v", err)
+		}
+		priceErrors = append(priceErrors, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read price errors: %v", err)
+	}
+	rows.Close()
+
+	if len(priceErrors) == 0 {
+		return nil, nil
+	}
+
+	// Convert in date order so FX series are fetched once per pair
+	sort.Slice(priceErrors, func(i, j int) bool { return priceErrors[i].date < priceErrors[j].date })
+
+	total := 0.0
+	for _, e := range priceErrors {
+		amount := e.amount
+		if e.currency != currency {
+			if s.fxRates == nil {
+				return nil, fmt.Errorf("currency conversion is not available")
+			}
+			date, err := time.Parse("2006-01-02", e.date[:min(len(e.date), 10)])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse prediction date %q: %v", e.date, err)
+			}
+			amount, err = s.fxRates.Convert(ctx, amount, e.currency, currency, date)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert %s price error: %w", e.currency, err)
+			}
+		}
+		total += amount
+	}
+
+	mae := total / float64(len(priceErrors))
+	return &mae, nil
+}
@@ -0,0 +1,307 @@
+// Package events keeps the corporate event calendar: earnings releases,
+// ex-dividend dates and splits loaded from the market data provider or
+// imported from CSV and iCalendar files. Predictions whose target date falls
+// on or just after an event are flagged through EventRisk.
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"stock-prediction-us/internal/models"
+	"stock-prediction-us/internal/services/marketdata"
+)
+
+// recentActionDays is how far back splits and dividends are copied into the
+// calendar on refresh, enough to cover the post-event window
+const recentActionDays = 30
+
+// TradingCalendar reports US market days; MarketCalendarService implements it
+type TradingCalendar interface {
+	IsMarketOpen(date time.Time) (bool, error)
+}
+
+// Settings configures event flagging
+type Settings struct {
+	PostEventDays    int           // Trading days after an event that are still flagged
+	DownWeight       bool          // Scale confidence of flagged predictions
+	ConfidenceFactor float64       // Scale applied when DownWeight is set
+	RefreshInterval  time.Duration // Minimum time between provider refreshes per symbol
+}
+
+// Calendar stores corporate events in SQLite and refreshes them from the
+// market data provider on demand
+type Calendar struct {
+	db       *sql.DB
+	provider marketdata.MarketDataProvider // May be nil for import-only calendars
+	clock    TradingCalendar               // May be nil; weekdays are trading days
+	settings Settings
+	logger   *logrus.Logger
+	now      func() time.Time
+
+	mutex       sync.Mutex
+	lastRefresh map[string]time.Time
+}
+
+// NewCalendar creates an event calendar
+func NewCalendar(db *sql.DB, provider marketdata.MarketDataProvider, clock TradingCalendar, settings Settings, logger *logrus.Logger) *Calendar {
+	if settings.PostEventDays < 0 {
+		settings.PostEventDays = 0
+	}
+	return &Calendar{
+		db:          db,
+		provider:    provider,
+		clock:       clock,
+		settings:    settings,
+		logger:      logger,
+		now:         time.Now,
+		lastRefresh: make(map[string]time.Time),
+	}
+}
+
+// Upcoming returns stored events for a symbol from today through the next
+// N days, refreshing from the provider first when due. A failed refresh is
+// logged and stored events are still served.
+func (c *Calendar) Upcoming(ctx context.Context, symbol string, days int) ([]models.CorporateEvent, error) {
+	c.ensureRefreshed(ctx, symbol)
+
+	today := dateOf(c.now())
+	return c.Events(symbol, today, today.AddDate(0, 0, days))
+}
+
+// Risk returns the events on the target date or within PostEventDays
+// trading days before it. The risk carries the confidence factor to apply
+// when down-weighting is enabled.
+func (c *Calendar) Risk(ctx context.Context, symbol string, target time.Time) (*models.EventRisk, error) {
+	c.ensureRefreshed(ctx, symbol)
+
+	target = dateOf(target)
+	windowStart := target
+	for i := 0; i < c.settings.PostEventDays; i++ {
+		windowStart = c.previousTradingDay(windowStart)
+	}
+
+	events, err := c.Events(symbol, windowStart, target)
+	if err != nil {
+		return nil, err
+	}
+
+	risk := &models.EventRisk{
+		TargetDate: target,
+		Flagged:    len(events) > 0,
+		Events:     events,
+	}
+	if risk.Flagged && c.settings.DownWeight {
+		risk.ConfidenceFactor = c.settings.ConfidenceFactor
+	}
+	return risk, nil
+}
+
+// Events returns stored events for a symbol with a date in [from, to]
+func (c *Calendar) Events(symbol string, from, to time.Time) ([]models.CorporateEvent, error) {
+	rows, err := c.db.Query(`
+		SELECT symbol, event_date, event_type, timing, description, source, updated_at
+		FROM corporate_events
+		WHERE symbol = ? AND event_date >= ? AND event_date <= ?
+		ORDER BY event_date ASC, event_type ASC
+	`, symbol, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query corporate events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.CorporateEvent{}
+	for rows.Next() {
+		var event models.CorporateEvent
+		var dateStr string
+		var timing, description sql.NullString
+		if err := rows.Scan(&event.Symbol, &dateStr, &event.Type, &timing, &description, &event.Source, &event.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan corporate event: %w", err)
+		}
+		event.Date, err = parseDate(dateStr)
+		if err != nil {
+			return nil, err
+		}
+		event.Timing = timing.String
+		event.Description = description.String
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// Import stores events from a file import, replacing any stored event of
+// the same symbol, date and type. Returns the number of events written.
+func (c *Calendar) Import(events []models.CorporateEvent) (int, error) {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin event import: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := upsertEvents(tx, events, c.now()); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit event import: %w", err)
+	}
+	return len(events), nil
+}
+
+// Refresh loads upcoming events and recent splits and dividends from the
+// provider. Upcoming events previously stored from the provider are
+// replaced, so a moved earnings date does not leave the old one behind.
+func (c *Calendar) Refresh(ctx context.Context, symbol string) (int, error) {
+	if c.provider == nil {
+		return 0, nil
+	}
+
+	var events []models.CorporateEvent
+	var failures []string
+	upcomingLoaded := false
+
+	if calendar, ok := c.provider.(marketdata.EventCalendar); ok {
+		upcoming, err := calendar.FetchUpcomingEvents(ctx, symbol)
+		if err != nil {
+			failures = append(failures, err.Error())
+		} else {
+			events = append(events, upcoming...)
+			upcomingLoaded = true
+		}
+	}
+
+	actions, err := c.provider.FetchCorporateActions(ctx, symbol, recentActionDays)
+	if err != nil {
+		failures = append(failures, err.Error())
+	} else {
+		events = append(events, models.EventsFromCorporateActions(actions, c.provider.Name())...)
+	}
+
+	if len(events) == 0 && len(failures) > 0 {
+		return 0, fmt.Errorf("failed to refresh events for %s: %s", symbol, strings.Join(failures, "; "))
+	}
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin event refresh: %w", err)
+	}
+	defer tx.Rollback()
+
+	if upcomingLoaded {
+		_, err := tx.Exec(`DELETE FROM corporate_events WHERE symbol = ? AND source = ? AND event_date >= ?`,
+			symbol, c.provider.Name(), dateOf(c.now()).Format("2006-01-02"))
+		if err != nil {
+			return 0, fmt.Errorf("failed to clear upcoming events for %s: %w", symbol, err)
+		}
+	}
+	if err := upsertEvents(tx, events, c.now()); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit event refresh: %w", err)
+	}
+
+	return len(events), nil
+}
+
+// ensureRefreshed refreshes a symbol unless it was refreshed recently.
+// Failures are logged; the attempt still counts so a down provider is not
+// asked again on every prediction.
+func (c *Calendar) ensureRefreshed(ctx context.Context, symbol string) {
+	if c.provider == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	last, ok := c.lastRefresh[symbol]
+	if ok && c.now().Sub(last) < c.settings.RefreshInterval {
+		c.mutex.Unlock()
+		return
+	}
+	c.lastRefresh[symbol] = c.now()
+	c.mutex.Unlock()
+
+	if _, err := c.Refresh(ctx, symbol); err != nil {
+		c.logger.WithFields(logrus.Fields{
+			"symbol": symbol,
+			"error":  err,
+		}).Warn("Corporate event refresh failed, serving stored events")
+	}
+}
+
+// IsTradingDay reports whether the US market is open on a date
+func (c *Calendar) IsTradingDay(day time.Time) bool {
+	if c.clock != nil {
+		if open, err := c.clock.IsMarketOpen(day); err == nil {
+			return open
+		}
+	}
+	return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday
+}
+
+// previousTradingDay returns the last trading day before a date
+func (c *Calendar) previousTradingDay(before time.Time) time.Time {
+	day := before
+	for i := 0; i < 10; i++ {
+		day = day.AddDate(0, 0, -1)
+		if c.IsTradingDay(day) {
+			return day
+		}
+	}
+	return day
+}
+
+// upsertEvents writes events within a transaction
+func upsertEvents(tx *sql.Tx, events []models.CorporateEvent, now time.Time) error {
+	stmt, err := tx.Prepare(`
+		INSERT INTO corporate_events (symbol, event_date, event_type, timing, description, source, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(symbol, event_date, event_type) DO UPDATE SET
+			timing = excluded.timing,
+			description = excluded.description,
+			source = excluded.source,
+			updated_at = excluded.updated_at
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare event upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, event := range events {
+		_, err := stmt.Exec(event.Symbol, dateOf(event.Date).Format("2006-01-02"), event.Type,
+			nullableString(event.Timing), nullableString(event.Description), event.Source, now.UTC())
+		if err != nil {
+			return fmt.Errorf("failed to store %s event for %s: %w", event.Type, event.Symbol, err)
+		}
+	}
+	return nil
+}
+
+// nullableString stores empty strings as NULL
+func nullableString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// dateOf truncates a time to its calendar date in UTC
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// parseDate parses a stored DATE column, which the driver may return as a
+// plain date or a timestamp
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return dateOf(t), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("failed to parse event date '%s'", value)
+}
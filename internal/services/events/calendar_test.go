@@ -0,0 +1,153 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"stock-prediction-us/internal/models"
+)
+
+// eventsProvider serves fixed upcoming events and corporate actions
+type eventsProvider struct {
+	upcoming []models.CorporateEvent
+	actions  []models.CorporateAction
+	err      error
+	calls    int
+}
+
+func (p *eventsProvider) Name() string { return "fixture" }
+
+func (p *eventsProvider) FetchLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	return 0, fmt.Errorf("not used")
+}
+
+func (p *eventsProvider) FetchStockData(ctx context.Context, symbol string, period string, interval models.Interval) ([]float64, error) {
+	return nil, fmt.Errorf("not used")
+}
+
+func (p *eventsProvider) FetchHistoricalData(ctx context.Context, symbol string, days int, interval models.Interval) ([]models.StockData, error) {
+	return nil, fmt.Errorf("not used")
+}
+
+func (p *eventsProvider) FetchCorporateActions(ctx context.Context, symbol string, days int) ([]models.CorporateAction, error) {
+	return p.actions, p.err
+}
+
+func (p *eventsProvider) FetchUpcomingEvents(ctx context.Context, symbol string) ([]models.CorporateEvent, error) {
+	p.calls++
+	return p.upcoming, p.err
+}
+
+func (p *eventsProvider) HealthCheck(ctx context.Context) error { return p.err }
+
+func newTestCalendar(t *testing.T, provider *eventsProvider, settings Settings, now time.Time) *Calendar {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	for _, name := range []string{"001_prediction_tracking.sql", "006_prediction_currency.sql", "007_corporate_events.sql"} {
+		migration, err := os.ReadFile("../../database/migrations/" + name)
+		require.NoError(t, err)
+		_, err = db.Exec(string(migration))
+		require.NoError(t, err)
+	}
+
+	calendar := NewCalendar(db, provider, nil, settings, logrus.New())
+	calendar.now = func() time.Time { return now }
+	return calendar
+}
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCalendarRiskFlagsTargetAndFollowingDays(t *testing.T) {
+	// Thursday 2026-11-19 after the close
+	provider := &eventsProvider{upcoming: []models.CorporateEvent{
+		{Symbol: "NVDA", Date: day(2026, 11, 19), Type: models.EventEarnings, Timing: models.TimingAfterClose, Source: "fixture"},
+	}}
+	calendar := newTestCalendar(t, provider, Settings{PostEventDays: 1, DownWeight: true, ConfidenceFactor: 0.7, RefreshInterval: time.Hour}, day(2026, 11, 16))
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		target  time.Time
+		flagged bool
+	}{
+		{day(2026, 11, 18), false},
+		{day(2026, 11, 19), true},
+		{day(2026, 11, 20), true},
+		{day(2026, 11, 23), false},
+	} {
+		risk, err := calendar.Risk(ctx, "NVDA", tc.target)
+		require.NoError(t, err)
+		assert.Equal(t, tc.flagged, risk.Flagged, tc.target.Format("2006-01-02"))
+		if tc.flagged {
+			assert.Equal(t, 0.7, risk.ConfidenceFactor)
+			assert.Equal(t, []string{models.EventEarnings}, risk.EventTypes())
+		} else {
+			assert.Zero(t, risk.ConfidenceFactor)
+		}
+	}
+
+	// An event on Friday still flags the following Monday
+	_, err := calendar.Import([]models.CorporateEvent{{Symbol: "NVDA", Date: day(2026, 11, 27), Type: models.EventExDividend, Source: FormatCSV}})
+	require.NoError(t, err)
+	risk, err := calendar.Risk(ctx, "NVDA", day(2026, 11, 30))
+	require.NoError(t, err)
+	assert.True(t, risk.Flagged)
+
+	assert.Equal(t, 1, provider.calls, "refresh interval limits provider calls")
+}
+
+func TestCalendarRefreshReplacesMovedEvents(t *testing.T) {
+	provider := &eventsProvider{upcoming: []models.CorporateEvent{
+		{Symbol: "AAPL", Date: day(2026, 10, 28), Type: models.EventEarnings, Source: "fixture"},
+	}}
+	calendar := newTestCalendar(t, provider, Settings{}, day(2026, 10, 1))
+	ctx := context.Background()
+
+	_, err := calendar.Refresh(ctx, "AAPL")
+	require.NoError(t, err)
+
+	provider.upcoming[0].Date = day(2026, 10, 30)
+	provider.actions = []models.CorporateAction{
+		{Symbol: "AAPL", Date: day(2026, 9, 25), Type: models.ActionDividend, Amount: 0.26},
+	}
+	_, err = calendar.Refresh(ctx, "AAPL")
+	require.NoError(t, err)
+
+	events, err := calendar.Events("AAPL", day(2026, 9, 1), day(2026, 12, 31))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, models.EventExDividend, events[0].Type)
+	assert.Equal(t, day(2026, 10, 30), events[1].Date)
+
+	// Upcoming only reaches forward from today
+	upcoming, err := calendar.Upcoming(ctx, "AAPL", 90)
+	require.NoError(t, err)
+	require.Len(t, upcoming, 1)
+	assert.Equal(t, models.EventEarnings, upcoming[0].Type)
+}
+
+func TestCalendarServesStoredEventsWhenProviderFails(t *testing.T) {
+	provider := &eventsProvider{err: fmt.Errorf("upstream down")}
+	calendar := newTestCalendar(t, provider, Settings{}, day(2026, 10, 1))
+
+	_, err := calendar.Import([]models.CorporateEvent{{Symbol: "TSLA", Date: day(2026, 10, 21), Type: models.EventEarnings, Source: FormatICS}})
+	require.NoError(t, err)
+
+	events, err := calendar.Upcoming(context.Background(), "TSLA", 30)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, FormatICS, events[0].Source)
+}
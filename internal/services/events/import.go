@@ -0,0 +1,297 @@
+package events
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"stock-prediction-us/internal/models"
+)
+
+// Import formats
+const (
+	FormatCSV = "csv"
+	FormatICS = "ics"
+)
+
+// ParseCSV reads events from a CSV file with a header row. The symbol, date
+// (YYYY-MM-DD) and type columns are required; timing and description are
+// optional. Column names are matched case-insensitively.
+func ParseCSV(r io.Reader) ([]models.CorporateEvent, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"symbol", "date", "type"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV is missing the %s column", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var events []models.CorporateEvent
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		symbol, err := models.NormalizeSymbol(field(record, "symbol"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		date, err := time.Parse("2006-01-02", field(record, "date"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid date %q (use YYYY-MM-DD)", line, field(record, "date"))
+		}
+		eventType, err := models.ParseEventType(field(record, "type"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		events = append(events, models.CorporateEvent{
+			Symbol:      symbol,
+			Date:        date,
+			Type:        eventType,
+			Timing:      models.ParseEventTiming(field(record, "timing")),
+			Description: field(record, "description"),
+			Source:      FormatCSV,
+		})
+	}
+
+	return events, nil
+}
+
+// ParseICS reads VEVENT entries from an iCalendar file such as an exported
+// earnings calendar. The symbol comes from an X-SYMBOL property, the leading
+// word of the SUMMARY ("NVDA Q3 Earnings") or defaultSymbol, in that order;
+// the type from CATEGORIES or the SUMMARY. Events without a recognisable
+// type or symbol are skipped and counted.
+func ParseICS(r io.Reader, defaultSymbol string) ([]models.CorporateEvent, int, error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var events []models.CorporateEvent
+	skipped := 0
+	var current map[string]icsProperty
+	for _, line := range lines {
+		switch {
+		case strings.EqualFold(line, "BEGIN:VEVENT"):
+			current = make(map[string]icsProperty)
+		case strings.EqualFold(line, "END:VEVENT"):
+			if current == nil {
+				continue
+			}
+			event, ok := icsEvent(current, defaultSymbol)
+			if ok {
+				events = append(events, event)
+			} else {
+				skipped++
+			}
+			current = nil
+		case current != nil:
+			property := parseICSProperty(line)
+			if _, seen := current[property.name]; !seen {
+				current[property.name] = property
+			}
+		}
+	}
+
+	return events, skipped, nil
+}
+
+// icsProperty is one content line: NAME;PARAM=VALUE:value
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// unfoldICS joins continuation lines, which start with a space or tab
+func unfoldICS(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	return lines, nil
+}
+
+// parseICSProperty splits a content line into name, parameters and value
+func parseICSProperty(line string) icsProperty {
+	property := icsProperty{params: make(map[string]string)}
+	head, value, _ := strings.Cut(line, ":")
+	parts := strings.Split(head, ";")
+	property.name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		if key, val, ok := strings.Cut(param, "="); ok {
+			property.params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+		}
+	}
+	property.value = strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+	return property
+}
+
+// icsEvent builds an event from a VEVENT's properties
+func icsEvent(properties map[string]icsProperty, defaultSymbol string) (models.CorporateEvent, bool) {
+	summary := properties["SUMMARY"].value
+	description := properties["DESCRIPTION"].value
+
+	start, ok := properties["DTSTART"]
+	if !ok {
+		return models.CorporateEvent{}, false
+	}
+	date, timing, err := icsDate(start)
+	if err != nil {
+		return models.CorporateEvent{}, false
+	}
+
+	eventType, err := models.ParseEventType(properties["CATEGORIES"].value)
+	if err != nil {
+		if eventType, err = models.ParseEventType(summary); err != nil {
+			return models.CorporateEvent{}, false
+		}
+	}
+
+	symbol := icsSymbol(properties["X-SYMBOL"].value, summary, defaultSymbol)
+	if symbol == "" {
+		return models.CorporateEvent{}, false
+	}
+
+	if text := timingFromText(summary + " " + description); text != "" {
+		timing = text
+	}
+	if eventType != models.EventEarnings {
+		timing = ""
+	}
+
+	return models.CorporateEvent{
+		Symbol:      symbol,
+		Date:        date,
+		Type:        eventType,
+		Timing:      timing,
+		Description: summary,
+		Source:      FormatICS,
+	}, true
+}
+
+// icsDate returns the exchange-local date of a DTSTART, and the earnings
+// timing implied by a start time. UTC times are read in New York time.
+func icsDate(property icsProperty) (time.Time, string, error) {
+	value := property.value
+	if len(value) == 8 || property.params["VALUE"] == "DATE" {
+		date, err := time.Parse("20060102", value[:min(8, len(value))])
+		return date, "", err
+	}
+
+	location := time.UTC
+	if strings.HasSuffix(value, "Z") {
+		if ny, err := time.LoadLocation("America/New_York"); err == nil {
+			location = ny
+		}
+	} else if tzid := property.params["TZID"]; tzid != "" {
+		if loc, err := time.LoadLocation(tzid); err == nil {
+			location = loc
+		}
+	}
+
+	var start time.Time
+	var err error
+	if strings.HasSuffix(value, "Z") {
+		start, err = time.Parse("20060102T150405Z", value)
+		start = start.In(location)
+	} else {
+		start, err = time.ParseInLocation("20060102T150405", value, location)
+	}
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	timing := ""
+	minutes := start.Hour()*60 + start.Minute()
+	switch {
+	case minutes < 9*60+30:
+		timing = models.TimingBeforeOpen
+	case minutes >= 16*60:
+		timing = models.TimingAfterClose
+	}
+	return dateOf(start), timing, nil
+}
+
+// icsSymbol picks the event's symbol from an explicit property, the first
+// word of the summary, or the default
+func icsSymbol(explicit, summary, defaultSymbol string) string {
+	if symbol, err := models.NormalizeSymbol(explicit); err == nil {
+		return symbol
+	}
+	if fields := strings.Fields(summary); len(fields) > 1 {
+		candidate := strings.Trim(fields[0], ":()$-")
+		if candidate == strings.ToUpper(candidate) {
+			if symbol, err := models.NormalizeSymbol(candidate); err == nil {
+				return symbol
+			}
+		}
+	}
+	if symbol, err := models.NormalizeSymbol(defaultSymbol); err == nil {
+		return symbol
+	}
+	return ""
+}
+
+// timingFromText detects "AMC"/"BMO" or "after close"/"before open" markers
+// in free text
+func timingFromText(text string) string {
+	lower := strings.ToLower(text)
+	words := strings.FieldsFunc(lower, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		switch word {
+		case "amc":
+			return models.TimingAfterClose
+		case "bmo":
+			return models.TimingBeforeOpen
+		}
+	}
+	switch {
+	case strings.Contains(lower, "after close") || strings.Contains(lower, "after market"):
+		return models.TimingAfterClose
+	case strings.Contains(lower, "before open") || strings.Contains(lower, "before market"):
+		return models.TimingBeforeOpen
+	default:
+		return ""
+	}
+}
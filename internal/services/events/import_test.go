@@ -0,0 +1,86 @@
+package events
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-prediction-us/internal/models"
+)
+
+func TestParseCSV(t *testing.T) {
+	events, err := ParseCSV(strings.NewReader(`Symbol,Date,Type,Timing,Description
+nvda,2026-11-19,Earnings,after close,Q3 FY27
+brk.b,2026-11-05,ex-dividend,,
+`))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	assert.Equal(t, "NVDA", events[0].Symbol)
+	assert.Equal(t, time.Date(2026, 11, 19, 0, 0, 0, 0, time.UTC), events[0].Date)
+	assert.Equal(t, models.EventEarnings, events[0].Type)
+	assert.Equal(t, models.TimingAfterClose, events[0].Timing)
+	assert.Equal(t, "Q3 FY27", events[0].Description)
+	assert.Equal(t, FormatCSV, events[0].Source)
+
+	assert.Equal(t, "BRK-B", events[1].Symbol)
+	assert.Equal(t, models.EventExDividend, events[1].Type)
+	assert.Empty(t, events[1].Timing)
+}
+
+func TestParseCSVReportsLine(t *testing.T) {
+	_, err := ParseCSV(strings.NewReader("symbol,date,type\nNVDA,2026-11-19,earnings\nNVDA,19/11/2026,earnings\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 3")
+
+	_, err = ParseCSV(strings.NewReader("symbol,type\nNVDA,earnings\n"))
+	assert.ErrorContains(t, err, "missing the date column")
+}
+
+func TestParseICS(t *testing.T) {
+	calendar := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20261119",
+		"SUMMARY:NVDA Q3 Earnings (AMC)",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART:20261028T113000Z",
+		"SUMMARY:Quarterly results call, long summary folded",
+		"  across lines",
+		"CATEGORIES:EARNINGS",
+		"X-SYMBOL:aapl",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20261101",
+		"SUMMARY:Ex-Dividend",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20261102",
+		"SUMMARY:Team offsite",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	events, skipped, err := ParseICS(strings.NewReader(calendar), "msft")
+	require.NoError(t, err)
+	assert.Equal(t, 1, skipped) // The offsite has no event type
+	require.Len(t, events, 3)
+
+	assert.Equal(t, "NVDA", events[0].Symbol)
+	assert.Equal(t, time.Date(2026, 11, 19, 0, 0, 0, 0, time.UTC), events[0].Date)
+	assert.Equal(t, models.EventEarnings, events[0].Type)
+	assert.Equal(t, models.TimingAfterClose, events[0].Timing)
+
+	// 11:30 UTC is 07:30 in New York, before the open
+	assert.Equal(t, "AAPL", events[1].Symbol)
+	assert.Equal(t, time.Date(2026, 10, 28, 0, 0, 0, 0, time.UTC), events[1].Date)
+	assert.Equal(t, models.TimingBeforeOpen, events[1].Timing)
+	assert.Equal(t, "Quarterly results call, long summary folded across lines", events[1].Description)
+
+	assert.Equal(t, "MSFT", events[2].Symbol)
+	assert.Equal(t, models.EventExDividend, events[2].Type)
+}
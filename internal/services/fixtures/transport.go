@@ -0,0 +1,275 @@
+// Package fixtures records HTTP exchanges with market data vendors to disk
+// and replays them, so the service can run and be tested without network
+// access.
+package fixtures
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mode selects how the transport treats outgoing requests
+type Mode string
+
+const (
+	ModeLive   Mode = "live"   // Pass requests through untouched
+	ModeRecord Mode = "record" // Pass requests through and save each exchange
+	ModeReplay Mode = "replay" // Serve saved exchanges; never touch the network
+)
+
+// ParseMode parses a fixture mode, defaulting to live
+func ParseMode(s string) (Mode, error) {
+	switch Mode(strings.ToLower(strings.TrimSpace(s))) {
+	case "", ModeLive:
+		return ModeLive, nil
+	case ModeRecord:
+		return ModeRecord, nil
+	case ModeReplay:
+		return ModeReplay, nil
+	default:
+		return "", fmt.Errorf("unsupported fixture mode: %s (valid options: live, record, replay)", s)
+	}
+}
+
+// ErrNoFixture is matched by errors returned for requests with no recording
+var ErrNoFixture = errors.New("no recorded fixture")
+
+// MissError reports a replayed request that was never recorded
+type MissError struct {
+	Key  string // Normalised request, e.g. "GET /v8/finance/chart/NVDA?range=1mo"
+	Path string // File the recording was expected in
+}
+
+func (e *MissError) Error() string {
+	return fmt.Sprintf("%v for %s (expected %s; run once with API_FIXTURE_MODE=record to capture it)", ErrNoFixture, e.Key, e.Path)
+}
+
+// Is makes errors.Is(err, ErrNoFixture) match
+func (e *MissError) Is(target error) bool {
+	return target == ErrNoFixture
+}
+
+// ClientError marks misses as caused by the request, so callers neither
+// retry them nor count them against upstream health
+func (e *MissError) ClientError() bool {
+	return true
+}
+
+// ignoredParams are query parameters that change between otherwise
+// identical requests and are left out of fixture keys
+var ignoredParams = map[string]bool{
+	"crumb":      true,
+	"corsDomain": true,
+	"_":          true,
+}
+
+// recordedHeaders are the response headers kept in fixtures
+var recordedHeaders = []string{"Content-Type", "Retry-After"}
+
+// Fixture is one recorded request/response pair as stored on disk
+type Fixture struct {
+	Request struct {
+		Method string `json:"method"`
+		URL    string `json:"url"`
+		Key    string `json:"key"`
+	} `json:"request"`
+	Response struct {
+		StatusCode int               `json:"status_code"`
+		Headers    map[string]string `json:"headers,omitempty"`
+		Body       string            `json:"body"`
+	} `json:"response"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// Transport is an http.RoundTripper that records or replays exchanges in a
+// fixture directory, one JSON file per normalised request
+type Transport struct {
+	mode Mode
+	dir  string
+	base http.RoundTripper
+
+	mutex sync.Mutex // serialises fixture writes
+}
+
+// NewTransport creates a transport over base (http.DefaultTransport if nil)
+func NewTransport(mode Mode, dir string, base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{
+		mode: mode,
+		dir:  dir,
+		base: base,
+	}
+}
+
+// Mode returns the transport mode
+func (t *Transport) Mode() Mode {
+	return t.mode
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch t.mode {
+	case ModeReplay:
+		return t.replay(req)
+	case ModeRecord:
+		return t.record(req)
+	default:
+		return t.base.RoundTrip(req)
+	}
+}
+
+// replay serves the recorded response for req
+func (t *Transport) replay(req *http.Request) (*http.Response, error) {
+	key := RequestKey(req)
+	path := t.fixturePath(req, key)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, &MissError{Key: key, Path: path}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture %s: %w", path, err)
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	if fixture.Request.Key != key {
+		return nil, fmt.Errorf("fixture %s was recorded for %s, not %s", path, fixture.Request.Key, key)
+	}
+
+	header := make(http.Header)
+	for name, value := range fixture.Response.Headers {
+		header.Set(name, value)
+	}
+	body := []byte(fixture.Response.Body)
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Response.StatusCode, http.StatusText(fixture.Response.StatusCode)),
+		StatusCode:    fixture.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// record performs req and saves the exchange before returning it
+func (t *Transport) record(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response for recording: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+
+	key := RequestKey(req)
+	var fixture Fixture
+	fixture.Request.Method = req.Method
+	fixture.Request.URL = req.URL.Redacted()
+	fixture.Request.Key = key
+	fixture.Response.StatusCode = resp.StatusCode
+	fixture.Response.Body = string(body)
+	fixture.Response.Headers = make(map[string]string)
+	for _, name := range recordedHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			fixture.Response.Headers[name] = value
+		}
+	}
+	fixture.RecordedAt = time.Now().UTC()
+
+	if err := t.write(t.fixturePath(req, key), &fixture); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// write stores a fixture atomically so concurrent readers never see a partial file
+func (t *Transport) write(path string, fixture *Fixture) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if err := os.MkdirAll(t.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write fixture %s: %w", path, err)
+	}
+	return os.Rename(tmp, path)
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9.=-]+`)
+
+// fixturePath names a fixture after its URL path plus a hash of the full key,
+// e.g. v8_finance_chart_NVDA-1a2b3c4d5e6f.json
+func (t *Transport) fixturePath(req *http.Request, key string) string {
+	name := unsafeFileChars.ReplaceAllString(strings.Trim(req.URL.EscapedPath(), "/"), "_")
+	if len(name) > 80 {
+		name = name[:80]
+	}
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(t.dir, name+"-"+hex.EncodeToString(sum[:6])+".json")
+}
+
+// RequestKey normalises a request to method, path and sorted query. The host
+// is left out so query1/query2 mirrors and test servers share recordings, and
+// volatile parameters such as crumb are dropped.
+func RequestKey(req *http.Request) string {
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for name := range query {
+		if !ignoredParams[name] {
+			keys = append(keys, name)
+		}
+	}
+	sort.Strings(keys)
+
+	normalized := url.Values{}
+	for _, name := range keys {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		normalized[name] = values
+	}
+
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	key := strings.ToUpper(method) + " " + req.URL.EscapedPath()
+	if encoded := normalized.Encode(); encoded != "" {
+		key += "?" + encoded
+	}
+	return key
+}
@@ -0,0 +1,87 @@
+package fixtures
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestKeyNormalisation(t *testing.T) {
+	a, _ := http.NewRequest("GET", "https://query1.finance.yahoo.com/v8/finance/chart/NVDA?range=1mo&interval=1d&crumb=abc", nil)
+	b, _ := http.NewRequest("GET", "http://127.0.0.1:4242/v8/finance/chart/NVDA?interval=1d&range=1mo", nil)
+	c, _ := http.NewRequest("GET", "http://127.0.0.1:4242/v8/finance/chart/NVDA?interval=1d&range=1y", nil)
+
+	assert.Equal(t, "GET /v8/finance/chart/NVDA?interval=1d&range=1mo", RequestKey(a))
+	assert.Equal(t, RequestKey(a), RequestKey(b))
+	assert.NotEqual(t, RequestKey(a), RequestKey(c))
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	dir := t.TempDir()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/throttled" {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"symbol":"` + r.URL.Query().Get("s") + `"}`))
+	}))
+
+	recorder := &http.Client{Transport: NewTransport(ModeRecord, dir, nil)}
+	for _, path := range []string{"/quote?s=NVDA", "/throttled"} {
+		resp, err := recorder.Get(server.URL + path)
+		require.NoError(t, err)
+		io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	server.Close()
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	// Replay never touches the (now closed) server
+	replayer := &http.Client{Transport: NewTransport(ModeReplay, dir, nil)}
+	resp, err := replayer.Get(server.URL + "/quote?s=NVDA")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"symbol":"NVDA"}`, string(body))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	resp, err = replayer.Get(server.URL + "/throttled")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "7", resp.Header.Get("Retry-After"))
+
+	// Misses name the request and the file that was expected
+	_, err = replayer.Get(server.URL + "/quote?s=TSLA")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrNoFixture)
+	var miss *MissError
+	require.True(t, errors.As(err, &miss))
+	assert.Equal(t, "GET /quote?s=TSLA", miss.Key)
+	assert.True(t, miss.ClientError())
+}
+
+func TestParseMode(t *testing.T) {
+	mode, err := ParseMode("")
+	require.NoError(t, err)
+	assert.Equal(t, ModeLive, mode)
+
+	mode, err = ParseMode(" Replay ")
+	require.NoError(t, err)
+	assert.Equal(t, ModeReplay, mode)
+
+	_, err = ParseMode("mock")
+	assert.Error(t, err)
+}
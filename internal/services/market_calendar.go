@@ -56,6 +56,24 @@ func (s *MarketCalendarService) IsMarketOpen(date time.Time) (bool, error) {
 	return isOpen, nil
 }
 
+// IsMarketOpenAt reports whether the US regular session (09:30-16:00 ET) is
+// in progress at the given instant
+func (s *MarketCalendarService) IsMarketOpenAt(t time.Time) (bool, error) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return false, fmt.Errorf("failed to load market time zone: %v", err)
+	}
+	local := t.In(loc)
+
+	isOpen, err := s.IsMarketOpen(local)
+	if err != nil || !isOpen {
+		return false, err
+	}
+
+	minutes := local.Hour()*60 + local.Minute()
+	return minutes >= 9*60+30 && minutes < 16*60, nil
+}
+
 // WasMarketOpenYesterday checks if the market was open on the previous trading day
 func (s *MarketCalendarService) WasMarketOpenYesterday() (bool, time.Time, error) {
 	now := time.Now()
@@ -173,100 +191,7 @@ func (s *MarketCalendarService) PopulateUSHolidays(year int) error {
 
 // getUSMarketHolidays returns a map of US market holidays for a given year
 func (s *MarketCalendarService) getUSMarketHolidays(year int) map[time.Time]string {
-	holidays := make(map[time.Time]string)
-	
-	// Fixed date holidays
-	holidays[time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)] = "New Year's Day"
-	holidays[time.Date(year, 7, 4, 0, 0, 0, 0, time.UTC)] = "Independence Day"
-	holidays[time.Date(year, 12, 25, 0, 0, 0, 0, time.UTC)] = "Christmas Day"
-	
-	// Juneteenth (June 19th, federal holiday since 2021)
-	if year >= 2021 {
-		holidays[time.Date(year, 6, 19, 0, 0, 0, 0, time.UTC)] = "Juneteenth"
-	}
-	
-	// Variable date holidays
-	holidays[s.getNthWeekdayOfMonth(year, 1, time.Monday, 3)] = "Martin Luther King Jr. Day"
-	holidays[s.getNthWeekdayOfMonth(year, 2, time.Monday, 3)] = "Presidents' Day"
-	holidays[s.getLastWeekdayOfMonth(year, 5, time.Monday)] = "Memorial Day"
-	holidays[s.getNthWeekdayOfMonth(year, 9, time.Monday, 1)] = "Labor Day"
-	holidays[s.getNthWeekdayOfMonth(year, 11, time.Thursday, 4)] = "Thanksgiving Day"
-	
-	// Good Friday (Friday before Easter)
-	easter := s.getEasterDate(year)
-	goodFriday := easter.AddDate(0, 0, -2)
-	holidays[goodFriday] = "Good Friday"
-	
-	// Handle holidays that fall on weekends (observed on Friday or Monday)
-	adjustedHolidays := make(map[time.Time]string)
-	for date, name := range holidays {
-		adjustedDate := s.adjustHolidayForWeekend(date)
-		adjustedHolidays[adjustedDate] = name
-	}
-	
-	return adjustedHolidays
-}
-
-// getNthWeekdayOfMonth returns the nth occurrence of a weekday in a month
-func (s *MarketCalendarService) getNthWeekdayOfMonth(year int, month time.Month, weekday time.Weekday, n int) time.Time {
-	firstDay := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
-	
-	// Find the first occurrence of the weekday
-	daysUntilWeekday := int(weekday - firstDay.Weekday())
-	if daysUntilWeekday < 0 {
-		daysUntilWeekday += 7
-	}
-	
-	firstOccurrence := firstDay.AddDate(0, 0, daysUntilWeekday)
-	return firstOccurrence.AddDate(0, 0, (n-1)*7)
-}
-
-// getLastWeekdayOfMonth returns the last occurrence of a weekday in a month
-func (s *MarketCalendarService) getLastWeekdayOfMonth(year int, month time.Month, weekday time.Weekday) time.Time {
-	// Start from the last day of the month and work backwards
-	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC) // Last day of the month
-	
-	daysBack := int(lastDay.Weekday() - weekday)
-	if daysBack < 0 {
-		daysBack += 7
-	}
-	
-	return lastDay.AddDate(0, 0, -daysBack)
-}
-
-// getEasterDate calculates Easter date for a given year using the algorithm
-func (s *MarketCalendarService) getEasterDate(year int) time.Time {
-	// Using the algorithm for Western Easter
-	a := year % 19
-	b := year / 100
-	c := year % 100
-	d := b / 4
-	e := b % 4
-	f := (b + 8) / 25
-	g := (b - f + 1) / 3
-	h := (19*a + b - d - g + 15) % 30
-	i := c / 4
-	k := c % 4
-	l := (32 + 2*e + 2*i - h - k) % 7
-	m := (a + 11*h + 22*l) / 451
-	month := (h + l - 7*m + 114) / 31
-	day := ((h + l - 7*m + 114) % 31) + 1
-	
-	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
-}
-
-// adjustHolidayForWeekend adjusts holiday dates that fall on weekends
-func (s *MarketCalendarService) adjustHolidayForWeekend(date time.Time) time.Time {
-	switch date.Weekday() {
-	case time.Saturday:
-		// Observed on Friday
-		return date.AddDate(0, 0, -1)
-	case time.Sunday:
-		// Observed on Monday
-		return date.AddDate(0, 0, 1)
-	default:
-		return date
-	}
+	return models.USMarketHolidays(year)
 }
 
 // GetTradingDaysInRange returns the number of trading days in a date range
@@ -290,6 +215,16 @@ func (s *MarketCalendarService) GetTradingDaysInRange(startDate, endDate time.Ti
 	return count, nil
 }
 
+// IsTradingDay reports whether the US market is open on a date, falling
+// back to the built-in holiday list when the calendar cannot be read
+func (s *MarketCalendarService) IsTradingDay(date time.Time) bool {
+	isOpen, err := s.IsMarketOpen(date)
+	if err != nil {
+		return models.IsUSTradingDay(date)
+	}
+	return isOpen
+}
+
 // InitializeCurrentYear populates holidays for the current year if not already done
 func (s *MarketCalendarService) InitializeCurrentYear() error {
 	currentYear := time.Now().Year()
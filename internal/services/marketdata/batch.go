@@ -0,0 +1,101 @@
+package marketdata
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"stock-prediction-us/internal/models"
+)
+
+// BatchResult holds the outcome of one symbol in a batch fetch
+type BatchResult struct {
+	Symbol string
+	Prices []float64
+	Err    error
+}
+
+// BatchFetcher fans requests for many symbols out over a bounded pool of
+// workers. Requests still go through the provider, so a Yahoo upstream keeps
+// sharing its single rate limiter across all workers.
+type BatchFetcher struct {
+	provider MarketDataProvider
+	workers  int
+	logger   *logrus.Logger
+}
+
+// NewBatchFetcher creates a batch fetcher with the given worker pool size
+func NewBatchFetcher(provider MarketDataProvider, workers int, logger *logrus.Logger) *BatchFetcher {
+	if workers < 1 {
+		workers = 1
+	}
+	return &BatchFetcher{
+		provider: provider,
+		workers:  workers,
+		logger:   logger,
+	}
+}
+
+// FetchStockData fetches closes for each symbol. Results are returned in the
+// order of symbols; symbols not started before ctx is done carry ctx.Err().
+func (b *BatchFetcher) FetchStockData(ctx context.Context, symbols []string, period string, interval models.Interval) []BatchResult {
+	return b.run(ctx, symbols, func(symbol string) ([]float64, error) {
+		return b.provider.FetchStockData(ctx, symbol, period, interval)
+	})
+}
+
+// run executes fetch for every symbol on the worker pool
+func (b *BatchFetcher) run(ctx context.Context, symbols []string, fetch func(symbol string) ([]float64, error)) []BatchResult {
+	start := time.Now()
+	results := make([]BatchResult, len(symbols))
+	jobs := make(chan int)
+
+	workers := b.workers
+	if workers > len(symbols) {
+		workers = len(symbols)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					results[i] = BatchResult{Symbol: symbols[i], Err: err}
+					continue
+				}
+				prices, err := fetch(symbols[i])
+				results[i] = BatchResult{Symbol: symbols[i], Prices: prices, Err: err}
+			}
+		}()
+	}
+
+	for i := range symbols {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			results[i] = BatchResult{Symbol: symbols[i], Err: ctx.Err()}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+
+	b.logger.WithFields(logrus.Fields{
+		"symbols":  len(symbols),
+		"failed":   failed,
+		"workers":  workers,
+		"duration": time.Since(start),
+	}).Info("Batch market data fetch completed")
+
+	return results
+}
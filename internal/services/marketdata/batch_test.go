@@ -0,0 +1,71 @@
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-prediction-us/internal/models"
+)
+
+// slowProvider records peak concurrency and fails for one symbol
+type slowProvider struct {
+	stubProvider
+	active int32
+	peak   int32
+}
+
+func (p *slowProvider) FetchStockData(ctx context.Context, symbol string, period string, interval models.Interval) ([]float64, error) {
+	active := atomic.AddInt32(&p.active, 1)
+	defer atomic.AddInt32(&p.active, -1)
+	for {
+		peak := atomic.LoadInt32(&p.peak)
+		if active <= peak || atomic.CompareAndSwapInt32(&p.peak, peak, active) {
+			break
+		}
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if symbol == "FAIL" {
+		return nil, fmt.Errorf("HTTP 404")
+	}
+	return []float64{1, float64(len(symbol))}, nil
+}
+
+func TestBatchFetcherBoundsWorkersAndKeepsOrder(t *testing.T) {
+	provider := &slowProvider{}
+	batch := NewBatchFetcher(provider, 3, logrus.New())
+
+	symbols := []string{"AAPL", "FAIL", "MSFT", "NVDA", "TSLA", "AMZN", "GOOGL", "SPY"}
+	results := batch.FetchStockData(context.Background(), symbols, "7d", models.Interval1d)
+
+	require.Len(t, results, len(symbols))
+	for i, result := range results {
+		assert.Equal(t, symbols[i], result.Symbol)
+		if result.Symbol == "FAIL" {
+			assert.Error(t, result.Err)
+			continue
+		}
+		require.NoError(t, result.Err)
+		assert.Equal(t, float64(len(result.Symbol)), result.Prices[1])
+	}
+	assert.LessOrEqual(t, atomic.LoadInt32(&provider.peak), int32(3))
+}
+
+func TestBatchFetcherHonoursCancellation(t *testing.T) {
+	batch := NewBatchFetcher(&slowProvider{}, 1, logrus.New())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := batch.FetchStockData(ctx, []string{"AAPL", "MSFT"}, "7d", models.Interval1d)
+	for _, result := range results {
+		assert.ErrorIs(t, result.Err, context.Canceled)
+	}
+}
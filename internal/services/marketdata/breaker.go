@@ -0,0 +1,374 @@
+package marketdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"stock-prediction-us/internal/metrics"
+	"stock-prediction-us/internal/models"
+)
+
+// BreakerState is the state of a circuit breaker
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// ErrCircuitOpen is returned without calling the upstream while a breaker is open
+var ErrCircuitOpen = errors.New("market data circuit breaker is open")
+
+// CircuitOpenError reports which provider is short-circuited and for how long
+type CircuitOpenError struct {
+	Provider   string
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: %v (retry in %s)", e.Provider, ErrCircuitOpen, e.RetryAfter.Round(time.Second))
+}
+
+// Is makes errors.Is(err, ErrCircuitOpen) match
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// BreakerSettings configures when a breaker opens and how it recovers
+type BreakerSettings struct {
+	FailureThreshold int           // Consecutive failures that open the breaker
+	OpenTimeout      time.Duration // Time spent open before allowing probes
+	HalfOpenRequests int           // Concurrent probe requests allowed while half-open
+}
+
+// BreakerSnapshot is a point-in-time view of a breaker for health and stats
+type BreakerSnapshot struct {
+	Provider            string       `json:"provider"`
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	Trips               int          `json:"trips"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+	RetryAfterSeconds   float64      `json:"retry_after_seconds,omitempty"`
+}
+
+// BreakerReporter is implemented by providers that contain circuit breakers
+type BreakerReporter interface {
+	BreakerSnapshots() []BreakerSnapshot
+}
+
+// clientError is implemented by upstream errors caused by the request itself
+// (unknown symbol, bad range) which say nothing about upstream health
+type clientError interface {
+	ClientError() bool
+}
+
+// CircuitBreaker wraps a provider and fails fast after repeated upstream
+// failures. After OpenTimeout a limited number of probe requests are let
+// through; one success closes the breaker and one failure re-opens it.
+type CircuitBreaker struct {
+	upstream MarketDataProvider
+	settings BreakerSettings
+	logger   *logrus.Logger
+	metrics  *metrics.Metrics
+
+	mutex     sync.Mutex
+	state     BreakerState
+	failures  int
+	trips     int
+	openedAt  time.Time
+	probes    int
+	now       func() time.Time
+}
+
+// NewCircuitBreaker creates a closed breaker around upstream
+func NewCircuitBreaker(upstream MarketDataProvider, settings BreakerSettings, logger *logrus.Logger, metrics *metrics.Metrics) *CircuitBreaker {
+	if settings.FailureThreshold < 1 {
+		settings.FailureThreshold = 1
+	}
+	if settings.HalfOpenRequests < 1 {
+		settings.HalfOpenRequests = 1
+	}
+	if metrics != nil {
+		metrics.UpdateCircuitBreakerState(upstream.Name(), breakerStateValue(BreakerClosed))
+	}
+	return &CircuitBreaker{
+		upstream: upstream,
+		settings: settings,
+		logger:   logger,
+		metrics:  metrics,
+		state:    BreakerClosed,
+		now:      time.Now,
+	}
+}
+
+// Name returns the upstream name; the breaker is transparent in logs
+func (b *CircuitBreaker) Name() string {
+	return b.upstream.Name()
+}
+
+// FetchLatestPrice calls the upstream unless the breaker is open
+func (b *CircuitBreaker) FetchLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	var price float64
+	err := b.call(ctx, symbol, func() error {
+		var err error
+		price, err = b.upstream.FetchLatestPrice(ctx, symbol)
+		return err
+	})
+	return price, err
+}
+
+// FetchStockData calls the upstream unless the breaker is open
+func (b *CircuitBreaker) FetchStockData(ctx context.Context, symbol string, period string, interval models.Interval) ([]float64, error) {
+	var data []float64
+	err := b.call(ctx, symbol, func() error {
+		var err error
+		data, err = b.upstream.FetchStockData(ctx, symbol, period, interval)
+		return err
+	})
+	return data, err
+}
+
+// FetchHistoricalData calls the upstream unless the breaker is open
+func (b *CircuitBreaker) FetchHistoricalData(ctx context.Context, symbol string, days int, interval models.Interval) ([]models.StockData, error) {
+	var data []models.StockData
+	err := b.call(ctx, symbol, func() error {
+		var err error
+		data, err = b.upstream.FetchHistoricalData(ctx, symbol, days, interval)
+		return err
+	})
+	return data, err
+}
+
+// FetchCorporateActions calls the upstream unless the breaker is open
+func (b *CircuitBreaker) FetchCorporateActions(ctx context.Context, symbol string, days int) ([]models.CorporateAction, error) {
+	var actions []models.CorporateAction
+	err := b.call(ctx, symbol, func() error {
+		var err error
+		actions, err = b.upstream.FetchCorporateActions(ctx, symbol, days)
+		return err
+	})
+	return actions, err
+}
+
+// HealthCheck calls the upstream health check unless the breaker is open
+func (b *CircuitBreaker) HealthCheck(ctx context.Context) error {
+	return b.call(ctx, "", func() error {
+		return b.upstream.HealthCheck(ctx)
+	})
+}
+
+// FetchSymbolMetadata calls the upstream symbol directory unless the breaker is open
+func (b *CircuitBreaker) FetchSymbolMetadata(ctx context.Context, symbol string) (*models.SymbolMetadata, error) {
+	directory, ok := b.upstream.(SymbolDirectory)
+	if !ok {
+		return nil, fmt.Errorf("%s does not support symbol lookup", b.upstream.Name())
+	}
+	var metadata *models.SymbolMetadata
+	err := b.call(ctx, symbol, func() error {
+		var err error
+		metadata, err = directory.FetchSymbolMetadata(ctx, symbol)
+		return err
+	})
+	return metadata, err
+}
+
+// SearchSymbols calls the upstream symbol search unless the breaker is open
+func (b *CircuitBreaker) SearchSymbols(ctx context.Context, query string, limit int) ([]models.SymbolMetadata, error) {
+	directory, ok := b.upstream.(SymbolDirectory)
+	if !ok {
+		return nil, fmt.Errorf("%s does not support symbol search", b.upstream.Name())
+	}
+	var results []models.SymbolMetadata
+	err := b.call(ctx, "", func() error {
+		var err error
+		results, err = directory.SearchSymbols(ctx, query, limit)
+		return err
+	})
+	return results, err
+}
+
+// FetchUpcomingEvents calls the upstream event calendar unless the breaker is open
+func (b *CircuitBreaker) FetchUpcomingEvents(ctx context.Context, symbol string) ([]models.CorporateEvent, error) {
+	calendar, ok := b.upstream.(EventCalendar)
+	if !ok {
+		return nil, fmt.Errorf("%s does not support event calendars", b.upstream.Name())
+	}
+	var events []models.CorporateEvent
+	err := b.call(ctx, symbol, func() error {
+		var err error
+		events, err = calendar.FetchUpcomingEvents(ctx, symbol)
+		return err
+	})
+	return events, err
+}
+
+// State returns the current breaker state
+func (b *CircuitBreaker) State() BreakerState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.advance()
+	return b.state
+}
+
+// BreakerSnapshots returns the breaker's current state
+func (b *CircuitBreaker) BreakerSnapshots() []BreakerSnapshot {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.advance()
+
+	snapshot := BreakerSnapshot{
+		Provider:            b.upstream.Name(),
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		Trips:               b.trips,
+	}
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		snapshot.OpenedAt = &openedAt
+	}
+	if b.state == BreakerOpen {
+		snapshot.RetryAfterSeconds = b.retryAfter().Seconds()
+	}
+	return []BreakerSnapshot{snapshot}
+}
+
+// call runs fn if the breaker admits the request and records the outcome
+func (b *CircuitBreaker) call(ctx context.Context, symbol string, fn func() error) error {
+	// Malformed symbols never reach the upstream, so they say nothing about it
+	if symbol != "" && models.ValidateSymbol(symbol) != nil {
+		return fn()
+	}
+
+	probe, err := b.admit()
+	if err != nil {
+		return err
+	}
+
+	err = fn()
+
+	// Cancelled callers and client errors are not upstream failures
+	var clientErr clientError
+	if err != nil && (ctx.Err() != nil || (errors.As(err, &clientErr) && clientErr.ClientError())) {
+		b.release(probe)
+		return err
+	}
+
+	b.record(probe, err)
+	return err
+}
+
+// admit decides whether a request may proceed and whether it is a probe
+func (b *CircuitBreaker) admit() (bool, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.advance()
+
+	switch b.state {
+	case BreakerOpen:
+		return false, &CircuitOpenError{Provider: b.upstream.Name(), RetryAfter: b.retryAfter()}
+	case BreakerHalfOpen:
+		if b.probes >= b.settings.HalfOpenRequests {
+			return false, &CircuitOpenError{Provider: b.upstream.Name(), RetryAfter: 0}
+		}
+		b.probes++
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// release gives back a probe slot without recording an outcome
+func (b *CircuitBreaker) release(probe bool) {
+	if !probe {
+		return
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.probes--
+}
+
+// record updates the breaker with the outcome of an admitted request
+func (b *CircuitBreaker) record(probe bool, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if probe {
+		b.probes--
+	}
+
+	if err == nil {
+		b.failures = 0
+		if b.state != BreakerClosed {
+			b.transition(BreakerClosed)
+		}
+		return
+	}
+
+	b.failures++
+	switch {
+	case b.state == BreakerHalfOpen:
+		b.open()
+	case b.state == BreakerClosed && b.failures >= b.settings.FailureThreshold:
+		b.open()
+	}
+}
+
+// open trips the breaker; callers hold the mutex
+func (b *CircuitBreaker) open() {
+	b.openedAt = b.now()
+	b.trips++
+	b.transition(BreakerOpen)
+}
+
+// advance moves an open breaker to half-open once the timeout has passed;
+// callers hold the mutex
+func (b *CircuitBreaker) advance() {
+	if b.state == BreakerOpen && b.retryAfter() <= 0 {
+		b.probes = 0
+		b.transition(BreakerHalfOpen)
+	}
+}
+
+// retryAfter returns the time left before probes are allowed; callers hold the mutex
+func (b *CircuitBreaker) retryAfter() time.Duration {
+	remaining := b.settings.OpenTimeout - b.now().Sub(b.openedAt)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// transition changes state, logging and recording metrics; callers hold the mutex
+func (b *CircuitBreaker) transition(to BreakerState) {
+	from := b.state
+	b.state = to
+
+	b.logger.WithFields(logrus.Fields{
+		"provider":             b.upstream.Name(),
+		"from":                 from,
+		"to":                   to,
+		"consecutive_failures": b.failures,
+	}).Warn("Market data circuit breaker state changed")
+
+	if b.metrics != nil {
+		b.metrics.RecordCircuitBreakerTransition(b.upstream.Name(), string(from), string(to), breakerStateValue(to))
+	}
+}
+
+// breakerStateValue maps states to the gauge values 0 (closed), 1 (half-open) and 2 (open)
+func breakerStateValue(state BreakerState) float64 {
+	switch state {
+	case BreakerOpen:
+		return 2
+	case BreakerHalfOpen:
+		return 1
+	default:
+		return 0
+	}
+}
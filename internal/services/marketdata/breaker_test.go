@@ -0,0 +1,92 @@
+package marketdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// notFoundError mimics an upstream 404 for an unknown symbol
+type notFoundError struct{}
+
+func (notFoundError) Error() string     { return "HTTP 404" }
+func (notFoundError) ClientError() bool { return true }
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	ctx := context.Background()
+	upstream := &stubProvider{name: "yahoo", price: 10, err: fmt.Errorf("HTTP 429")}
+	breaker := NewCircuitBreaker(upstream, BreakerSettings{
+		FailureThreshold: 2,
+		OpenTimeout:      30 * time.Second,
+	}, logrus.New(), nil)
+
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	// Closed: failures below the threshold reach the upstream
+	_, err := breaker.FetchLatestPrice(ctx, "NVDA")
+	assert.NotErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, BreakerClosed, breaker.State())
+
+	// Open: the threshold trips the breaker and calls fail fast
+	_, err = breaker.FetchLatestPrice(ctx, "NVDA")
+	assert.NotErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, BreakerOpen, breaker.State())
+
+	upstream.err = nil
+	_, err = breaker.FetchLatestPrice(ctx, "NVDA")
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	var openErr *CircuitOpenError
+	require.True(t, errors.As(err, &openErr))
+	assert.Equal(t, 30*time.Second, openErr.RetryAfter)
+
+	// Half-open: after the timeout a failed probe re-opens the breaker
+	now = now.Add(31 * time.Second)
+	assert.Equal(t, BreakerHalfOpen, breaker.State())
+	upstream.err = fmt.Errorf("HTTP 503")
+	_, err = breaker.FetchLatestPrice(ctx, "NVDA")
+	assert.NotErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, BreakerOpen, breaker.State())
+
+	// A successful probe closes it again
+	now = now.Add(31 * time.Second)
+	upstream.err = nil
+	price, err := breaker.FetchLatestPrice(ctx, "NVDA")
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, price)
+	assert.Equal(t, BreakerClosed, breaker.State())
+
+	snapshot := breaker.BreakerSnapshots()[0]
+	assert.Equal(t, 2, snapshot.Trips)
+	assert.Equal(t, 0, snapshot.ConsecutiveFailures)
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	ctx := context.Background()
+	upstream := &stubProvider{name: "yahoo", err: notFoundError{}}
+	breaker := NewCircuitBreaker(upstream, BreakerSettings{FailureThreshold: 1, OpenTimeout: time.Minute}, logrus.New(), nil)
+
+	for i := 0; i < 3; i++ {
+		_, err := breaker.FetchLatestPrice(ctx, "ZZZZ")
+		assert.Error(t, err)
+		_, err = breaker.FetchLatestPrice(ctx, "not-a-symbol")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, BreakerClosed, breaker.State())
+
+	// Open breakers surface through a failover chain as ErrCircuitOpen
+	upstream.err = fmt.Errorf("HTTP 500")
+	_, err := breaker.FetchLatestPrice(ctx, "NVDA")
+	require.Error(t, err)
+
+	chain := NewChainProvider(logrus.New(), breaker)
+	_, err = chain.FetchLatestPrice(ctx, "NVDA")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, BreakerOpen, chain.BreakerSnapshots()[0].State)
+}
@@ -0,0 +1,173 @@
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"stock-prediction-us/internal/models"
+)
+
+// ChainProvider tries each provider in order and returns the first success
+type ChainProvider struct {
+	providers []MarketDataProvider
+	logger    *logrus.Logger
+}
+
+// NewChainProvider creates a failover chain over the given providers
+func NewChainProvider(logger *logrus.Logger, providers ...MarketDataProvider) *ChainProvider {
+	return &ChainProvider{
+		providers: providers,
+		logger:    logger,
+	}
+}
+
+// Name returns the names of the chained providers
+func (c *ChainProvider) Name() string {
+	names := make([]string, len(c.providers))
+	for i, p := range c.providers {
+		names[i] = p.Name()
+	}
+	return "chain(" + strings.Join(names, ",") + ")"
+}
+
+// FetchStockData returns closes from the first provider that succeeds
+func (c *ChainProvider) FetchStockData(ctx context.Context, symbol string, period string, interval models.Interval) ([]float64, error) {
+	var data []float64
+	err := c.try(ctx, "FetchStockData", symbol, func(p MarketDataProvider) error {
+		var err error
+		data, err = p.FetchStockData(ctx, symbol, period, interval)
+		return err
+	})
+	return data, err
+}
+
+// FetchLatestPrice returns the latest price from the first provider that succeeds
+func (c *ChainProvider) FetchLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	var price float64
+	err := c.try(ctx, "FetchLatestPrice", symbol, func(p MarketDataProvider) error {
+		var err error
+		price, err = p.FetchLatestPrice(ctx, symbol)
+		return err
+	})
+	return price, err
+}
+
+// FetchHistoricalData returns bars from the first provider that succeeds
+func (c *ChainProvider) FetchHistoricalData(ctx context.Context, symbol string, days int, interval models.Interval) ([]models.StockData, error) {
+	var data []models.StockData
+	err := c.try(ctx, "FetchHistoricalData", symbol, func(p MarketDataProvider) error {
+		var err error
+		data, err = p.FetchHistoricalData(ctx, symbol, days, interval)
+		return err
+	})
+	return data, err
+}
+
+// FetchCorporateActions returns actions from the first provider that succeeds
+func (c *ChainProvider) FetchCorporateActions(ctx context.Context, symbol string, days int) ([]models.CorporateAction, error) {
+	var actions []models.CorporateAction
+	err := c.try(ctx, "FetchCorporateActions", symbol, func(p MarketDataProvider) error {
+		var err error
+		actions, err = p.FetchCorporateActions(ctx, symbol, days)
+		return err
+	})
+	return actions, err
+}
+
+// FetchSymbolMetadata returns metadata from the first provider that succeeds
+func (c *ChainProvider) FetchSymbolMetadata(ctx context.Context, symbol string) (*models.SymbolMetadata, error) {
+	var metadata *models.SymbolMetadata
+	err := c.try(ctx, "FetchSymbolMetadata", symbol, func(p MarketDataProvider) error {
+		directory, ok := p.(SymbolDirectory)
+		if !ok {
+			return fmt.Errorf("symbol lookup not supported")
+		}
+		var err error
+		metadata, err = directory.FetchSymbolMetadata(ctx, symbol)
+		return err
+	})
+	return metadata, err
+}
+
+// SearchSymbols returns search results from the first provider that succeeds
+func (c *ChainProvider) SearchSymbols(ctx context.Context, query string, limit int) ([]models.SymbolMetadata, error) {
+	var results []models.SymbolMetadata
+	err := c.try(ctx, "SearchSymbols", query, func(p MarketDataProvider) error {
+		directory, ok := p.(SymbolDirectory)
+		if !ok {
+			return fmt.Errorf("symbol search not supported")
+		}
+		var err error
+		results, err = directory.SearchSymbols(ctx, query, limit)
+		return err
+	})
+	return results, err
+}
+
+// FetchUpcomingEvents returns events from the first provider that succeeds
+func (c *ChainProvider) FetchUpcomingEvents(ctx context.Context, symbol string) ([]models.CorporateEvent, error) {
+	var events []models.CorporateEvent
+	err := c.try(ctx, "FetchUpcomingEvents", symbol, func(p MarketDataProvider) error {
+		calendar, ok := p.(EventCalendar)
+		if !ok {
+			return fmt.Errorf("event calendar not supported")
+		}
+		var err error
+		events, err = calendar.FetchUpcomingEvents(ctx, symbol)
+		return err
+	})
+	return events, err
+}
+
+// HealthCheck succeeds if at least one provider is healthy
+func (c *ChainProvider) HealthCheck(ctx context.Context) error {
+	return c.try(ctx, "HealthCheck", "", func(p MarketDataProvider) error {
+		return p.HealthCheck(ctx)
+	})
+}
+
+// try runs fn against each provider until one succeeds. A cancelled context
+// stops the failover instead of trying the remaining providers.
+func (c *ChainProvider) try(ctx context.Context, operation, symbol string, fn func(MarketDataProvider) error) error {
+	if len(c.providers) == 0 {
+		return fmt.Errorf("no market data providers configured")
+	}
+
+	// Keep every failure wrapped so callers can match sentinel errors
+	var formats []string
+	var failures []interface{}
+	for _, p := range c.providers {
+		err := fn(p)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+
+		formats = append(formats, "%s: %w")
+		failures = append(failures, p.Name(), err)
+		c.logger.WithFields(logrus.Fields{
+			"provider":  p.Name(),
+			"operation": operation,
+			"symbol":    symbol,
+			"error":     err,
+		}).Warn("Market data provider failed, trying next")
+	}
+
+	return fmt.Errorf("all market data providers failed: "+strings.Join(formats, "; "), failures...)
+}
+
+// BreakerSnapshots collects circuit breaker state from the chained providers
+func (c *ChainProvider) BreakerSnapshots() []BreakerSnapshot {
+	var snapshots []BreakerSnapshot
+	for _, p := range c.providers {
+		if reporter, ok := p.(BreakerReporter); ok {
+			snapshots = append(snapshots, reporter.BreakerSnapshots()...)
+		}
+	}
+	return snapshots
+}
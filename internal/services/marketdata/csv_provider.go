@@ -0,0 +1,227 @@
+package marketdata
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"stock-prediction-us/internal/models"
+)
+
+// CSVProvider serves recorded daily bars from a directory of <SYMBOL>.csv files.
+// Files use the Yahoo Finance download layout:
+// Date,Open,High,Low,Close,Adj Close,Volume
+type CSVProvider struct {
+	dir    string
+	logger *logrus.Logger
+}
+
+// NewCSVProvider creates a provider backed by the given directory
+func NewCSVProvider(dir string, logger *logrus.Logger) *CSVProvider {
+	return &CSVProvider{
+		dir:    dir,
+		logger: logger,
+	}
+}
+
+// Name returns the provider identifier
+func (p *CSVProvider) Name() string {
+	return "csv"
+}
+
+// FetchStockData returns closes covering the requested period, measured back
+// from the most recent recorded bar
+func (p *CSVProvider) FetchStockData(ctx context.Context, symbol string, period string, interval models.Interval) ([]float64, error) {
+	if interval.IsIntraday() {
+		return nil, fmt.Errorf("csv provider only serves daily bars, got interval %s", interval)
+	}
+
+	days, err := PeriodToDays(period)
+	if err != nil {
+		return nil, err
+	}
+
+	bars, err := p.loadBars(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := bars[len(bars)-1].Timestamp.AddDate(0, 0, -days)
+	var closes []float64
+	for _, bar := range bars {
+		if bar.Timestamp.After(cutoff) {
+			closes = append(closes, bar.Close)
+		}
+	}
+
+	if err := models.ValidateStockData(closes, 1); err != nil {
+		return nil, fmt.Errorf("invalid stock data: %w", err)
+	}
+
+	return closes, nil
+}
+
+// FetchLatestPrice returns the close of the most recent recorded bar
+func (p *CSVProvider) FetchLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	bars, err := p.loadBars(symbol)
+	if err != nil {
+		return 0, err
+	}
+
+	return bars[len(bars)-1].Close, nil
+}
+
+// FetchHistoricalData returns the last N recorded bars
+func (p *CSVProvider) FetchHistoricalData(ctx context.Context, symbol string, days int, interval models.Interval) ([]models.StockData, error) {
+	if interval.IsIntraday() {
+		return nil, fmt.Errorf("csv provider only serves daily bars, got interval %s", interval)
+	}
+
+	bars, err := p.loadBars(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bars) > days {
+		bars = bars[len(bars)-days:]
+	}
+
+	return bars, nil
+}
+
+// FetchCorporateActions returns no actions; recordings carry an Adj Close
+// column instead
+func (p *CSVProvider) FetchCorporateActions(ctx context.Context, symbol string, days int) ([]models.CorporateAction, error) {
+	return nil, nil
+}
+
+// HealthCheck verifies the data directory is readable
+func (p *CSVProvider) HealthCheck(ctx context.Context) error {
+	info, err := os.Stat(p.dir)
+	if err != nil {
+		return fmt.Errorf("csv data directory unavailable: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("csv data path is not a directory: %s", p.dir)
+	}
+	return nil
+}
+
+// loadBars reads and parses the CSV file for a symbol, sorted by date
+func (p *CSVProvider) loadBars(symbol string) ([]models.StockData, error) {
+	if err := models.ValidateSymbol(symbol); err != nil {
+		return nil, fmt.Errorf("invalid symbol: %w", err)
+	}
+
+	path := filepath.Join(p.dir, symbol+".csv")
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("no recorded data for %s: %w", symbol, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header from %s: %w", path, err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"date", "open", "high", "low", "close", "volume"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%s is missing column %q", path, required)
+		}
+	}
+
+	var bars []models.StockData
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s line %d: %w", path, line, err)
+		}
+
+		bar, err := parseCSVBar(symbol, record, columns)
+		if err != nil {
+			// Recorded files commonly contain "null" rows for halted days
+			p.logger.WithFields(logrus.Fields{
+				"symbol": symbol,
+				"line":   line,
+				"error":  err,
+			}).Debug("Skipping unparsable CSV row")
+			continue
+		}
+		bars = append(bars, bar)
+	}
+
+	if len(bars) == 0 {
+		return nil, fmt.Errorf("no bars in %s", path)
+	}
+
+	sort.Slice(bars, func(i, j int) bool {
+		return bars[i].Timestamp.Before(bars[j].Timestamp)
+	})
+
+	return bars, nil
+}
+
+// parseCSVBar converts one CSV record into a StockData bar
+func parseCSVBar(symbol string, record []string, columns map[string]int) (models.StockData, error) {
+	field := func(name string) string {
+		return strings.TrimSpace(record[columns[name]])
+	}
+
+	timestamp, err := time.Parse("2006-01-02", field("date"))
+	if err != nil {
+		return models.StockData{}, fmt.Errorf("invalid date %q", field("date"))
+	}
+
+	prices := make(map[string]float64, 4)
+	for _, name := range []string{"open", "high", "low", "close"} {
+		value, err := strconv.ParseFloat(field(name), 64)
+		if err != nil {
+			return models.StockData{}, fmt.Errorf("invalid %s %q", name, field(name))
+		}
+		prices[name] = value
+	}
+
+	volume, err := strconv.ParseInt(field("volume"), 10, 64)
+	if err != nil {
+		return models.StockData{}, fmt.Errorf("invalid volume %q", field("volume"))
+	}
+
+	bar := models.StockData{
+		Symbol:    symbol,
+		Timestamp: timestamp,
+		Open:      prices["open"],
+		High:      prices["high"],
+		Low:       prices["low"],
+		Close:     prices["close"],
+		Volume:    volume,
+		Interval:  models.Interval1d,
+		Currency:  models.CurrencyOf(symbol),
+	}
+
+	// The adjusted close column is optional
+	if _, ok := columns["adj close"]; ok {
+		if adjClose, err := strconv.ParseFloat(field("adj close"), 64); err == nil {
+			bar.AdjClose = adjClose
+		}
+	}
+
+	return bar, nil
+}
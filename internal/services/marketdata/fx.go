@@ -0,0 +1,139 @@
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"stock-prediction-us/internal/models"
+)
+
+// cryptoBases are currencies whose rates come from crypto pairs (BTC-USD)
+// rather than Yahoo FX pairs (EURUSD=X)
+var cryptoBases = map[string]bool{"BTC": true, "ETH": true}
+
+// FXRates converts amounts between currencies with daily closes of currency
+// pairs fetched through a MarketDataProvider, so rates share the provider
+// chain, circuit breaker and bar store with prices. Series are cached per
+// pair and refetched after the TTL or when an older date is requested.
+type FXRates struct {
+	provider MarketDataProvider
+	logger   *logrus.Logger
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu     sync.Mutex
+	series map[string]*fxSeries
+}
+
+// fxSeries is a cached daily close series for one pair
+type fxSeries struct {
+	fetched time.Time
+	days    int
+	bars    []models.StockData
+}
+
+// NewFXRates creates an FX converter
+func NewFXRates(provider MarketDataProvider, logger *logrus.Logger, ttl time.Duration) *FXRates {
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+	return &FXRates{
+		provider: provider,
+		logger:   logger,
+		ttl:      ttl,
+		now:      time.Now,
+		series:   make(map[string]*fxSeries),
+	}
+}
+
+// Rate returns the value of one unit of from in to, using the last daily
+// close on or before date. Sub-unit quotes such as GBp are scaled.
+func (f *FXRates) Rate(ctx context.Context, from, to string, date time.Time) (float64, error) {
+	fromMajor, fromScale := models.MajorCurrency(from)
+	toMajor, toScale := models.MajorCurrency(to)
+	if fromMajor == toMajor {
+		return fromScale / toScale, nil
+	}
+
+	rate, err := f.pairRate(ctx, fromMajor, toMajor, date)
+	if err != nil {
+		// Only one direction of some pairs is listed
+		inverse, inverseErr := f.pairRate(ctx, toMajor, fromMajor, date)
+		if inverseErr != nil || inverse <= 0 {
+			return 0, fmt.Errorf("no FX rate for %s/%s: %w", fromMajor, toMajor, err)
+		}
+		rate = 1 / inverse
+	}
+	return rate * fromScale / toScale, nil
+}
+
+// Convert converts an amount between currencies at the rate on date
+func (f *FXRates) Convert(ctx context.Context, amount float64, from, to string, date time.Time) (float64, error) {
+	rate, err := f.Rate(ctx, from, to, date)
+	if err != nil {
+		return 0, err
+	}
+	return amount * rate, nil
+}
+
+// pairRate looks up the close of a pair on or before date
+func (f *FXRates) pairRate(ctx context.Context, from, to string, date time.Time) (float64, error) {
+	ticker := fxTicker(from, to)
+	bars, err := f.seriesFor(ctx, ticker, date)
+	if err != nil {
+		return 0, err
+	}
+
+	day := date.UTC().Format("2006-01-02")
+	for i := len(bars) - 1; i >= 0; i-- {
+		if bars[i].Close > 0 && bars[i].Timestamp.UTC().Format("2006-01-02") <= day {
+			return bars[i].Close, nil
+		}
+	}
+	return 0, fmt.Errorf("no %s close on or before %s", ticker, day)
+}
+
+// seriesFor returns cached bars for a pair reaching back to date, fetching
+// when the cache is stale or too short
+func (f *FXRates) seriesFor(ctx context.Context, ticker string, date time.Time) ([]models.StockData, error) {
+	now := f.now()
+	days := int(now.Sub(date).Hours()/24) + 7
+	if days < 10 {
+		days = 10
+	}
+
+	f.mu.Lock()
+	cached, ok := f.series[ticker]
+	f.mu.Unlock()
+	if ok && now.Sub(cached.fetched) < f.ttl && cached.days >= days {
+		return cached.bars, nil
+	}
+
+	bars, err := f.provider.FetchHistoricalData(ctx, ticker, days, models.Interval1d)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", ticker, err)
+	}
+
+	f.logger.WithFields(logrus.Fields{
+		"pair": ticker,
+		"days": days,
+		"bars": len(bars),
+	}).Debug("Fetched FX rates")
+
+	f.mu.Lock()
+	f.series[ticker] = &fxSeries{fetched: now, days: days, bars: bars}
+	f.mu.Unlock()
+	return bars, nil
+}
+
+// fxTicker returns the Yahoo ticker quoting from in to
+func fxTicker(from, to string) string {
+	if cryptoBases[from] {
+		return from + "-" + to
+	}
+	return from + to + "=X"
+}
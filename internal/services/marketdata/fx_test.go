@@ -0,0 +1,84 @@
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-prediction-us/internal/models"
+)
+
+// pairsProvider serves daily closes per ticker and counts fetches
+type pairsProvider struct {
+	barsProvider
+	pairs   map[string][]models.StockData
+	fetches map[string]int
+}
+
+func (p *pairsProvider) FetchHistoricalData(ctx context.Context, symbol string, days int, interval models.Interval) ([]models.StockData, error) {
+	p.fetches[symbol]++
+	bars, ok := p.pairs[symbol]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+	return bars, nil
+}
+
+func fxBars(closes map[string]float64) []models.StockData {
+	var bars []models.StockData
+	for _, day := range []string{"2026-10-12", "2026-10-13", "2026-10-14", "2026-10-15"} {
+		if close, ok := closes[day]; ok {
+			timestamp, _ := time.Parse("2006-01-02", day)
+			bars = append(bars, models.StockData{Timestamp: timestamp, Close: close})
+		}
+	}
+	return bars
+}
+
+func TestFXRates(t *testing.T) {
+	ctx := context.Background()
+	provider := &pairsProvider{
+		pairs: map[string][]models.StockData{
+			"TWDUSD=X": fxBars(map[string]float64{"2026-10-12": 0.031, "2026-10-14": 0.032}),
+			"USDJPY=X": fxBars(map[string]float64{"2026-10-15": 150}),
+			"GBPUSD=X": fxBars(map[string]float64{"2026-10-15": 1.25}),
+		},
+		fetches: make(map[string]int),
+	}
+	fx := NewFXRates(provider, logrus.New(), time.Hour)
+	fx.now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
+
+	// Last close on or before the date, including non-trading days
+	rate, err := fx.Rate(ctx, "TWD", "USD", time.Date(2026, 10, 13, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 0.031, rate)
+	rate, err = fx.Rate(ctx, "TWD", "USD", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 0.032, rate)
+	assert.Equal(t, 1, provider.fetches["TWDUSD=X"], "series are cached")
+
+	// Missing pairs fall back to the inverse quote
+	rate, err = fx.Rate(ctx, "JPY", "USD", time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0/150, rate, 1e-12)
+
+	// Pence are scaled to pounds before converting
+	amount, err := fx.Convert(ctx, 200, "GBp", "USD", time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.InDelta(t, 2.5, amount, 1e-12)
+
+	rate, err = fx.Rate(ctx, "USDT", "USD", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, rate)
+
+	_, err = fx.Rate(ctx, "TWD", "USD", time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))
+	assert.Error(t, err, "no close before the series starts")
+
+	_, err = fx.Rate(ctx, "CHF", "SEK", time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+}
@@ -0,0 +1,88 @@
+package marketdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"stock-prediction-us/internal/models"
+)
+
+// MarketDataProvider is the source of price data used by handlers, the daily
+// tracker and health checks. The Yahoo Finance client is one implementation;
+// others can serve recorded data or a different vendor. Every call takes the
+// caller's context so a client disconnect or shutdown stops the fetch.
+type MarketDataProvider interface {
+	// Name returns a short identifier for logs and health output
+	Name() string
+
+	// FetchLatestPrice returns the most recent close for a symbol
+	FetchLatestPrice(ctx context.Context, symbol string) (float64, error)
+
+	// FetchStockData returns closes at the given interval for a Yahoo-style
+	// range ("7d", "1mo", "1y", ...)
+	FetchStockData(ctx context.Context, symbol string, period string, interval models.Interval) ([]float64, error)
+
+	// FetchHistoricalData returns OHLCV bars covering the last N trading days
+	FetchHistoricalData(ctx context.Context, symbol string, days int, interval models.Interval) ([]models.StockData, error)
+
+	// FetchCorporateActions returns splits and dividends with an ex-date in the last N days
+	FetchCorporateActions(ctx context.Context, symbol string, days int) ([]models.CorporateAction, error)
+
+	// HealthCheck reports whether the provider can currently serve data
+	HealthCheck(ctx context.Context) error
+}
+
+// ErrSymbolNotFound is returned when a provider does not know a symbol
+var ErrSymbolNotFound = errors.New("symbol not found")
+
+// SymbolDirectory is implemented by providers that can describe instruments.
+// It is optional; callers type-assert a MarketDataProvider to use it.
+type SymbolDirectory interface {
+	// FetchSymbolMetadata returns names, exchange, currency and type for a symbol
+	FetchSymbolMetadata(ctx context.Context, symbol string) (*models.SymbolMetadata, error)
+
+	// SearchSymbols returns up to limit instruments matching a ticker or name fragment
+	SearchSymbols(ctx context.Context, query string, limit int) ([]models.SymbolMetadata, error)
+}
+
+// EventCalendar is implemented by providers that publish scheduled corporate
+// events. It is optional; callers type-assert a MarketDataProvider to use it.
+type EventCalendar interface {
+	// FetchUpcomingEvents returns announced earnings, ex-dividend and split
+	// dates for a symbol that fall today or later
+	FetchUpcomingEvents(ctx context.Context, symbol string) ([]models.CorporateEvent, error)
+}
+
+// PeriodToDays converts a Yahoo-style range string into a number of calendar days
+func PeriodToDays(period string) (int, error) {
+	period = strings.TrimSpace(strings.ToLower(period))
+	if period == "" {
+		return 0, fmt.Errorf("period cannot be empty")
+	}
+
+	units := []struct {
+		suffix string
+		days   int
+	}{
+		{"mo", 30},
+		{"wk", 7},
+		{"d", 1},
+		{"y", 365},
+	}
+
+	for _, unit := range units {
+		if !strings.HasSuffix(period, unit.suffix) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(period, unit.suffix))
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid period: %s", period)
+		}
+		return n * unit.days, nil
+	}
+
+	return 0, fmt.Errorf("unsupported period: %s", period)
+}
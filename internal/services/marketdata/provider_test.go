@@ -0,0 +1,116 @@
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-prediction-us/internal/models"
+)
+
+const sampleCSV = `Date,Open,High,Low,Close,Adj Close,Volume
+2024-08-05,100.0,102.0,99.0,101.0,101.0,1000
+2024-08-06,101.0,103.0,100.0,102.0,102.0,1100
+2024-08-07,null,null,null,null,null,null
+2024-08-08,102.0,104.0,101.0,103.5,103.5,1200
+`
+
+func TestPeriodToDays(t *testing.T) {
+	tests := []struct {
+		period  string
+		want    int
+		wantErr bool
+	}{
+		{"1d", 1, false},
+		{"7d", 7, false},
+		{"1mo", 30, false},
+		{"6mo", 180, false},
+		{"2y", 730, false},
+		{"", 0, true},
+		{"max", 0, true},
+		{"0d", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			got, err := PeriodToDays(tt.period)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCSVProvider(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "NVDA.csv"), []byte(sampleCSV), 0644))
+
+	provider := NewCSVProvider(dir, logrus.New())
+
+	closes, err := provider.FetchStockData(ctx, "NVDA", "7d", models.Interval1d)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{101.0, 102.0, 103.5}, closes)
+
+	latest, err := provider.FetchLatestPrice(ctx, "NVDA")
+	require.NoError(t, err)
+	assert.Equal(t, 103.5, latest)
+
+	bars, err := provider.FetchHistoricalData(ctx, "NVDA", 2, models.Interval1d)
+	require.NoError(t, err)
+	require.Len(t, bars, 2)
+	assert.Equal(t, int64(1200), bars[1].Volume)
+
+	_, err = provider.FetchLatestPrice(ctx, "AAPL")
+	assert.Error(t, err)
+}
+
+type stubProvider struct {
+	name  string
+	price float64
+	err   error
+}
+
+func (s *stubProvider) Name() string { return s.name }
+
+func (s *stubProvider) FetchLatestPrice(ctx context.Context, symbol string) (float64, error) { return s.price, s.err }
+
+func (s *stubProvider) FetchStockData(ctx context.Context, symbol string, period string, interval models.Interval) ([]float64, error) {
+	return []float64{s.price}, s.err
+}
+
+func (s *stubProvider) FetchHistoricalData(ctx context.Context, symbol string, days int, interval models.Interval) ([]models.StockData, error) {
+	return nil, s.err
+}
+
+func (s *stubProvider) FetchCorporateActions(ctx context.Context, symbol string, days int) ([]models.CorporateAction, error) {
+	return nil, s.err
+}
+
+func (s *stubProvider) HealthCheck(ctx context.Context) error { return s.err }
+
+func TestChainProviderFailover(t *testing.T) {
+	ctx := context.Background()
+	failing := &stubProvider{name: "primary", err: fmt.Errorf("throttled")}
+	backup := &stubProvider{name: "backup", price: 42}
+
+	chain := NewChainProvider(logrus.New(), failing, backup)
+	assert.Equal(t, "chain(primary,backup)", chain.Name())
+
+	price, err := chain.FetchLatestPrice(ctx, "NVDA")
+	require.NoError(t, err)
+	assert.Equal(t, 42.0, price)
+
+	allFailing := NewChainProvider(logrus.New(), failing)
+	_, err = allFailing.FetchLatestPrice(ctx, "NVDA")
+	assert.ErrorContains(t, err, "primary: throttled")
+}
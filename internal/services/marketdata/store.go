@@ -0,0 +1,477 @@
+package marketdata
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"stock-prediction-us/internal/models"
+)
+
+// BarStore persists daily OHLCV bars in SQLite and serves reads from the
+// store first. Only bars missing since the last stored date are fetched from
+// the upstream provider, and cached bars keep being served when the upstream
+// is slow or down. Intraday requests pass straight through to the upstream.
+//
+// Corporate actions are stored alongside the bars. A newly seen split or
+// dividend changes the adjusted close of every earlier bar, so it triggers a
+// full re-backfill of the symbol. Symbol metadata is captured on sync too.
+type BarStore struct {
+	db           *sql.DB
+	upstream     MarketDataProvider
+	logger       *logrus.Logger
+	syncInterval time.Duration
+	backfillDays int
+
+	mutex    sync.Mutex
+	locks    map[string]*sync.Mutex
+	lastSync map[string]time.Time
+}
+
+// NewBarStore creates a bar store in front of the given upstream provider
+func NewBarStore(db *sql.DB, upstream MarketDataProvider, logger *logrus.Logger, syncInterval time.Duration, backfillDays int) *BarStore {
+	return &BarStore{
+		db:           db,
+		upstream:     upstream,
+		logger:       logger,
+		syncInterval: syncInterval,
+		backfillDays: backfillDays,
+		locks:        make(map[string]*sync.Mutex),
+		lastSync:     make(map[string]time.Time),
+	}
+}
+
+// Name returns the store identifier including the upstream provider
+func (s *BarStore) Name() string {
+	return "store(" + s.upstream.Name() + ")"
+}
+
+// Sync fetches bars newer than the last stored date and upserts them.
+// The last stored bar is always refreshed since it may have been partial.
+// Returns the number of bars written.
+func (s *BarStore) Sync(ctx context.Context, symbol string) (int, error) {
+	lock := s.symbolLock(symbol)
+	lock.Lock()
+	defer lock.Unlock()
+
+	lastDate, found, err := s.lastStoredDate(symbol)
+	if err != nil {
+		return 0, err
+	}
+
+	days := s.backfillDays
+	if found {
+		days = int(time.Since(lastDate).Hours()/24) + 2
+		if days > s.backfillDays {
+			days = s.backfillDays
+		}
+	}
+
+	bars, err := s.upstream.FetchHistoricalData(ctx, symbol, days, models.Interval1d)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch bars from %s: %w", s.upstream.Name(), err)
+	}
+
+	actions, err := s.upstream.FetchCorporateActions(ctx, symbol, days)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"symbol": symbol,
+			"error":  err,
+		}).Warn("Failed to fetch corporate actions")
+	} else {
+		newActions, err := s.upsertActions(symbol, actions)
+		if err != nil {
+			return 0, err
+		}
+
+		// Stored adjusted closes are on a stale basis after a new action
+		if found && newActions > 0 && days < s.backfillDays {
+			s.logger.WithFields(logrus.Fields{
+				"symbol":      symbol,
+				"new_actions": newActions,
+			}).Info("New corporate action, re-backfilling price bars")
+
+			bars, err = s.upstream.FetchHistoricalData(ctx, symbol, s.backfillDays, models.Interval1d)
+			if err != nil {
+				return 0, fmt.Errorf("failed to re-backfill bars from %s: %w", s.upstream.Name(), err)
+			}
+			found = false
+		}
+	}
+
+	var missing []models.StockData
+	for _, bar := range bars {
+		if !found || !barDate(bar.Timestamp).Before(lastDate) {
+			missing = append(missing, bar)
+		}
+	}
+
+	if err := s.upsertBars(symbol, missing); err != nil {
+		return 0, err
+	}
+	s.captureMetadata(ctx, symbol)
+
+	s.mutex.Lock()
+	s.lastSync[symbol] = time.Now()
+	s.mutex.Unlock()
+
+	s.logger.WithFields(logrus.Fields{
+		"symbol":   symbol,
+		"upstream": s.upstream.Name(),
+		"fetched":  len(bars),
+		"stored":   len(missing),
+	}).Debug("Synced price bars")
+
+	return len(missing), nil
+}
+
+// FetchHistoricalData returns the last N stored bars after an incremental sync
+func (s *BarStore) FetchHistoricalData(ctx context.Context, symbol string, days int, interval models.Interval) ([]models.StockData, error) {
+	if interval.IsIntraday() {
+		return s.upstream.FetchHistoricalData(ctx, symbol, days, interval)
+	}
+
+	if err := models.ValidateSymbol(symbol); err != nil {
+		return nil, fmt.Errorf("invalid symbol: %w", err)
+	}
+
+	syncErr := s.ensureSynced(ctx, symbol)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	bars, err := s.loadBars(symbol, days)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bars) == 0 {
+		if syncErr != nil {
+			return nil, fmt.Errorf("no stored bars for %s: %w", symbol, syncErr)
+		}
+		return nil, fmt.Errorf("no stored bars for %s", symbol)
+	}
+
+	return bars, nil
+}
+
+// FetchStockData returns stored closes covering the requested period,
+// measured back from the most recent stored bar
+func (s *BarStore) FetchStockData(ctx context.Context, symbol string, period string, interval models.Interval) ([]float64, error) {
+	if interval.IsIntraday() {
+		return s.upstream.FetchStockData(ctx, symbol, period, interval)
+	}
+
+	days, err := PeriodToDays(period)
+	if err != nil {
+		return nil, err
+	}
+
+	bars, err := s.FetchHistoricalData(ctx, symbol, days, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := bars[len(bars)-1].Timestamp.AddDate(0, 0, -days)
+	var closes []float64
+	for _, bar := range bars {
+		if bar.Timestamp.After(cutoff) {
+			closes = append(closes, bar.Close)
+		}
+	}
+
+	if err := models.ValidateStockData(closes, 1); err != nil {
+		return nil, fmt.Errorf("invalid stock data: %w", err)
+	}
+
+	return closes, nil
+}
+
+// FetchLatestPrice prefers a live quote and falls back to the last stored close
+func (s *BarStore) FetchLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	price, err := s.upstream.FetchLatestPrice(ctx, symbol)
+	if err == nil {
+		return price, nil
+	}
+
+	bars, loadErr := s.loadBars(symbol, 1)
+	if loadErr != nil || len(bars) == 0 {
+		return 0, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"symbol": symbol,
+		"error":  err,
+	}).Warn("Live quote unavailable, serving last stored close")
+
+	return bars[0].Close, nil
+}
+
+// FetchCorporateActions returns stored actions with an ex-date in the last N days
+func (s *BarStore) FetchCorporateActions(ctx context.Context, symbol string, days int) ([]models.CorporateAction, error) {
+	if err := models.ValidateSymbol(symbol); err != nil {
+		return nil, fmt.Errorf("invalid symbol: %w", err)
+	}
+
+	s.ensureSynced(ctx, symbol)
+
+	rows, err := s.db.Query(`
+		SELECT ex_date, action_type, amount, numerator, denominator
+		FROM corporate_actions
+		WHERE symbol = ? AND ex_date >= ?
+		ORDER BY ex_date ASC
+	`, symbol, time.Now().AddDate(0, 0, -days).Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query corporate actions: %w", err)
+	}
+	defer rows.Close()
+
+	var actions []models.CorporateAction
+	for rows.Next() {
+		var dateStr string
+		var amount, numerator, denominator sql.NullFloat64
+		action := models.CorporateAction{Symbol: symbol}
+
+		if err := rows.Scan(&dateStr, &action.Type, &amount, &numerator, &denominator); err != nil {
+			return nil, fmt.Errorf("failed to scan corporate action: %w", err)
+		}
+
+		action.Date, err = parseStoredDate(dateStr)
+		if err != nil {
+			return nil, err
+		}
+		action.Amount = amount.Float64
+		action.Numerator = numerator.Float64
+		action.Denominator = denominator.Float64
+
+		actions = append(actions, action)
+	}
+
+	return actions, rows.Err()
+}
+
+// FetchUpcomingEvents passes through to the upstream event calendar; the
+// event store keeps its own copy of the results
+func (s *BarStore) FetchUpcomingEvents(ctx context.Context, symbol string) ([]models.CorporateEvent, error) {
+	calendar, ok := s.upstream.(EventCalendar)
+	if !ok {
+		return nil, fmt.Errorf("%s does not support event calendars", s.upstream.Name())
+	}
+	return calendar.FetchUpcomingEvents(ctx, symbol)
+}
+
+// HealthCheck verifies both the database and the upstream provider
+func (s *BarStore) HealthCheck(ctx context.Context) error {
+	if err := s.db.Ping(); err != nil {
+		return fmt.Errorf("bar store unavailable: %w", err)
+	}
+	if err := s.upstream.HealthCheck(ctx); err != nil {
+		return fmt.Errorf("upstream %s unhealthy (serving stored bars): %w", s.upstream.Name(), err)
+	}
+	return nil
+}
+
+// BreakerSnapshots reports circuit breaker state from the upstream
+func (s *BarStore) BreakerSnapshots() []BreakerSnapshot {
+	if reporter, ok := s.upstream.(BreakerReporter); ok {
+		return reporter.BreakerSnapshots()
+	}
+	return nil
+}
+
+// ensureSynced runs a sync unless the symbol was synced recently
+func (s *BarStore) ensureSynced(ctx context.Context, symbol string) error {
+	s.mutex.Lock()
+	last, ok := s.lastSync[symbol]
+	s.mutex.Unlock()
+
+	if ok && time.Since(last) < s.syncInterval {
+		return nil
+	}
+
+	if _, err := s.Sync(ctx, symbol); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"symbol": symbol,
+			"error":  err,
+		}).Warn("Price bar sync failed, serving stored bars")
+		return err
+	}
+
+	return nil
+}
+
+// symbolLock returns the mutex serialising syncs for one symbol
+func (s *BarStore) symbolLock(symbol string) *sync.Mutex {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	lock, ok := s.locks[symbol]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.locks[symbol] = lock
+	}
+	return lock
+}
+
+// lastStoredDate returns the most recent stored bar date for a symbol
+func (s *BarStore) lastStoredDate(symbol string) (time.Time, bool, error) {
+	var dateStr sql.NullString
+	err := s.db.QueryRow(`SELECT MAX(date) FROM price_bars WHERE symbol = ?`, symbol).Scan(&dateStr)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to query last stored bar: %w", err)
+	}
+	if !dateStr.Valid {
+		return time.Time{}, false, nil
+	}
+
+	date, err := parseStoredDate(dateStr.String)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return date, true, nil
+}
+
+// upsertBars writes bars in a single transaction
+func (s *BarStore) upsertBars(symbol string, bars []models.StockData) error {
+	if len(bars) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO price_bars (symbol, date, open, high, low, close, adj_close, volume, filled, source, fetched_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(symbol, date) DO UPDATE SET
+			open = excluded.open,
+			high = excluded.high,
+			low = excluded.low,
+			close = excluded.close,
+			adj_close = excluded.adj_close,
+			volume = excluded.volume,
+			filled = excluded.filled,
+			source = excluded.source,
+			fetched_at = excluded.fetched_at
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare bar upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, bar := range bars {
+		if bar.Close <= 0 {
+			continue
+		}
+		_, err := stmt.Exec(
+			symbol, barDate(bar.Timestamp).Format("2006-01-02"),
+			bar.Open, bar.High, bar.Low, bar.Close, nullablePrice(bar.AdjClose), bar.Volume, bar.Filled,
+			s.upstream.Name(), now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store bar for %s: %w", symbol, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// upsertActions stores corporate actions and returns how many were new
+func (s *BarStore) upsertActions(symbol string, actions []models.CorporateAction) (int, error) {
+	inserted := 0
+	for _, action := range actions {
+		result, err := s.db.Exec(`
+			INSERT OR IGNORE INTO corporate_actions (symbol, ex_date, action_type, amount, numerator, denominator, source)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, symbol, action.Date.Format("2006-01-02"), action.Type,
+			nullablePrice(action.Amount), nullablePrice(action.Numerator), nullablePrice(action.Denominator),
+			s.upstream.Name())
+		if err != nil {
+			return inserted, fmt.Errorf("failed to store corporate action for %s: %w", symbol, err)
+		}
+
+		if n, err := result.RowsAffected(); err == nil {
+			inserted += int(n)
+		}
+	}
+	return inserted, nil
+}
+
+// loadBars returns the last N stored bars in ascending date order
+func (s *BarStore) loadBars(symbol string, limit int) ([]models.StockData, error) {
+	rows, err := s.db.Query(`
+		SELECT date, open, high, low, close, adj_close, volume, filled
+		FROM (
+			SELECT date, open, high, low, close, adj_close, volume, filled
+			FROM price_bars
+			WHERE symbol = ?
+			ORDER BY date DESC
+			LIMIT ?
+		)
+		ORDER BY date ASC
+	`, symbol, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stored bars: %w", err)
+	}
+	defer rows.Close()
+
+	// Currency is a property of the listing, so it is not stored per bar
+	currency := models.CurrencyOf(symbol)
+	var bars []models.StockData
+	for rows.Next() {
+		var dateStr string
+		var open, high, low, adjClose sql.NullFloat64
+		var volume sql.NullInt64
+		bar := models.StockData{Symbol: symbol, Interval: models.Interval1d, Currency: currency}
+
+		if err := rows.Scan(&dateStr, &open, &high, &low, &bar.Close, &adjClose, &volume, &bar.Filled); err != nil {
+			return nil, fmt.Errorf("failed to scan stored bar: %w", err)
+		}
+
+		bar.Timestamp, err = parseStoredDate(dateStr)
+		if err != nil {
+			return nil, err
+		}
+		bar.Open = open.Float64
+		bar.High = high.Float64
+		bar.Low = low.Float64
+		bar.AdjClose = adjClose.Float64
+		bar.Volume = volume.Int64
+
+		bars = append(bars, bar)
+	}
+
+	return bars, rows.Err()
+}
+
+// nullablePrice stores zero values as NULL
+func nullablePrice(value float64) interface{} {
+	if value == 0 {
+		return nil
+	}
+	return value
+}
+
+// barDate truncates a bar timestamp to its UTC calendar date
+func barDate(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// parseStoredDate parses DATE values as returned by the SQLite driver
+func parseStoredDate(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "2006-01-02T15:04:05Z", "2006-01-02T15:04:05.000Z"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("failed to parse stored date '%s'", value)
+}
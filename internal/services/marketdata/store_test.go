@@ -0,0 +1,165 @@
+package marketdata
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"stock-prediction-us/internal/models"
+)
+
+// barsProvider serves a fixed bar series and records requested lookbacks
+type barsProvider struct {
+	bars     []models.StockData
+	actions  []models.CorporateAction
+	requests []int
+	err      error
+}
+
+func (p *barsProvider) Name() string { return "fixture" }
+
+func (p *barsProvider) FetchLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	return p.bars[len(p.bars)-1].Close, nil
+}
+
+func (p *barsProvider) FetchStockData(ctx context.Context, symbol string, period string, interval models.Interval) ([]float64, error) {
+	return nil, fmt.Errorf("not used")
+}
+
+func (p *barsProvider) FetchHistoricalData(ctx context.Context, symbol string, days int, interval models.Interval) ([]models.StockData, error) {
+	p.requests = append(p.requests, days)
+	if p.err != nil {
+		return nil, p.err
+	}
+	if len(p.bars) > days {
+		return p.bars[len(p.bars)-days:], nil
+	}
+	return p.bars, nil
+}
+
+func (p *barsProvider) FetchCorporateActions(ctx context.Context, symbol string, days int) ([]models.CorporateAction, error) {
+	return p.actions, p.err
+}
+
+func (p *barsProvider) HealthCheck(ctx context.Context) error { return p.err }
+
+func newTestStore(t *testing.T, upstream MarketDataProvider) *BarStore {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	for _, name := range []string{"002_price_bars.sql", "003_corporate_actions.sql", "004_price_bar_quality.sql", "005_symbol_metadata.sql"} {
+		migration, err := os.ReadFile("../../database/migrations/" + name)
+		require.NoError(t, err)
+		_, err = db.Exec(string(migration))
+		require.NoError(t, err)
+	}
+
+	return NewBarStore(db, upstream, logrus.New(), 0, 730)
+}
+
+func dailyBars(start time.Time, closes ...float64) []models.StockData {
+	bars := make([]models.StockData, len(closes))
+	for i, c := range closes {
+		bars[i] = models.StockData{
+			Symbol:    "NVDA",
+			Timestamp: start.AddDate(0, 0, i).Add(13*time.Hour + 30*time.Minute),
+			Open:      c, High: c, Low: c, Close: c,
+			Volume: 1000,
+		}
+	}
+	return bars
+}
+
+func TestBarStoreIncrementalSync(t *testing.T) {
+	ctx := context.Background()
+	start := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -4)
+	upstream := &barsProvider{bars: dailyBars(start, 100, 101, 102)}
+	store := newTestStore(t, upstream)
+
+	stored, err := store.Sync(ctx, "NVDA")
+	require.NoError(t, err)
+	assert.Equal(t, 3, stored)
+	assert.Equal(t, 730, upstream.requests[0])
+
+	// Second sync only asks for the days since the last stored bar
+	upstream.bars = dailyBars(start, 100, 101, 102.5, 103)
+	stored, err = store.Sync(ctx, "NVDA")
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored)
+	assert.Less(t, upstream.requests[1], 10)
+
+	closes, err := store.FetchStockData(ctx, "NVDA", "1mo", models.Interval1d)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{100, 101, 102.5, 103}, closes)
+}
+
+func TestBarStoreServesCachedBarsWhenUpstreamDown(t *testing.T) {
+	ctx := context.Background()
+	start := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -3)
+	upstream := &barsProvider{bars: dailyBars(start, 50, 51)}
+	store := newTestStore(t, upstream)
+
+	_, err := store.Sync(ctx, "NVDA")
+	require.NoError(t, err)
+
+	upstream.err = fmt.Errorf("HTTP 429")
+
+	bars, err := store.FetchHistoricalData(ctx, "NVDA", 5, models.Interval1d)
+	require.NoError(t, err)
+	assert.Len(t, bars, 2)
+
+	price, err := store.FetchLatestPrice(ctx, "NVDA")
+	require.NoError(t, err)
+	assert.Equal(t, 51.0, price)
+
+	_, err = store.FetchHistoricalData(ctx, "AAPL", 5, models.Interval1d)
+	assert.ErrorContains(t, err, "HTTP 429")
+}
+
+func TestBarStoreRebackfillsOnNewSplit(t *testing.T) {
+	ctx := context.Background()
+	start := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -4)
+	upstream := &barsProvider{bars: dailyBars(start, 1000, 1010, 1020)}
+	store := newTestStore(t, upstream)
+
+	_, err := store.Sync(ctx, "NVDA")
+	require.NoError(t, err)
+
+	// A 10:1 split restates every earlier adjusted close
+	upstream.bars = dailyBars(start, 1000, 1010, 1020, 103)
+	for i := 0; i < 3; i++ {
+		upstream.bars[i].AdjClose = upstream.bars[i].Close / 10
+	}
+	upstream.actions = []models.CorporateAction{{
+		Symbol: "NVDA", Date: barDate(upstream.bars[3].Timestamp),
+		Type: models.ActionSplit, Numerator: 10, Denominator: 1,
+	}}
+
+	stored, err := store.Sync(ctx, "NVDA")
+	require.NoError(t, err)
+	assert.Equal(t, 4, stored)
+	assert.Equal(t, 730, upstream.requests[len(upstream.requests)-1])
+
+	bars, err := store.FetchHistoricalData(ctx, "NVDA", 10, models.Interval1d)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{100, 101, 102, 103}, models.ClosePrices(bars, true))
+	assert.Equal(t, []float64{1000, 1010, 1020, 103}, models.ClosePrices(bars, false))
+
+	actions, err := store.FetchCorporateActions(ctx, "NVDA", 30)
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	assert.Equal(t, 10.0, actions[0].SplitRatio())
+}
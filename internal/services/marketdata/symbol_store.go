@@ -0,0 +1,261 @@
+package marketdata
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"stock-prediction-us/internal/models"
+)
+
+// metadataTTL is how long stored symbol metadata is served before the
+// upstream is asked again
+const metadataTTL = 7 * 24 * time.Hour
+
+// FetchSymbolMetadata returns stored metadata, refreshing it from the
+// upstream when missing or older than metadataTTL. Stale metadata is still
+// served when the upstream is unavailable.
+func (s *BarStore) FetchSymbolMetadata(ctx context.Context, symbol string) (*models.SymbolMetadata, error) {
+	if err := models.ValidateSymbol(symbol); err != nil {
+		return nil, fmt.Errorf("invalid symbol: %w", err)
+	}
+
+	stored, err := s.loadMetadata(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil && time.Since(stored.UpdatedAt) < metadataTTL {
+		return stored, nil
+	}
+
+	metadata, err := s.refreshMetadata(ctx, symbol)
+	if err == nil {
+		return metadata, nil
+	}
+	if stored != nil && !errors.Is(err, ErrSymbolNotFound) {
+		s.logger.WithFields(logrus.Fields{
+			"symbol": symbol,
+			"error":  err,
+		}).Warn("Symbol metadata refresh failed, serving stored metadata")
+		return stored, nil
+	}
+	return nil, err
+}
+
+// SearchSymbols matches stored symbols and names first and asks the upstream
+// only when the store has fewer than limit matches. Upstream results are
+// stored without overwriting richer metadata captured from bar syncs.
+func (s *BarStore) SearchSymbols(ctx context.Context, query string, limit int) ([]models.SymbolMetadata, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("search query cannot be empty")
+	}
+
+	results, err := s.searchMetadata(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) >= limit {
+		return results, nil
+	}
+
+	directory, ok := s.upstream.(SymbolDirectory)
+	if !ok {
+		return results, nil
+	}
+
+	remote, err := directory.SearchSymbols(ctx, query, limit)
+	if err != nil {
+		if len(results) > 0 {
+			s.logger.WithFields(logrus.Fields{
+				"query": query,
+				"error": err,
+			}).Warn("Symbol search failed upstream, serving stored matches")
+			return results, nil
+		}
+		return nil, fmt.Errorf("symbol search failed on %s: %w", s.upstream.Name(), err)
+	}
+
+	seen := make(map[string]bool, len(results))
+	for _, metadata := range results {
+		seen[metadata.Symbol] = true
+	}
+	for _, metadata := range remote {
+		if seen[metadata.Symbol] {
+			continue
+		}
+		if err := s.insertMetadataIfMissing(metadata); err != nil {
+			return nil, err
+		}
+		seen[metadata.Symbol] = true
+		results = append(results, metadata)
+		if len(results) == limit {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+// captureMetadata stores metadata during a bar sync when it is missing or
+// stale. The upstream usually has it from the chart it just served, so this
+// rarely costs a request; failures are only logged.
+func (s *BarStore) captureMetadata(ctx context.Context, symbol string) {
+	stored, err := s.loadMetadata(symbol)
+	if err == nil && stored != nil && time.Since(stored.UpdatedAt) < metadataTTL {
+		return
+	}
+	if _, err := s.refreshMetadata(ctx, symbol); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"symbol": symbol,
+			"error":  err,
+		}).Debug("Symbol metadata not captured")
+	}
+}
+
+// refreshMetadata fetches metadata from the upstream and stores it
+func (s *BarStore) refreshMetadata(ctx context.Context, symbol string) (*models.SymbolMetadata, error) {
+	directory, ok := s.upstream.(SymbolDirectory)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s (%s does not support symbol lookup)", ErrSymbolNotFound, symbol, s.upstream.Name())
+	}
+
+	metadata, err := directory.FetchSymbolMetadata(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	metadata.UpdatedAt = time.Now()
+
+	if err := s.upsertMetadata(*metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// metadataColumns is the column list shared by metadata reads and writes
+const metadataColumns = `symbol, long_name, short_name, asset_class, exchange_code, exchange,
+	currency, timezone, instrument_type, first_trade_date, source, updated_at`
+
+// upsertMetadata inserts or replaces the stored metadata for a symbol
+func (s *BarStore) upsertMetadata(metadata models.SymbolMetadata) error {
+	_, err := s.db.Exec(`
+		INSERT INTO symbol_metadata (`+metadataColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(symbol) DO UPDATE SET
+			long_name = excluded.long_name,
+			short_name = excluded.short_name,
+			asset_class = excluded.asset_class,
+			exchange_code = excluded.exchange_code,
+			exchange = excluded.exchange,
+			currency = excluded.currency,
+			timezone = excluded.timezone,
+			instrument_type = excluded.instrument_type,
+			first_trade_date = excluded.first_trade_date,
+			source = excluded.source,
+			updated_at = excluded.updated_at
+	`, metadataArgs(metadata)...)
+	if err != nil {
+		return fmt.Errorf("failed to store metadata for %s: %w", metadata.Symbol, err)
+	}
+	return nil
+}
+
+// insertMetadataIfMissing stores search results without replacing existing rows
+func (s *BarStore) insertMetadataIfMissing(metadata models.SymbolMetadata) error {
+	_, err := s.db.Exec(`
+		INSERT OR IGNORE INTO symbol_metadata (`+metadataColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, metadataArgs(metadata)...)
+	if err != nil {
+		return fmt.Errorf("failed to store metadata for %s: %w", metadata.Symbol, err)
+	}
+	return nil
+}
+
+// metadataArgs returns the values for metadataColumns
+func metadataArgs(metadata models.SymbolMetadata) []interface{} {
+	var firstTrade interface{}
+	if metadata.FirstTradeDate != nil {
+		firstTrade = metadata.FirstTradeDate.Format("2006-01-02")
+	}
+	return []interface{}{
+		metadata.Symbol, metadata.LongName, metadata.ShortName, metadata.AssetClass,
+		metadata.ExchangeCode, metadata.Exchange, metadata.Currency, metadata.Timezone,
+		metadata.InstrumentType, firstTrade, metadata.Source, metadata.UpdatedAt.UTC(),
+	}
+}
+
+// loadMetadata returns stored metadata for a symbol, or nil if none is stored
+func (s *BarStore) loadMetadata(symbol string) (*models.SymbolMetadata, error) {
+	rows, err := s.db.Query(`SELECT `+metadataColumns+` FROM symbol_metadata WHERE symbol = ?`, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query symbol metadata: %w", err)
+	}
+	defer rows.Close()
+
+	results, err := scanMetadata(rows)
+	if err != nil || len(results) == 0 {
+		return nil, err
+	}
+	return &results[0], nil
+}
+
+// searchMetadata matches stored symbols by prefix and names by substring,
+// exact and prefix symbol matches first
+func (s *BarStore) searchMetadata(query string, limit int) ([]models.SymbolMetadata, error) {
+	upper := strings.ToUpper(query)
+	pattern := "%" + escapeLike(query) + "%"
+	rows, err := s.db.Query(`
+		SELECT `+metadataColumns+` FROM symbol_metadata
+		WHERE symbol LIKE ? ESCAPE '\' OR long_name LIKE ? ESCAPE '\' OR short_name LIKE ? ESCAPE '\'
+		ORDER BY CASE WHEN symbol = ? THEN 0 WHEN symbol LIKE ? ESCAPE '\' THEN 1 ELSE 2 END, symbol
+		LIMIT ?
+	`, escapeLike(upper)+"%", pattern, pattern, upper, escapeLike(upper)+"%", limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search symbol metadata: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMetadata(rows)
+}
+
+// scanMetadata reads rows selected with metadataColumns
+func scanMetadata(rows *sql.Rows) ([]models.SymbolMetadata, error) {
+	results := []models.SymbolMetadata{}
+	for rows.Next() {
+		var metadata models.SymbolMetadata
+		var longName, shortName, exchange, currency, timezone, instrumentType, firstTrade sql.NullString
+		if err := rows.Scan(&metadata.Symbol, &longName, &shortName, &metadata.AssetClass,
+			&metadata.ExchangeCode, &exchange, &currency, &timezone, &instrumentType,
+			&firstTrade, &metadata.Source, &metadata.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan symbol metadata: %w", err)
+		}
+
+		metadata.LongName = longName.String
+		metadata.ShortName = shortName.String
+		metadata.Exchange = exchange.String
+		metadata.Currency = currency.String
+		metadata.Timezone = timezone.String
+		metadata.InstrumentType = instrumentType.String
+		if firstTrade.Valid && firstTrade.String != "" {
+			date, err := parseStoredDate(firstTrade.String)
+			if err != nil {
+				return nil, err
+			}
+			metadata.FirstTradeDate = &date
+		}
+
+		results = append(results, metadata)
+	}
+	return results, rows.Err()
+}
+
+// escapeLike escapes LIKE wildcards in user input
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
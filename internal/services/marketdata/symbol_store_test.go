@@ -0,0 +1,106 @@
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-prediction-us/internal/models"
+)
+
+// directoryProvider adds a symbol directory to barsProvider
+type directoryProvider struct {
+	barsProvider
+	metadata map[string]models.SymbolMetadata
+	lookups  int
+	searches int
+}
+
+func (p *directoryProvider) FetchSymbolMetadata(ctx context.Context, symbol string) (*models.SymbolMetadata, error) {
+	p.lookups++
+	if p.err != nil {
+		return nil, p.err
+	}
+	metadata, ok := p.metadata[symbol]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+	return &metadata, nil
+}
+
+func (p *directoryProvider) SearchSymbols(ctx context.Context, query string, limit int) ([]models.SymbolMetadata, error) {
+	p.searches++
+	if p.err != nil {
+		return nil, p.err
+	}
+	var results []models.SymbolMetadata
+	for _, metadata := range p.metadata {
+		if strings.Contains(strings.ToLower(metadata.LongName), strings.ToLower(query)) {
+			results = append(results, metadata)
+		}
+	}
+	return results, nil
+}
+
+func testMetadata(ticker, name string) models.SymbolMetadata {
+	symbol, _ := models.ParseSymbol(ticker)
+	metadata := models.NewSymbolMetadata(symbol, "fixture")
+	metadata.LongName = name
+	metadata.InstrumentType = "EQUITY"
+	firstTrade := time.Date(1999, 1, 22, 0, 0, 0, 0, time.UTC)
+	metadata.FirstTradeDate = &firstTrade
+	return metadata
+}
+
+func TestBarStoreCapturesSymbolMetadata(t *testing.T) {
+	ctx := context.Background()
+	upstream := &directoryProvider{
+		barsProvider: barsProvider{bars: dailyBars(time.Now().AddDate(0, 0, -3), 100, 101, 102)},
+		metadata: map[string]models.SymbolMetadata{
+			"NVDA":    testMetadata("NVDA", "NVIDIA Corporation"),
+			"2330.TW": testMetadata("2330.TW", "Taiwan Semiconductor Manufacturing Company Limited"),
+		},
+	}
+	store := newTestStore(t, upstream)
+
+	// A bar sync captures metadata without a separate lookup later
+	_, err := store.Sync(ctx, "NVDA")
+	require.NoError(t, err)
+	assert.Equal(t, 1, upstream.lookups)
+
+	metadata, err := store.FetchSymbolMetadata(ctx, "NVDA")
+	require.NoError(t, err)
+	assert.Equal(t, 1, upstream.lookups)
+	assert.Equal(t, "NVIDIA Corporation", metadata.LongName)
+	assert.Equal(t, models.AssetEquity, metadata.AssetClass)
+	require.NotNil(t, metadata.FirstTradeDate)
+	assert.Equal(t, "1999-01-22", metadata.FirstTradeDate.Format("2006-01-02"))
+
+	// Unknown symbols report ErrSymbolNotFound
+	_, err = store.FetchSymbolMetadata(ctx, "ZZZZ")
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+
+	// Stored matches are served locally; the upstream fills the remainder
+	results, err := store.SearchSymbols(ctx, "nvd", 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "NVDA", results[0].Symbol)
+	assert.Equal(t, 0, upstream.searches)
+
+	results, err = store.SearchSymbols(ctx, "taiwan", 5)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "TWSE", results[0].ExchangeCode)
+	assert.Equal(t, 1, upstream.searches)
+
+	// Search results are persisted for later lookups
+	upstream.err = fmt.Errorf("upstream down")
+	metadata, err = store.FetchSymbolMetadata(ctx, "2330.TW")
+	require.NoError(t, err)
+	assert.Equal(t, "TWD", metadata.Currency)
+}
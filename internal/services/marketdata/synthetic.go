@@ -0,0 +1,394 @@
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"stock-prediction-us/internal/models"
+)
+
+// SyntheticSettings configures the generated price process
+type SyntheticSettings struct {
+	Drift         float64 // Annualised drift of log prices, e.g. 0.08
+	Volatility    float64 // Annualised volatility, e.g. 0.35
+	JumpIntensity float64 // Expected jumps per year
+	JumpSize      float64 // Standard deviation of a jump in log price
+	BaseVolume    int64   // Typical daily volume
+	Seed          int64   // Combined with the symbol so each symbol has its own path
+}
+
+// syntheticEpoch is the first generated session. Paths always start here, so
+// a given symbol and date produce the same bar whenever they are requested.
+var syntheticEpoch = time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC)
+
+// SyntheticProvider generates OHLCV series from a geometric Brownian motion
+// with Poisson jumps, for demos and load tests without network access.
+// Sessions follow the symbol's trading calendar and intraday bars are a
+// Brownian bridge between the session open and close with a U-shaped volume
+// profile.
+type SyntheticProvider struct {
+	settings SyntheticSettings
+	now      func() time.Time
+}
+
+// NewSyntheticProvider creates a synthetic provider
+func NewSyntheticProvider(settings SyntheticSettings) *SyntheticProvider {
+	if settings.Volatility <= 0 {
+		settings.Volatility = 0.3
+	}
+	if settings.BaseVolume <= 0 {
+		settings.BaseVolume = 1_000_000
+	}
+	return &SyntheticProvider{
+		settings: settings,
+		now:      time.Now,
+	}
+}
+
+// Name returns the provider identifier
+func (p *SyntheticProvider) Name() string {
+	return "synthetic"
+}
+
+// FetchLatestPrice returns the close of the latest generated session
+func (p *SyntheticProvider) FetchLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	bars, err := p.FetchHistoricalData(ctx, symbol, 1, models.Interval1d)
+	if err != nil {
+		return 0, err
+	}
+	return bars[len(bars)-1].Close, nil
+}
+
+// FetchStockData returns closes covering the requested period
+func (p *SyntheticProvider) FetchStockData(ctx context.Context, symbol string, period string, interval models.Interval) ([]float64, error) {
+	days, err := PeriodToDays(period)
+	if err != nil {
+		return nil, err
+	}
+
+	bars, err := p.FetchHistoricalData(ctx, symbol, days, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := p.now().AddDate(0, 0, -days)
+	var closes []float64
+	for _, bar := range bars {
+		if bar.Timestamp.After(cutoff) {
+			closes = append(closes, bar.Close)
+		}
+	}
+
+	if err := models.ValidateStockData(closes, 1); err != nil {
+		return nil, fmt.Errorf("invalid stock data: %w", err)
+	}
+	return closes, nil
+}
+
+// FetchHistoricalData returns bars for the last N sessions
+func (p *SyntheticProvider) FetchHistoricalData(ctx context.Context, symbol string, days int, interval models.Interval) ([]models.StockData, error) {
+	parsed, err := models.ParseSymbol(symbol)
+	if err != nil || parsed.Ticker != symbol {
+		return nil, fmt.Errorf("invalid symbol: %w", models.ValidateSymbol(symbol))
+	}
+	if days < 1 {
+		days = 1
+	}
+
+	daily := p.dailyBars(parsed)
+	if len(daily) == 0 {
+		return nil, fmt.Errorf("no synthetic sessions for %s yet", symbol)
+	}
+	if len(daily) > days {
+		daily = daily[len(daily)-days:]
+	}
+	if !interval.IsIntraday() {
+		return daily, nil
+	}
+
+	var bars []models.StockData
+	for _, session := range daily {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		bars = append(bars, p.intradayBars(parsed, session, interval)...)
+	}
+	if len(bars) == 0 {
+		return nil, fmt.Errorf("no synthetic %s bars for %s yet", interval, symbol)
+	}
+	return bars, nil
+}
+
+// FetchCorporateActions returns no actions; synthetic series are never split
+func (p *SyntheticProvider) FetchCorporateActions(ctx context.Context, symbol string, days int) ([]models.CorporateAction, error) {
+	return nil, nil
+}
+
+// FetchUpcomingEvents schedules quarterly earnings for equities on a stable
+// per-symbol weekday a few weeks into each calendar quarter, so sandbox
+// predictions exercise event flagging. The next two releases are returned.
+func (p *SyntheticProvider) FetchUpcomingEvents(ctx context.Context, symbol string) ([]models.CorporateEvent, error) {
+	parsed, err := models.ParseSymbol(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+	if parsed.AssetClass != models.AssetEquity {
+		return []models.CorporateEvent{}, nil
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(parsed.Ticker))
+	offset := int(h.Sum32() % 20) // Days past the fourth week of the quarter
+	timing := models.TimingAfterClose
+	if h.Sum32()%3 == 0 {
+		timing = models.TimingBeforeOpen
+	}
+
+	now := p.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	quarter := time.Date(now.Year(), ((now.Month()-1)/3)*3+1, 1, 0, 0, 0, 0, time.UTC)
+
+	events := []models.CorporateEvent{}
+	for len(events) < 2 {
+		date := quarter.AddDate(0, 0, 21+offset)
+		for date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			date = date.AddDate(0, 0, 1)
+		}
+		if !date.Before(today) {
+			events = append(events, models.CorporateEvent{
+				Symbol:      parsed.Ticker,
+				Date:        date,
+				Type:        models.EventEarnings,
+				Timing:      timing,
+				Description: "Synthetic quarterly earnings",
+				Source:      p.Name(),
+				UpdatedAt:   now,
+			})
+		}
+		quarter = quarter.AddDate(0, 3, 0)
+	}
+	return events, nil
+}
+
+// HealthCheck always succeeds
+func (p *SyntheticProvider) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+// FetchSymbolMetadata describes any parsable symbol as a synthetic instrument
+func (p *SyntheticProvider) FetchSymbolMetadata(ctx context.Context, symbol string) (*models.SymbolMetadata, error) {
+	parsed, err := models.ParseSymbol(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+	metadata := p.metadata(parsed)
+	return &metadata, nil
+}
+
+// SearchSymbols matches the query itself when it is a valid ticker
+func (p *SyntheticProvider) SearchSymbols(ctx context.Context, query string, limit int) ([]models.SymbolMetadata, error) {
+	parsed, err := models.ParseSymbol(query)
+	if err != nil || limit < 1 {
+		return []models.SymbolMetadata{}, nil
+	}
+	return []models.SymbolMetadata{p.metadata(parsed)}, nil
+}
+
+// metadata returns the synthetic description of a symbol
+func (p *SyntheticProvider) metadata(symbol models.Symbol) models.SymbolMetadata {
+	metadata := models.NewSymbolMetadata(symbol, p.Name())
+	metadata.LongName = "Synthetic " + symbol.Ticker
+	metadata.ShortName = symbol.Ticker
+	metadata.InstrumentType = strings.ToUpper(string(symbol.AssetClass))
+	firstTrade := syntheticEpoch
+	metadata.FirstTradeDate = &firstTrade
+	return metadata
+}
+
+// dailyBars generates every session from syntheticEpoch up to now
+func (p *SyntheticProvider) dailyBars(symbol models.Symbol) []models.StockData {
+	rng := rand.New(rand.NewSource(p.seed(symbol.Ticker, "1d")))
+	loc := symbol.Exchange.Location()
+	now := p.now()
+
+	dt := 1.0 / 252
+	if symbol.AssetClass == models.AssetCrypto {
+		dt = 1.0 / 365
+	}
+	volatility, drift, jumpSize := p.settings.Volatility, p.settings.Drift, p.settings.JumpSize
+	price := p.startPrice(symbol.Ticker)
+	if symbol.AssetClass == models.AssetCurrency {
+		// Currency pairs start near a realistic level and move far less than
+		// equities so converted sandbox prices stay plausible
+		if rate, ok := syntheticFXRate(symbol); ok {
+			price = rate
+		}
+		volatility, drift, jumpSize = volatility/4, 0, jumpSize/4
+	}
+	sigma := volatility * math.Sqrt(dt)
+	mu := (drift - volatility*volatility/2) * dt
+
+	var bars []models.StockData
+	for day := syntheticEpoch; ; day = day.AddDate(0, 0, 1) {
+		open := sessionOpen(symbol, day, loc)
+		if open.After(now) {
+			break
+		}
+		if !symbol.IsTradingDay(day) {
+			continue
+		}
+
+		shock := rng.NormFloat64()
+		logReturn := mu + sigma*shock
+		jumped := p.settings.JumpIntensity > 0 && rng.Float64() < p.settings.JumpIntensity*dt
+		if jumped {
+			logReturn += jumpSize * rng.NormFloat64()
+		}
+
+		// Overnight gap, then the session move; wicks extend past both
+		openPrice := price * math.Exp(0.2*sigma*rng.NormFloat64())
+		closePrice := price * math.Exp(logReturn)
+		high := math.Max(openPrice, closePrice) * math.Exp(0.5*sigma*math.Abs(rng.NormFloat64()))
+		low := math.Min(openPrice, closePrice) * math.Exp(-0.5*sigma*math.Abs(rng.NormFloat64()))
+
+		// Volume rises with the size of the move
+		volume := float64(p.settings.BaseVolume) * math.Exp(0.25*rng.NormFloat64()) * (1 + 0.5*math.Abs(shock))
+		if jumped {
+			volume *= 3
+		}
+
+		bars = append(bars, models.StockData{
+			Symbol:    symbol.Ticker,
+			Timestamp: open.UTC(),
+			Open:      roundPrice(openPrice),
+			High:      roundPrice(high),
+			Low:       roundPrice(low),
+			Close:     roundPrice(closePrice),
+			AdjClose:  roundPrice(closePrice),
+			Volume:    int64(volume),
+			Interval:  models.Interval1d,
+			Currency:  symbol.Currency(),
+		})
+		price = closePrice
+	}
+	return bars
+}
+
+// intradayBars splits a session into a Brownian bridge from its open to its
+// close, kept inside the session's high/low, with a U-shaped volume profile.
+// Bars after now are left out.
+func (p *SyntheticProvider) intradayBars(symbol models.Symbol, session models.StockData, interval models.Interval) []models.StockData {
+	rng := rand.New(rand.NewSource(p.seed(symbol.Ticker, string(interval)+session.Timestamp.Format("2006-01-02"))))
+	n := interval.BarsPerSession()
+	step := interval.Duration()
+	now := p.now()
+
+	// Random walk, then pin both ends to the session open and close
+	walk := make([]float64, n+1)
+	stepSigma := math.Log(session.High/session.Low) / 2 / math.Sqrt(float64(n))
+	for k := 1; k <= n; k++ {
+		walk[k] = walk[k-1] + stepSigma*rng.NormFloat64()
+	}
+	logOpen, logClose := math.Log(session.Open), math.Log(session.Close)
+	logHigh, logLow := math.Log(session.High), math.Log(session.Low)
+	path := make([]float64, n+1)
+	for k := 0; k <= n; k++ {
+		frac := float64(k) / float64(n)
+		x := logOpen + frac*(logClose-logOpen) + walk[k] - frac*walk[n]
+		path[k] = math.Max(logLow, math.Min(logHigh, x))
+	}
+
+	// Volume weights are heavier at the open and close
+	weights := make([]float64, n)
+	total := 0.0
+	for k := range weights {
+		centre := (float64(k)+0.5)/float64(n)*2 - 1
+		weights[k] = 1 + 1.5*centre*centre
+		total += weights[k]
+	}
+
+	var bars []models.StockData
+	for k := 0; k < n; k++ {
+		timestamp := session.Timestamp.Add(time.Duration(k) * step)
+		if timestamp.After(now) {
+			break
+		}
+		open, close := math.Exp(path[k]), math.Exp(path[k+1])
+		wick := math.Exp(0.3 * stepSigma * math.Abs(rng.NormFloat64()))
+		bars = append(bars, models.StockData{
+			Symbol:    symbol.Ticker,
+			Timestamp: timestamp,
+			Open:      roundPrice(open),
+			High:      roundPrice(math.Min(session.High, math.Max(open, close)*wick)),
+			Low:       roundPrice(math.Max(session.Low, math.Min(open, close)/wick)),
+			Close:     roundPrice(close),
+			Volume:    int64(float64(session.Volume) * weights[k] / total),
+			Interval:  interval,
+			Currency:  session.Currency,
+		})
+	}
+	return bars
+}
+
+// seed derives a per-symbol, per-series seed from the configured seed
+func (p *SyntheticProvider) seed(symbol, series string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(symbol + "|" + series))
+	return p.settings.Seed ^ int64(h.Sum64())
+}
+
+// startPrice picks a stable starting price between 20 and 500 per symbol
+func (p *SyntheticProvider) startPrice(symbol string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(symbol))
+	return 20 + float64(h.Sum32()%48000)/100
+}
+
+// syntheticUSDValues are rough USD values of one unit, used to seed
+// synthetic currency pairs
+var syntheticUSDValues = map[string]float64{
+	"USD": 1, "EUR": 1.1, "GBP": 1.3, "JPY": 0.0068, "TWD": 0.031,
+	"HKD": 0.128, "KRW": 0.00074, "CAD": 0.73, "AUD": 0.66,
+}
+
+// syntheticFXRate returns a plausible starting rate for a currency pair
+// such as EURUSD=X or TWD=X (USD/TWD)
+func syntheticFXRate(symbol models.Symbol) (float64, bool) {
+	pair := strings.TrimSuffix(symbol.Ticker, "=X")
+	base, quote := "USD", pair
+	if len(pair) == 6 {
+		base, quote = pair[:3], pair[3:]
+	}
+	baseValue, ok := syntheticUSDValues[base]
+	if !ok {
+		return 0, false
+	}
+	quoteValue, ok := syntheticUSDValues[quote]
+	if !ok {
+		return 0, false
+	}
+	return baseValue / quoteValue, true
+}
+
+// sessionOpen returns the session open for a date in the exchange time zone:
+// 09:30 for US listings, 09:00 elsewhere and midnight UTC for crypto
+func sessionOpen(symbol models.Symbol, day time.Time, loc *time.Location) time.Time {
+	switch {
+	case symbol.AssetClass == models.AssetCrypto:
+		return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	case symbol.IsUS():
+		return time.Date(day.Year(), day.Month(), day.Day(), 9, 30, 0, 0, loc)
+	default:
+		return time.Date(day.Year(), day.Month(), day.Day(), 9, 0, 0, 0, loc)
+	}
+}
+
+// roundPrice rounds to 4 decimals like vendor quotes
+func roundPrice(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
@@ -0,0 +1,118 @@
+package marketdata
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-prediction-us/internal/models"
+)
+
+func newTestSynthetic(seed int64, now time.Time) *SyntheticProvider {
+	provider := NewSyntheticProvider(SyntheticSettings{
+		Drift:         0.08,
+		Volatility:    0.35,
+		JumpIntensity: 3,
+		JumpSize:      0.05,
+		BaseVolume:    1_000_000,
+		Seed:          seed,
+	})
+	provider.now = func() time.Time { return now }
+	return provider
+}
+
+func TestSyntheticProviderIsDeterministic(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 21, 0, 0, 0, time.UTC)
+
+	first, err := newTestSynthetic(42, now).FetchHistoricalData(ctx, "NVDA", 30, models.Interval1d)
+	require.NoError(t, err)
+	second, err := newTestSynthetic(42, now).FetchHistoricalData(ctx, "NVDA", 30, models.Interval1d)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	// A later clock extends the series without changing earlier bars
+	later, err := newTestSynthetic(42, now.AddDate(0, 0, 7)).FetchHistoricalData(ctx, "NVDA", 35, models.Interval1d)
+	require.NoError(t, err)
+	assert.Equal(t, first, later[:30])
+
+	other, err := newTestSynthetic(7, now).FetchHistoricalData(ctx, "NVDA", 30, models.Interval1d)
+	require.NoError(t, err)
+	assert.NotEqual(t, first[29].Close, other[29].Close)
+
+	tsla, err := newTestSynthetic(42, now).FetchHistoricalData(ctx, "TSLA", 30, models.Interval1d)
+	require.NoError(t, err)
+	assert.NotEqual(t, first[29].Close, tsla[29].Close)
+}
+
+func TestSyntheticProviderBars(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 21, 0, 0, 0, time.UTC)
+	provider := newTestSynthetic(42, now)
+
+	daily, err := provider.FetchHistoricalData(ctx, "NVDA", 60, models.Interval1d)
+	require.NoError(t, err)
+	require.Len(t, daily, 60)
+	for _, bar := range daily {
+		assert.True(t, models.IsUSTradingDay(bar.Timestamp), "bar on non-trading day %s", bar.Timestamp)
+		assert.GreaterOrEqual(t, bar.High, bar.Open)
+		assert.GreaterOrEqual(t, bar.High, bar.Close)
+		assert.LessOrEqual(t, bar.Low, bar.Open)
+		assert.LessOrEqual(t, bar.Low, bar.Close)
+		assert.Positive(t, bar.Volume)
+	}
+	assert.True(t, models.AssessDataQuality("NVDA", daily, 0.25).IsClean())
+
+	// Crypto trades through the weekend
+	crypto, err := provider.FetchHistoricalData(ctx, "BTC-USD", 7, models.Interval1d)
+	require.NoError(t, err)
+	assert.Len(t, crypto, 7)
+
+	// Intraday bars stay inside their session and end at its close
+	intraday, err := provider.FetchHistoricalData(ctx, "NVDA", 2, models.Interval5m)
+	require.NoError(t, err)
+	require.Len(t, intraday, 2*models.Interval5m.BarsPerSession())
+	session := daily[len(daily)-1]
+	last := intraday[len(intraday)-1]
+	assert.InDelta(t, session.Close, last.Close, 0.0001)
+	for _, bar := range intraday[len(intraday)/2:] {
+		assert.LessOrEqual(t, bar.High, session.High)
+		assert.GreaterOrEqual(t, bar.Low, session.Low)
+	}
+
+	closes, err := provider.FetchStockData(ctx, "2330.TW", "1mo", models.Interval1d)
+	require.NoError(t, err)
+	assert.NotEmpty(t, closes)
+
+	_, err = provider.FetchHistoricalData(ctx, "nvda", 5, models.Interval1d)
+	assert.Error(t, err)
+}
+
+func TestSyntheticProviderSchedulesQuarterlyEarnings(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 21, 0, 0, 0, time.UTC)
+	provider := newTestSynthetic(42, now)
+
+	events, err := provider.FetchUpcomingEvents(ctx, "NVDA")
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	for _, event := range events {
+		assert.Equal(t, models.EventEarnings, event.Type)
+		assert.False(t, event.Date.Before(time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)))
+		assert.NotEqual(t, time.Saturday, event.Date.Weekday())
+		assert.NotEqual(t, time.Sunday, event.Date.Weekday())
+	}
+	assert.True(t, events[1].Date.After(events[0].Date))
+
+	again, err := newTestSynthetic(42, now).FetchUpcomingEvents(ctx, "NVDA")
+	require.NoError(t, err)
+	assert.Equal(t, events, again)
+
+	// Indices do not report earnings
+	index, err := provider.FetchUpcomingEvents(ctx, "^GSPC")
+	require.NoError(t, err)
+	assert.Empty(t, index)
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"stock-prediction-us/internal/models"
+	"stock-prediction-us/internal/services/prediction"
+)
+
+// Model registry errors, for handlers to map to status codes
+var (
+	ErrModelVersionNotFound   = errors.New("model version not found")
+	ErrModelVersionExists     = errors.New("model version already registered")
+	ErrInvalidModelTransition = errors.New("invalid model status change")
+	ErrInvalidModelArtifact   = errors.New("invalid model artifact")
+)
+
+// ModelRegistryService tracks versioned model artifacts through staging,
+// production and retirement. The production version of each model is
+// cached, since every prediction looks it up.
+type ModelRegistryService struct {
+	db   *sql.DB
+	root string // Directory every artifact must be under
+
+	mu         sync.RWMutex // Guards production
+	production map[string]*models.ModelVersion
+}
+
+// NewModelRegistryService creates a model registry for artifacts under
+// root and loads the current production versions
+func NewModelRegistryService(db *sql.DB, root string) *ModelRegistryService {
+	s := &ModelRegistryService{
+		db:         db,
+		root:       root,
+		production: make(map[string]*models.ModelVersion),
+	}
+	if err := s.reloadProduction(); err != nil {
+		log.Printf("Failed to load production model versions: %v", err)
+	}
+	return s
+}
+
+const modelVersionColumns = `id, name, version, artifact_path, sha256, training_start, training_end,
+			   metrics, status, created_at, promoted_at, rolled_back_at`
+
+// Register adds a model version in staging. The artifact must be under the
+// model root, and is recorded by its resolved path. Its SHA-256 is computed
+// from the file; a digest given in the request must match it.
+func (s *ModelRegistryService) Register(ctx context.Context, req models.RegisterModelRequest) (*models.ModelVersion, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	path, err := prediction.ResolveArtifact(s.root, req.ArtifactPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidModelArtifact, err)
+	}
+	req.ArtifactPath = path
+	digest, err := prediction.ArtifactSHA256(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidModelArtifact, err)
+	}
+	if req.SHA256 != "" && req.SHA256 != digest {
+		return nil, fmt.Errorf("%w: sha256 mismatch", ErrInvalidModelArtifact)
+	}
+
+	var metrics interface{}
+	if len(req.Metrics) > 0 {
+		encoded, err := json.Marshal(req.Metrics)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metrics: %v", err)
+		}
+		metrics = string(encoded)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO model_versions (name, version, artifact_path, sha256, training_start, training_end, metrics, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, req.Name, req.Version, nullString(req.ArtifactPath), nullString(digest),
+		nullDate(req.TrainingStart), nullDate(req.TrainingEnd), metrics, models.ModelStatusStaging)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return nil, fmt.Errorf("%w: %s %s", ErrModelVersionExists, req.Name, req.Version)
+		}
+		return nil, fmt.Errorf("failed to register model version: %v", err)
+	}
+
+	return s.Get(ctx, req.Name, req.Version)
+}
+
+// Get returns a registered model version
+func (s *ModelRegistryService) Get(ctx context.Context, name, version string) (*models.ModelVersion, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT `+modelVersionColumns+`
+		FROM model_versions
+		WHERE name = ? AND version = ?
+	`, name, version)
+
+	v, err := scanModelVersion(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s %s", ErrModelVersionNotFound, name, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model version: %v", err)
+	}
+	return &v, nil
+}
+
+// List returns the registered versions of a model, or of every model when
+// name is empty, newest first
+func (s *ModelRegistryService) List(ctx context.Context, name string) ([]models.ModelVersion, error) {
+	query := `SELECT ` + modelVersionColumns + ` FROM model_versions`
+	var args []interface{}
+	if name != "" {
+		query += ` WHERE name = ?`
+		args = append(args, name)
+	}
+	query += ` ORDER BY name, created_at DESC, id DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list model versions: %v", err)
+	}
+	defer rows.Close()
+
+	versions := []models.ModelVersion{}
+	for rows.Next() {
+		v, err := scanModelVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan model version: %v", err)
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// Promote puts a staging or retired version into production, retiring the
+// version it replaces. An artifact changed since registration is refused.
+func (s *ModelRegistryService) Promote(ctx context.Context, name, version string) (*models.ModelVersion, error) {
+	target, err := s.Get(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	if target.Status == models.ModelStatusProduction {
+		return nil, fmt.Errorf("%w: %s %s is already in production", ErrInvalidModelTransition, name, version)
+	}
+	if err := verifyArtifact(ctx, target); err != nil {
+		return nil, err
+	}
+
+	err = s.transition(ctx, name, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE model_versions SET status = ?, promoted_at = ? WHERE id = ?
+		`, models.ModelStatusProduction, time.Now(), target.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, name, version)
+}
+
+// Rollback retires the production version of a model and restores the
+// retired version promoted before it. Versions that were themselves rolled
+// back are skipped until promoted again.
+func (s *ModelRegistryService) Rollback(ctx context.Context, name string) (*models.ModelVersion, error) {
+	current, ok := s.Production(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s has no production version", ErrInvalidModelTransition, name)
+	}
+
+	// The previous version keeps its promotion time, so rolling back again
+	// goes further back rather than toggling between two versions
+	var previousVersion string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT version FROM model_versions
+		WHERE name = ? AND status = ? AND promoted_at IS NOT NULL AND promoted_at < ?
+			AND (rolled_back_at IS NULL OR rolled_back_at < promoted_at)
+		ORDER BY promoted_at DESC
+		LIMIT 1
+	`, name, models.ModelStatusRetired, current.PromotedAt).Scan(&previousVersion)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s has no earlier production version", ErrInvalidModelTransition, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find previous model version: %v", err)
+	}
+	previous, err := s.Get(ctx, name, previousVersion)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyArtifact(ctx, previous); err != nil {
+		return nil, err
+	}
+
+	err = s.transition(ctx, name, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `UPDATE model_versions SET rolled_back_at = ? WHERE id = ?`, time.Now(), current.ID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE model_versions SET status = ? WHERE id = ?`, models.ModelStatusProduction, previous.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, name, previous.Version)
+}
+
+// verifyArtifact checks that a version's artifact is unchanged since it
+// was registered
+func verifyArtifact(ctx context.Context, v *models.ModelVersion) error {
+	if v.ArtifactPath == "" {
+		return nil
+	}
+	digest, err := prediction.ArtifactSHA256(ctx, v.ArtifactPath)
+	if err != nil {
+		return fmt.Errorf("%w: artifact unreadable: %v", ErrInvalidModelTransition, err)
+	}
+	if digest != v.SHA256 {
+		return fmt.Errorf("%w: artifact %s changed since registration", ErrInvalidModelTransition, v.ArtifactPath)
+	}
+	return nil
+}
+
+// transition retires a model's production version and applies promote in
+// the same transaction, then refreshes the cache
+func (s *ModelRegistryService) transition(ctx context.Context, name string, promote func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `UPDATE model_versions SET status = ? WHERE name = ? AND status = ?`,
+		models.ModelStatusRetired, name, models.ModelStatusProduction)
+	if err != nil {
+		return fmt.Errorf("failed to retire production version: %v", err)
+	}
+	if err := promote(tx); err != nil {
+		return fmt.Errorf("failed to promote model version: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return s.reloadProduction()
+}
+
+// Production returns the version of a model in production, if any
+func (s *ModelRegistryService) Production(name string) (*models.ModelVersion, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.production[name]
+	return v, ok
+}
+
+// reloadProduction refreshes the cached production versions
+func (s *ModelRegistryService) reloadProduction() error {
+	rows, err := s.db.Query(`SELECT `+modelVersionColumns+` FROM model_versions WHERE status = ?`, models.ModelStatusProduction)
+	if err != nil {
+		return fmt.Errorf("failed to load production versions: %v", err)
+	}
+	defer rows.Close()
+
+	production := make(map[string]*models.ModelVersion)
+	for rows.Next() {
+		v, err := scanModelVersion(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan model version: %v", err)
+		}
+		production[v.Name] = &v
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.production = production
+	s.mu.Unlock()
+	return nil
+}
+
+func scanModelVersion(row rowScanner) (models.ModelVersion, error) {
+	var v models.ModelVersion
+	var artifactPath, digest, trainingStart, trainingEnd, metrics sql.NullString
+	var promotedAt, rolledBackAt sql.NullTime
+
+	err := row.Scan(&v.ID, &v.Name, &v.Version, &artifactPath, &digest, &trainingStart, &trainingEnd,
+		&metrics, &v.Status, &v.CreatedAt, &promotedAt, &rolledBackAt)
+	if err != nil {
+		return v, err
+	}
+
+	v.ArtifactPath = artifactPath.String
+	v.SHA256 = digest.String
+	v.TrainingStart = parseNullDate(trainingStart)
+	v.TrainingEnd = parseNullDate(trainingEnd)
+	if promotedAt.Valid {
+		v.PromotedAt = &promotedAt.Time
+	}
+	if rolledBackAt.Valid {
+		v.RolledBackAt = &rolledBackAt.Time
+	}
+	if metrics.Valid && metrics.String != "" {
+		if err := json.Unmarshal([]byte(metrics.String), &v.Metrics); err != nil {
+			return v, fmt.Errorf("invalid metrics for %s %s: %v", v.Name, v.Version, err)
+		}
+	}
+	return v, nil
+}
+
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func nullID(id int64) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+func nullDate(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.Format("2006-01-02")
+}
+
+func parseNullDate(s sql.NullString) *time.Time {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", s.String[:min(len(s.String), 10)])
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-prediction-us/internal/models"
+)
+
+func writeArtifact(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestModelRegistryLifecycle(t *testing.T) {
+	db := newTestDB(t, trackingMigrations...)
+	dir := t.TempDir()
+	registry := NewModelRegistryService(db, dir)
+	ctx := context.Background()
+
+	start, end := day(2024, 1, 2), day(2026, 9, 30)
+	first, err := registry.Register(ctx, models.RegisterModelRequest{
+		Name:          "Enhanced",
+		Version:       "1.0.0",
+		ArtifactPath:  writeArtifact(t, dir, "v1.pkl", "weights v1"),
+		TrainingStart: &start,
+		TrainingEnd:   &end,
+		Metrics:       map[string]float64{"mape": 1.8},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "enhanced", first.Name)
+	assert.Equal(t, models.ModelStatusStaging, first.Status)
+	assert.Equal(t, "13e35c44395f6cade670c076230362267c5288989d3498e207877d62b859a6d8", first.SHA256) // sha256 of "weights v1"
+	assert.Equal(t, start, *first.TrainingStart)
+	assert.Equal(t, end, *first.TrainingEnd)
+	assert.Equal(t, 1.8, first.Metrics["mape"])
+
+	_, err = registry.Register(ctx, models.RegisterModelRequest{Name: "enhanced", Version: "1.0.0", ArtifactPath: first.ArtifactPath})
+	assert.ErrorIs(t, err, ErrModelVersionExists)
+	_, err = registry.Register(ctx, models.RegisterModelRequest{Name: "enhanced", Version: "1.1", ArtifactPath: first.ArtifactPath})
+	assert.Error(t, err)
+	_, err = registry.Register(ctx, models.RegisterModelRequest{Name: "enhanced", Version: "1.1.0"})
+	assert.Error(t, err) // Every version names the artifact it serves
+	_, err = registry.Register(ctx, models.RegisterModelRequest{
+		Name: "enhanced", Version: "1.1.0",
+		ArtifactPath: filepath.Join(dir, "v1.pkl"),
+		SHA256:       "0000000000000000000000000000000000000000000000000000000000000000",
+	})
+	assert.ErrorIs(t, err, ErrInvalidModelArtifact)
+	assert.NotContains(t, err.Error(), first.SHA256) // The digest of a rejected file is not disclosed
+
+	// Artifacts outside the model root are refused before being read
+	outside := writeArtifact(t, t.TempDir(), "secret", "secret")
+	for _, path := range []string{outside, "../" + filepath.Base(dir) + "/../secret", "/dev/zero", "/"} {
+		_, err = registry.Register(ctx, models.RegisterModelRequest{Name: "enhanced", Version: "1.1.0", ArtifactPath: path})
+		assert.ErrorIs(t, err, ErrInvalidModelArtifact, path)
+	}
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "link.pkl")))
+	_, err = registry.Register(ctx, models.RegisterModelRequest{Name: "enhanced", Version: "1.1.0", ArtifactPath: "link.pkl"})
+	assert.ErrorIs(t, err, ErrInvalidModelArtifact)
+
+	// Directories hash their files, so a saved model can be registered;
+	// paths are relative to the model root
+	savedModel := filepath.Join(dir, "v2")
+	require.NoError(t, os.Mkdir(savedModel, 0o755))
+	writeArtifact(t, savedModel, "weights.bin", "weights v2")
+	second, err := registry.Register(ctx, models.RegisterModelRequest{Name: "enhanced", Version: "1.1.0", ArtifactPath: "v2"})
+	require.NoError(t, err)
+	assert.Equal(t, savedModel, second.ArtifactPath)
+	assert.NotEqual(t, first.SHA256, second.SHA256)
+	third, err := registry.Register(ctx, models.RegisterModelRequest{Name: "enhanced", Version: "2.0.0-rc.1",
+		ArtifactPath: writeArtifact(t, dir, "v2.pkl", "weights v2")})
+	require.NoError(t, err)
+
+	_, err = registry.Rollback(ctx, "enhanced")
+	assert.ErrorIs(t, err, ErrInvalidModelTransition)
+
+	promoted, err := registry.Promote(ctx, "enhanced", "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, models.ModelStatusProduction, promoted.Status)
+	_, err = registry.Promote(ctx, "enhanced", "1.0.0")
+	assert.ErrorIs(t, err, ErrInvalidModelTransition)
+
+	_, err = registry.Promote(ctx, "enhanced", "1.1.0")
+	require.NoError(t, err)
+	production, ok := registry.Production("enhanced")
+	require.True(t, ok)
+	assert.Equal(t, second.ID, production.ID)
+	retired, err := registry.Get(ctx, "enhanced", "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, models.ModelStatusRetired, retired.Status)
+
+	// Rolling back restores 1.0.0; there is nothing before it
+	restored, err := registry.Rollback(ctx, "enhanced")
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", restored.Version)
+	rolledBack, err := registry.Get(ctx, "enhanced", "1.1.0")
+	require.NoError(t, err)
+	assert.Equal(t, models.ModelStatusRetired, rolledBack.Status)
+	assert.NotNil(t, rolledBack.RolledBackAt)
+	_, err = registry.Rollback(ctx, "enhanced")
+	assert.ErrorIs(t, err, ErrInvalidModelTransition)
+
+	// A later rollback skips the version that was rolled back
+	time.Sleep(time.Millisecond)
+	_, err = registry.Promote(ctx, "enhanced", third.Version)
+	require.NoError(t, err)
+	restored, err = registry.Rollback(ctx, "enhanced")
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", restored.Version)
+
+	// An artifact changed after registration cannot be promoted or restored
+	_, err = registry.Promote(ctx, "enhanced", "1.1.0")
+	require.NoError(t, err)
+	writeArtifact(t, dir, "v1.pkl", "tampered")
+	_, err = registry.Rollback(ctx, "enhanced")
+	assert.ErrorIs(t, err, ErrInvalidModelTransition)
+	_, err = registry.Promote(ctx, "enhanced", "1.0.0")
+	assert.ErrorIs(t, err, ErrInvalidModelTransition)
+
+	versions, err := registry.List(ctx, "enhanced")
+	require.NoError(t, err)
+	assert.Len(t, versions, 3)
+	_, err = registry.Get(ctx, "enhanced", "9.9.9")
+	assert.ErrorIs(t, err, ErrModelVersionNotFound)
+
+	// A new service loads the production version from the database
+	production, ok = NewModelRegistryService(db, dir).Production("enhanced")
+	require.True(t, ok)
+	assert.Equal(t, "1.1.0", production.Version)
+}
+
+func TestTrackerRecordsModelVersion(t *testing.T) {
+	db := newTestDB(t, trackingMigrations...)
+	dir := t.TempDir()
+	registry := NewModelRegistryService(db, dir)
+	tracker := NewPredictionTrackerService(db, NewMarketCalendarService(db), nil, &barsProvider{}, nil, nil, nil)
+	ctx := context.Background()
+
+	_, err := registry.Register(ctx, models.RegisterModelRequest{Name: "lstm", Version: "1.0.0",
+		ArtifactPath: writeArtifact(t, dir, "lstm.keras", "weights")})
+	require.NoError(t, err)
+	version, err := registry.Promote(ctx, "lstm", "1.0.0")
+	require.NoError(t, err)
+
+	price := 100.0
+	_, err = tracker.CreatePrediction(models.CreatePredictionRequest{
+		Symbol:         "NVDA",
+		PredictionDate: day(2026, 10, 16),
+		PredictedPrice: &price,
+		Model:          "ensemble",
+		ModelVersion:   "v3.3.0-ensemble",
+		Members: []models.EnsembleMember{
+			{Model: "lstm", ModelVersion: version.Version, ModelVersionID: version.ID, PredictedPrice: 101, Weight: 0.5},
+			{Model: "simple", ModelVersion: "v3.3.0", PredictedPrice: 99, Weight: 0.5},
+		},
+	})
+	require.NoError(t, err)
+
+	p, err := tracker.GetPrediction("NVDA", day(2026, 10, 16), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "ensemble", p.Model)
+	assert.Equal(t, "v3.3.0-ensemble", p.ModelVersion)
+	assert.Nil(t, p.ModelVersionID) // Loads no artifact
+
+	memberVersion := func(model string) (string, *int64) {
+		var version string
+		var id *int64
+		require.NoError(t, db.QueryRow(`SELECT model_version, model_version_id FROM prediction_members WHERE prediction_id = ? AND model = ?`, p.ID, model).
+			Scan(&version, &id))
+		return version, id
+	}
+	lstmVersion, lstmID := memberVersion("lstm")
+	assert.Equal(t, "1.0.0", lstmVersion)
+	require.NotNil(t, lstmID)
+	assert.Equal(t, version.ID, *lstmID)
+	simpleVersion, simpleID := memberVersion("simple")
+	assert.Equal(t, "v3.3.0", simpleVersion)
+	assert.Nil(t, simpleID) // Unregistered
+}
@@ -0,0 +1,189 @@
+package prediction
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"stock-prediction-us/internal/models"
+)
+
+// MaxArtifactBytes caps the bytes hashed for one model artifact
+const MaxArtifactBytes int64 = 4 << 30
+
+// ResolveArtifact resolves an artifact path, relative to root unless
+// absolute, to the file or directory it names under root. Paths leaving
+// root, directly or through a symlink, are rejected.
+func ResolveArtifact(root, path string) (string, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	path = filepath.Clean(path)
+	if !within(root, path) {
+		return "", fmt.Errorf("artifact must be under the model root %s", root)
+	}
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("model root unavailable: %w", err)
+	}
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	if !within(realRoot, realPath) {
+		return "", fmt.Errorf("artifact must be under the model root %s", root)
+	}
+	return realPath, nil
+}
+
+// within reports whether path is strictly inside dir
+func within(dir, path string) bool {
+	relative, err := filepath.Rel(dir, path)
+	if err != nil || relative == "." {
+		return false
+	}
+	return relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
+
+// ArtifactSHA256 returns the hex-encoded SHA-256 of a model artifact. For a
+// directory, such as a saved model, it covers each file's relative path and
+// contents in lexical order. Only regular files and directories are hashed,
+// up to MaxArtifactBytes in all.
+func ArtifactSHA256(ctx context.Context, path string) (string, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	budget := MaxArtifactBytes
+	switch {
+	case info.Mode().IsRegular():
+		if err := copyFile(ctx, hash, path, &budget); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	case !info.IsDir():
+		return "", fmt.Errorf("artifact %s is not a regular file or directory", path)
+	}
+
+	err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return fmt.Errorf("artifact entry %s is not a regular file", file)
+		}
+		relative, err := filepath.Rel(path, file)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(hash, "%s\x00", filepath.ToSlash(relative))
+		return copyFile(ctx, hash, file, &budget)
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// copyFile copies a file into w, taking its size from the remaining budget
+func copyFile(ctx context.Context, w io.Writer, path string, budget *int64) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	copied, err := io.CopyN(w, contextReader{ctx: ctx, r: file}, *budget+1)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if copied > *budget {
+		return fmt.Errorf("artifact exceeds %d bytes", MaxArtifactBytes)
+	}
+	*budget -= copied
+	return nil
+}
+
+// contextReader stops a copy once its context is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// LoadsArtifact reports whether a model loads a versioned artifact. Only
+// script models do, from ML_MODEL_PATH; in-process, remote and ensemble
+// models are unaffected by the model registry.
+func (s *Service) LoadsArtifact(model string) bool {
+	predictor, err := s.registry.Get(model)
+	if err != nil {
+		return false
+	}
+	_, ok := predictor.(*ScriptPredictor)
+	return ok
+}
+
+// productionVersion returns the registry's production version of a model
+// that loads an artifact
+func (s *Service) productionVersion(model string) (*models.ModelVersion, bool) {
+	if s.versions == nil || !s.LoadsArtifact(model) {
+		return nil, false
+	}
+	version, ok := s.versions.Production(model)
+	if !ok || version.ArtifactPath == "" {
+		return nil, false
+	}
+	return version, true
+}
+
+// verifyArtifact checks that a version's artifact is unchanged since it was
+// registered, hashing it once per version
+func (s *Service) verifyArtifact(ctx context.Context, version *models.ModelVersion) error {
+	s.artifactMu.Lock()
+	verified := s.verified[version.ID]
+	s.artifactMu.Unlock()
+	if verified {
+		return nil
+	}
+
+	digest, err := ArtifactSHA256(ctx, version.ArtifactPath)
+	if err != nil {
+		return fmt.Errorf("model %s %s artifact unreadable: %w", version.Name, version.Version, err)
+	}
+	if digest != version.SHA256 {
+		return fmt.Errorf("model %s %s artifact %s changed since registration", version.Name, version.Version, version.ArtifactPath)
+	}
+
+	s.artifactMu.Lock()
+	if s.verified == nil {
+		s.verified = make(map[int64]bool)
+	}
+	s.verified[version.ID] = true
+	s.artifactMu.Unlock()
+	return nil
+}
@@ -0,0 +1,62 @@
+package prediction
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveArtifact(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "model.pkl"), []byte("weights"), 0o644))
+	outside := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(outside, []byte("secret"), 0o644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "escape")))
+
+	path, err := ResolveArtifact(root, "model.pkl")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "model.pkl"), path)
+	path, err = ResolveArtifact(root, filepath.Join(root, "model.pkl"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "model.pkl"), path)
+
+	for _, escape := range []string{outside, "../secret", "/etc/passwd", "/", ".", root, "escape"} {
+		_, err := ResolveArtifact(root, escape)
+		assert.Error(t, err, escape)
+	}
+}
+
+func TestArtifactSHA256(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "weights.bin"), []byte("weights"), 0o644))
+
+	digest, err := ArtifactSHA256(context.Background(), dir)
+	require.NoError(t, err)
+	assert.Len(t, digest, 64)
+
+	// Devices and links are not hashed, at the top or inside a directory
+	_, err = ArtifactSHA256(context.Background(), "/dev/zero")
+	assert.ErrorContains(t, err, "not a regular file")
+	require.NoError(t, os.Symlink("/dev/zero", filepath.Join(dir, "zero")))
+	_, err = ArtifactSHA256(context.Background(), dir)
+	assert.ErrorContains(t, err, "not a regular file")
+
+	// Hashing stops when the request does
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ArtifactSHA256(ctx, filepath.Join(dir, "weights.bin"))
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Files beyond the byte budget are refused
+	budget := int64(3)
+	err = copyFile(context.Background(), io.Discard, filepath.Join(dir, "weights.bin"), &budget)
+	assert.ErrorContains(t, err, "exceeds")
+	budget = 7
+	require.NoError(t, copyFile(context.Background(), io.Discard, filepath.Join(dir, "weights.bin"), &budget))
+	assert.Zero(t, budget)
+}
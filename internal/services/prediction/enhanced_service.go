@@ -3,9 +3,7 @@ package prediction
 import (
 	"context"
 	"fmt"
-	"os/exec"
-	"strconv"
-	"strings"
+	"sync"
 	"time"
 
 	"stock-prediction-us/internal/config"
@@ -19,6 +17,7 @@ import (
 // EnhancedPredictionService provides enhanced prediction capabilities
 type EnhancedPredictionService struct {
 	*Service // Embed the original service
+	mu               sync.RWMutex // Guards predictionConfig
 	predictionConfig *models.PredictionConfig
 }
 
@@ -33,9 +32,14 @@ func NewEnhancedPredictionService(
 	// Create base service
 	baseService := NewService(config, logger, metrics, cache)
 	
-	// Create prediction configuration from config
+	// Create prediction configuration from config; an enabled ensemble is
+	// the default model
+	model := models.PredictionModel(config.ML.Model)
+	if config.ML.EnableEnsemble && baseService.registry.Has(string(models.ModelEnsemble)) {
+		model = models.ModelEnsemble
+	}
 	predictionConfig := &models.PredictionConfig{
-		Model:         models.PredictionModel(config.ML.Model),
+		Model:         model,
 		UseOHLCVData:  config.ML.UseOHLCVData,
 		MaxDataPoints: config.ML.MaxDataPoints,
 		MinDataPoints: config.ML.MinDataPoints,
@@ -44,7 +48,7 @@ func NewEnhancedPredictionService(
 	}
 	
 	// Validate configuration
-	if err := predictionConfig.Validate(); err != nil {
+	if err := baseService.validatePredictionConfig(predictionConfig); err != nil {
 		logger.WithError(err).Warn("Invalid prediction configuration, using defaults")
 		predictionConfig = models.DefaultPredictionConfig()
 	}
@@ -65,32 +69,55 @@ func (s *EnhancedPredictionService) Predict(ctx context.Context, req *models.Pre
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 	
+	// Use the default model unless the request names another one
+	predictionConfig := s.currentConfig()
+	if req.Model != "" && req.Model != string(predictionConfig.Model) {
+		override := *predictionConfig
+		override.Model = models.PredictionModel(req.Model)
+		if err := s.validatePredictionConfig(&override); err != nil {
+			s.metrics.RecordPrediction(time.Since(start).Seconds(), false)
+			return nil, fmt.Errorf("invalid request: %w", err)
+		}
+		predictionConfig = &override
+	}
+	model := predictionConfig.Model
+	
 	s.logger.WithFields(logrus.Fields{
 		"symbol":      req.Symbol,
 		"data_points": len(req.HistoricalData),
-		"model":       s.predictionConfig.Model,
+		"model":       model,
 	}).Info("Processing enhanced prediction request")
 	
 	// Prepare historical data based on model requirements
-	processedData := s.prepareHistoricalData(req.HistoricalData)
+	processedData := s.prepareHistoricalData(predictionConfig, req.HistoricalData)
+	
+	interval := req.Interval
+	if interval == "" {
+		interval = models.Interval1d
+	}
 	
-	// Check cache first (with model-specific key)
-	cacheKey := fmt.Sprintf("%s_%s", req.Symbol, s.predictionConfig.Model)
+	// Check cache first (with model- and interval-specific key)
+	cacheKey := fmt.Sprintf("%s_%s_%s_%t%s%s", req.Symbol, model, interval, req.Adjusted, eventCacheSuffix(req.EventRisk), horizonCacheSuffix(req)) + s.versionCacheSuffix(string(model))
 	if cached, found := s.cache.Get(cacheKey, processedData); found {
 		s.logger.WithFields(logrus.Fields{
 			"symbol": req.Symbol,
-			"model":  s.predictionConfig.Model,
+			"model":  model,
 		}).Debug("Returning cached enhanced prediction")
 		s.metrics.RecordPrediction(time.Since(start).Seconds(), true)
 		return cached, nil
 	}
 	
 	// Make prediction using the configured model
-	predictedPrice, err := s.callEnhancedModel(ctx, processedData)
+	var input *models.ModelInput
+	if req.Input != nil {
+		input = req.Input.Tail(len(processedData))
+	}
+	diagnostics, err := s.callEnhancedModel(ctx, predictionConfig, req.Symbol, processedData, input)
 	if err != nil {
 		s.metrics.RecordPrediction(time.Since(start).Seconds(), false)
 		return nil, fmt.Errorf("enhanced prediction failed: %w", err)
 	}
+	predictedPrice := diagnostics.Output.PredictedPrice
 	
 	// Get current price (last data point)
 	currentPrice := processedData[len(processedData)-1]
@@ -103,8 +130,14 @@ func (s *EnhancedPredictionService) Predict(ctx context.Context, req *models.Pre
 		s.config.Stock.SellThreshold,
 	)
 	
-	// Calculate advanced confidence using historical data
-	confidence := models.CalculateAdvancedConfidence(currentPrice, predictedPrice, processedData)
+	// Calculate advanced confidence using historical data and nearby events
+	confidence := models.CalculateEventAwareConfidence(currentPrice, predictedPrice, processedData, req.EventRisk)
+	
+	// The more ensemble members disagree, the less the blend is trusted
+	ensemble := diagnostics.Output.Ensemble
+	if ensemble != nil {
+		confidence = models.ApplyDisagreement(confidence, ensemble.Disagreement)
+	}
 	
 	// Create response
 	response := &models.PredictionResponse{
@@ -114,7 +147,24 @@ func (s *EnhancedPredictionService) Predict(ctx context.Context, req *models.Pre
 		TradingSignal:  string(signal),
 		Confidence:     confidence,
 		PredictionTime: time.Now(),
-		ModelVersion:   fmt.Sprintf("v3.1.0-%s", s.predictionConfig.Model),
+		ModelVersion:   diagnostics.Version,
+		ModelVersionID: diagnostics.VersionID,
+		Model:          string(model),
+		Interval:       interval,
+		Adjusted:       req.Adjusted,
+		EventRisk:      req.EventRisk,
+		Diagnostics:    diagnostics,
+		Intervals:      s.predictionIntervals(ctx, req.Symbol, interval, 1, predictedPrice, diagnostics.Output),
+		Ensemble:       ensemble,
+	}
+	
+	// Extend the prediction into a path when horizons are requested
+	if len(req.Horizons) > 0 {
+		response.Forecasts, err = s.forecastPath(ctx, predictionConfig, req, processedData, input, diagnostics.Output, confidence)
+		if err != nil {
+			s.metrics.RecordPrediction(time.Since(start).Seconds(), false)
+			return nil, fmt.Errorf("forecast path failed: %w", err)
+		}
 	}
 	
 	// Cache the result
@@ -123,7 +173,7 @@ func (s *EnhancedPredictionService) Predict(ctx context.Context, req *models.Pre
 	// Log prediction details
 	s.logger.WithFields(logrus.Fields{
 		"symbol":          req.Symbol,
-		"model":           s.predictionConfig.Model,
+		"model":           model,
 		"current_price":   currentPrice,
 		"predicted_price": predictedPrice,
 		"signal":          signal,
@@ -136,108 +186,205 @@ func (s *EnhancedPredictionService) Predict(ctx context.Context, req *models.Pre
 }
 
 // prepareHistoricalData prepares historical data based on model requirements
-func (s *EnhancedPredictionService) prepareHistoricalData(data []float64) []float64 {
+func (s *EnhancedPredictionService) prepareHistoricalData(predictionConfig *models.PredictionConfig, data []float64) []float64 {
 	// Apply data point limits
-	maxPoints := s.predictionConfig.MaxDataPoints
+	maxPoints := predictionConfig.MaxDataPoints
 	if len(data) > maxPoints {
 		data = data[len(data)-maxPoints:]
 	}
 	
 	// Ensure minimum data points
-	if len(data) < s.predictionConfig.MinDataPoints {
+	if len(data) < predictionConfig.MinDataPoints {
 		s.logger.WithFields(logrus.Fields{
 			"available": len(data),
-			"required":  s.predictionConfig.MinDataPoints,
+			"required":  predictionConfig.MinDataPoints,
 		}).Warn("Insufficient historical data for optimal prediction")
 	}
 	
 	return data
 }
 
-// callEnhancedModel calls the appropriate prediction model
-func (s *EnhancedPredictionService) callEnhancedModel(ctx context.Context, data []float64) (float64, error) {
-	scriptPath := s.predictionConfig.GetScriptPath()
-	
-	// Prepare input data
-	priceStrings := make([]string, len(data))
-	for i, price := range data {
-		priceStrings[i] = fmt.Sprintf("%.2f", price)
-	}
-	inputData := strings.Join(priceStrings, ",")
-	
-	// Create command
-	cmd := exec.CommandContext(ctx, "python3", scriptPath, inputData)
-	
-	// Set environment variables for debugging if enabled
-	if s.predictionConfig.DebugMode {
-		cmd.Env = append(cmd.Env, "DEBUG=1")
+// callEnhancedModel calls the configured prediction model
+func (s *EnhancedPredictionService) callEnhancedModel(ctx context.Context, predictionConfig *models.PredictionConfig, symbol string, data []float64, input *models.ModelInput) (*models.PredictionDiagnostics, error) {
+	model := string(predictionConfig.Model)
+	predictor, err := s.registry.Get(model)
+	if err != nil {
+		return nil, err
 	}
 	
 	s.logger.WithFields(logrus.Fields{
-		"script":      scriptPath,
-		"model":       s.predictionConfig.Model,
+		"model":       model,
 		"data_points": len(data),
 	}).Debug("Calling enhanced prediction model")
 	
-	// Execute command
-	output, err := cmd.Output()
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			return 0, fmt.Errorf("model execution failed: %s", string(exitError.Stderr))
-		}
-		return 0, fmt.Errorf("failed to execute model: %w", err)
-	}
-	
-	// Parse output
-	outputStr := strings.TrimSpace(string(output))
-	predictedPrice, err := strconv.ParseFloat(outputStr, 64)
-	if err != nil {
-		return 0, fmt.Errorf("failed to parse prediction output '%s': %w", outputStr, err)
+	return s.predict(ctx, symbol, model, predictor, data, input, predictionConfig.UseOHLCVData)
+}
+
+// forecastPath iterates the one-step model over its own predictions, one
+// trading day at a time, up to the furthest requested horizon. Each step
+// slides the window forward so the model sees as many points as it did for
+// the first step. Intervals at every horizon derive from the first step's
+// quantiles, since later steps only see their own forecast noise, and
+// confidence at every horizon from the first step's.
+func (s *EnhancedPredictionService) forecastPath(ctx context.Context, predictionConfig *models.PredictionConfig, req *models.PredictionRequest, data []float64, input *models.ModelInput, first *models.ModelOutput, confidence float64) ([]models.ForecastPoint, error) {
+	currentPrice := data[len(data)-1]
+	wanted := make(map[int]bool, len(req.Horizons))
+	for _, days := range req.Horizons {
+		wanted[days] = true
 	}
 	
-	if predictedPrice <= 0 {
-		return 0, fmt.Errorf("invalid prediction: %f", predictedPrice)
+	closes := append([]float64(nil), data...)
+	price := first.PredictedPrice
+	forecasts := make([]models.ForecastPoint, 0, len(req.Horizons))
+	for days := 1; days <= models.MaxHorizon(req.Horizons); days++ {
+		if days > 1 {
+			closes = append(closes[1:], price)
+			if input != nil {
+				input = input.Append(req.ForecastDates[days-2], price)
+			}
+			diagnostics, err := s.callEnhancedModel(ctx, predictionConfig, req.Symbol, closes, input)
+			if err != nil {
+				return nil, fmt.Errorf("step %d: %w", days, err)
+			}
+			price = diagnostics.Output.PredictedPrice
+		}
+		if !wanted[days] {
+			continue
+		}
+		forecasts = append(forecasts, models.ForecastPoint{
+			HorizonDays:    days,
+			TargetDate:     req.ForecastDates[days-1],
+			PredictedPrice: price,
+			ChangePercent:  (price - currentPrice) / currentPrice * 100,
+			TradingSignal: string(models.GenerateTradingSignal(
+				currentPrice,
+				price,
+				s.config.Stock.BuyThreshold,
+				s.config.Stock.SellThreshold,
+			)),
+			Confidence: models.HorizonConfidence(confidence, days),
+			Intervals:  s.predictionIntervals(ctx, req.Symbol, models.Interval1d, days, price, first),
+		})
 	}
-	
-	return predictedPrice, nil
+	return forecasts, nil
 }
 
 // GetModelInfo returns information about the current prediction model
 func (s *EnhancedPredictionService) GetModelInfo() *models.ModelInfo {
-	return &models.ModelInfo{
-		Name:        string(s.predictionConfig.Model),
-		Version:     fmt.Sprintf("v3.1.0-%s", s.predictionConfig.Model),
-		Description: s.predictionConfig.Model.GetModelDescription(),
-		Features:    s.predictionConfig.Model.GetModelFeatures(),
-		Config: map[string]interface{}{
-			"use_ohlcv_data":   s.predictionConfig.UseOHLCVData,
-			"max_data_points":  s.predictionConfig.MaxDataPoints,
-			"min_data_points":  s.predictionConfig.MinDataPoints,
-			"enable_ensemble":  s.predictionConfig.EnableEnsemble,
-			"debug_mode":       s.predictionConfig.DebugMode,
-		},
+	predictionConfig := s.currentConfig()
+	info := s.describeModel(string(predictionConfig.Model))
+	info.Config = map[string]interface{}{
+		"use_ohlcv_data":   predictionConfig.UseOHLCVData,
+		"max_data_points":  predictionConfig.MaxDataPoints,
+		"min_data_points":  predictionConfig.MinDataPoints,
+		"enable_ensemble":  predictionConfig.EnableEnsemble,
+		"debug_mode":       predictionConfig.DebugMode,
 	}
+	return info
 }
 
-// SwitchModel dynamically switches the prediction model
+// ListModels describes every registered model
+func (s *EnhancedPredictionService) ListModels() []*models.ModelInfo {
+	defaultModel := s.DefaultModel()
+	names := s.registry.Names()
+	infos := make([]*models.ModelInfo, 0, len(names))
+	for _, name := range names {
+		info := s.describeModel(name)
+		minPoints, maxPoints := models.PredictionModel(name).GetRecommendedDataPoints()
+		info.Config = map[string]interface{}{
+			"default":                 name == defaultModel,
+			"requires_ohlcv_data":     models.PredictionModel(name).RequiresOHLCVData(),
+			"recommended_min_points":  minPoints,
+			"recommended_max_points":  maxPoints,
+		}
+		if spec, ok := s.registry.Spec(name); ok {
+			info.Config["backend"] = spec.Backend
+		}
+		if predictor, err := s.registry.Get(name); err == nil {
+			if ensemble, ok := predictor.(*EnsemblePredictor); ok {
+				info.Config["members"] = ensemble.Members()
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// describeModel returns the name, version, description and features of a
+// registered model
+func (s *EnhancedPredictionService) describeModel(name string) *models.ModelInfo {
+	model := models.PredictionModel(name)
+	description := model.GetModelDescription()
+	if spec, ok := s.registry.Spec(name); ok && !model.IsBuiltIn() {
+		description = fmt.Sprintf("Configured %s model", spec.Backend)
+	}
+	
+	info := &models.ModelInfo{
+		Name:        name,
+		Description: description,
+		Features:    model.GetModelFeatures(),
+	}
+	info.Version, _ = s.modelVersion(name)
+	if s.versions != nil {
+		if version, ok := s.versions.Production(name); ok {
+			info.Registry = version
+		}
+	}
+	return info
+}
+
+// DefaultModel returns the model used when a request names none
+func (s *EnhancedPredictionService) DefaultModel() string {
+	return string(s.currentConfig().Model)
+}
+
+// HealthCheck checks that the default model can serve predictions
+func (s *EnhancedPredictionService) HealthCheck() error {
+	return s.checkModel(s.DefaultModel())
+}
+
+// SwitchModel dynamically switches the default prediction model. It is
+// safe to call while predictions are in flight; they finish on the model
+// they started with.
 func (s *EnhancedPredictionService) SwitchModel(model models.PredictionModel) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
 	// Validate the new model
-	newConfig := &models.PredictionConfig{
-		Model:         model,
-		UseOHLCVData:  s.predictionConfig.UseOHLCVData,
-		MaxDataPoints: s.predictionConfig.MaxDataPoints,
-		MinDataPoints: s.predictionConfig.MinDataPoints,
-		EnableEnsemble: s.predictionConfig.EnableEnsemble,
-		DebugMode:     s.predictionConfig.DebugMode,
-	}
+	newConfig := *s.predictionConfig
+	newConfig.Model = model
 	
-	if err := newConfig.Validate(); err != nil {
+	if err := s.validatePredictionConfig(&newConfig); err != nil {
 		return fmt.Errorf("invalid model configuration: %w", err)
 	}
 	
 	// Update configuration
-	s.predictionConfig = newConfig
+	previous := s.predictionConfig.Model
+	s.predictionConfig = &newConfig
 	
-	s.logger.WithField("model", model).Info("Switched prediction model")
+	s.logger.WithFields(logrus.Fields{
+		"model":    model,
+		"previous": previous,
+	}).Info("Switched prediction model")
+	return nil
+}
+
+// currentConfig returns the prediction configuration in effect. The
+// returned value is never modified; SwitchModel replaces it.
+func (s *EnhancedPredictionService) currentConfig() *models.PredictionConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.predictionConfig
+}
+
+// validatePredictionConfig checks a configuration and that its model is
+// registered
+func (s *Service) validatePredictionConfig(pc *models.PredictionConfig) error {
+	if err := pc.Validate(); err != nil {
+		return err
+	}
+	if !s.registry.Has(string(pc.Model)) {
+		return fmt.Errorf("%w: %s, valid options: %v", ErrUnknownModel, pc.Model, s.registry.Names())
+	}
 	return nil
 }
@@ -0,0 +1,241 @@
+package prediction
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-prediction-us/internal/config"
+	"stock-prediction-us/internal/models"
+	"stock-prediction-us/internal/services/cache"
+	"stock-prediction-us/internal/services/prediction/native"
+)
+
+func newTestEnhancedService(t *testing.T, model string) *EnhancedPredictionService {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.ML.Model = model
+	cfg.ML.MaxDataPoints = 30
+	cfg.ML.MinDataPoints = 5
+	cfg.ML.WorkerPoolSize = 1
+	cfg.Stock.BuyThreshold = 0.02
+	cfg.Stock.SellThreshold = -0.02
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	service := NewEnhancedPredictionService(cfg, logger, testMetrics, cache.NewPredictionCache(time.Minute, testMetrics))
+	t.Cleanup(service.Close)
+	return service
+}
+
+func TestEnhancedServiceModelOverride(t *testing.T) {
+	service := newTestEnhancedService(t, "simple-go")
+
+	response, err := service.Predict(context.Background(), &models.PredictionRequest{Symbol: "NVDA", HistoricalData: testPrices})
+	require.NoError(t, err)
+	assert.Equal(t, "simple-go", response.Model)
+	assert.Equal(t, native.Simple(testPrices), response.PredictedPrice)
+
+	response, err = service.Predict(context.Background(), &models.PredictionRequest{Symbol: "NVDA", HistoricalData: testPrices, Model: "enhanced-go"})
+	require.NoError(t, err)
+	assert.Equal(t, "enhanced-go", response.Model)
+	assert.Equal(t, native.Enhanced(testPrices).Prediction, response.PredictedPrice)
+
+	// An override leaves the default untouched
+	assert.Equal(t, "simple-go", service.DefaultModel())
+
+	_, err = service.Predict(context.Background(), &models.PredictionRequest{Symbol: "NVDA", HistoricalData: testPrices, Model: "missing"})
+	assert.ErrorIs(t, err, ErrUnknownModel)
+}
+
+func TestEnhancedServiceSwitchModel(t *testing.T) {
+	service := newTestEnhancedService(t, "simple-go")
+
+	assert.ErrorIs(t, service.SwitchModel("missing"), ErrUnknownModel)
+	assert.Equal(t, "simple-go", service.DefaultModel())
+
+	// Switch back and forth while predictions run
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				response, err := service.Predict(context.Background(), &models.PredictionRequest{Symbol: "NVDA", HistoricalData: testPrices})
+				if assert.NoError(t, err) {
+					assert.Contains(t, []string{"simple-go", "enhanced-go"}, response.Model)
+				}
+			}
+		}()
+	}
+	for j := 0; j < 50; j++ {
+		model := models.ModelSimpleGo
+		if j%2 == 0 {
+			model = models.ModelEnhancedGo
+		}
+		require.NoError(t, service.SwitchModel(model))
+	}
+	wg.Wait()
+
+	require.NoError(t, service.SwitchModel(models.ModelEnhancedGo))
+	assert.Equal(t, "enhanced-go", service.GetModelInfo().Name)
+
+	var defaults []string
+	for _, info := range service.ListModels() {
+		if info.Config["default"] == true {
+			defaults = append(defaults, info.Name)
+		}
+	}
+	assert.Equal(t, []string{"enhanced-go"}, defaults)
+}
+
+func TestEnhancedServiceForecastPath(t *testing.T) {
+	service := newTestEnhancedService(t, "simple-go")
+
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	var dates []time.Time
+	for i := 1; i <= 5; i++ {
+		dates = append(dates, day.AddDate(0, 0, i))
+	}
+
+	response, err := service.Predict(context.Background(), &models.PredictionRequest{
+		Symbol:         "NVDA",
+		HistoricalData: testPrices,
+		Horizons:       []int{1, 3, 5},
+		ForecastDates:  dates,
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Forecasts, 3)
+
+	// Each step feeds the previous prediction back in a sliding window
+	window := append([]float64(nil), testPrices...)
+	var path []float64
+	for step := 0; step < 5; step++ {
+		price := native.Simple(window)
+		path = append(path, price)
+		window = append(window[1:], price)
+	}
+
+	for i, days := range []int{1, 3, 5} {
+		point := response.Forecasts[i]
+		assert.Equal(t, days, point.HorizonDays)
+		assert.Equal(t, dates[days-1], point.TargetDate)
+		assert.InDelta(t, path[days-1], point.PredictedPrice, 1e-9)
+		assert.InDelta(t, (path[days-1]/105.5-1)*100, point.ChangePercent, 1e-9)
+		assert.InDelta(t, response.Confidence/math.Sqrt(float64(days)), point.Confidence, 1e-12)
+	}
+	assert.Equal(t, response.PredictedPrice, response.Forecasts[0].PredictedPrice)
+
+	// Horizons beyond the supplied dates are rejected
+	_, err = service.Predict(context.Background(), &models.PredictionRequest{
+		Symbol:         "NVDA",
+		HistoricalData: testPrices,
+		Horizons:       []int{20},
+		ForecastDates:  dates,
+	})
+	assert.Error(t, err)
+}
+
+// fixedVersions serves registry versions from a map
+type fixedVersions map[string]*models.ModelVersion
+
+func (f fixedVersions) Production(name string) (*models.ModelVersion, bool) {
+	version, ok := f[name]
+	return version, ok
+}
+
+func TestEnhancedServiceRegistryVersion(t *testing.T) {
+	service := newTestEnhancedService(t, "simple-go")
+
+	response, err := service.Predict(context.Background(), &models.PredictionRequest{Symbol: "NVDA", HistoricalData: testPrices})
+	require.NoError(t, err)
+	assert.Equal(t, "v3.3.0-simple-go", response.ModelVersion)
+	assert.Zero(t, response.ModelVersionID)
+
+	// In-process models load no artifact, so a registry version does not
+	// describe them
+	service.SetVersionSource(fixedVersions{
+		"simple-go": {ID: 7, Name: "simple-go", Version: "1.4.0", ArtifactPath: "simple-go.bin", Status: models.ModelStatusProduction},
+	})
+	assert.False(t, service.LoadsArtifact("simple-go"))
+	response, err = service.Predict(context.Background(), &models.PredictionRequest{Symbol: "NVDA", HistoricalData: testPrices})
+	require.NoError(t, err)
+	assert.Equal(t, "v3.3.0-simple-go", response.ModelVersion)
+	assert.Zero(t, response.ModelVersionID)
+}
+
+func TestRegistryVersionSelectsArtifact(t *testing.T) {
+	dir := t.TempDir()
+	artifact := func(name, price string) *models.ModelVersion {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(price+"\n"), 0o644))
+		digest, err := ArtifactSHA256(context.Background(), path)
+		require.NoError(t, err)
+		return &models.ModelVersion{Name: "lstm", Version: name, ArtifactPath: path, SHA256: digest, Status: models.ModelStatusProduction}
+	}
+	v1, v2 := artifact("1.0.0", "111.5"), artifact("2.0.0", "222.5")
+	v1.ID, v2.ID = 1, 2
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	pool := newTestPool(t, WorkerSettings{Size: 1})
+	registry := NewRegistry()
+	registry.Register(ModelSpec{Name: "lstm", Backend: BackendScript, Target: "artifact.py"}, NewScriptPredictor("lstm", "artifact.py", pool))
+	versions := fixedVersions{"lstm": v1}
+	service := &EnhancedPredictionService{
+		Service: &Service{config: &config.Config{}, logger: logger, metrics: testMetrics, cache: cache.NewPredictionCache(time.Minute, testMetrics),
+			workers: pool, registry: registry, versions: versions},
+		predictionConfig: models.DefaultPredictionConfig(),
+	}
+
+	predict := func() *models.PredictionResponse {
+		t.Helper()
+		response, err := service.Predict(context.Background(), &models.PredictionRequest{Symbol: "NVDA", HistoricalData: testPrices, Model: "lstm"})
+		require.NoError(t, err)
+		return response
+	}
+
+	response := predict()
+	assert.Equal(t, 111.5, response.PredictedPrice)
+	assert.Equal(t, "1.0.0", response.ModelVersion)
+	assert.Equal(t, int64(1), response.ModelVersionID)
+	assert.Equal(t, v1.ArtifactPath, response.Diagnostics.Artifact)
+
+	// Promoting a version serves predictions from its artifact at once
+	versions["lstm"] = v2
+	response = predict()
+	assert.Equal(t, 222.5, response.PredictedPrice)
+	assert.Equal(t, "2.0.0", response.ModelVersion)
+	assert.Equal(t, int64(2), response.ModelVersionID)
+	assert.Equal(t, v2.ArtifactPath, response.Diagnostics.Artifact)
+
+	// Rolling back returns to the previous artifact
+	versions["lstm"] = v1
+	response = predict()
+	assert.Equal(t, 111.5, response.PredictedPrice)
+	assert.Equal(t, "1.0.0", response.ModelVersion)
+
+	// An artifact changed since registration is refused, not served under
+	// the registered version
+	tampered := artifact("3.0.0", "333.5")
+	tampered.ID = 3
+	require.NoError(t, os.WriteFile(tampered.ArtifactPath, []byte("999.5\n"), 0o644))
+	versions["lstm"] = tampered
+	_, err := service.Predict(context.Background(), &models.PredictionRequest{Symbol: "NVDA", HistoricalData: testPrices, Model: "lstm"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "changed since registration")
+
+	// Without a registry version the script loads the configured model
+	delete(versions, "lstm")
+	version, id := service.modelVersion("lstm")
+	assert.Equal(t, "v3.3.0-lstm", version)
+	assert.Zero(t, id)
+}
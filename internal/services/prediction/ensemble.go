@@ -0,0 +1,220 @@
+package prediction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"stock-prediction-us/internal/models"
+)
+
+// MemberAccuracySource supplies the rolling error of each model on a symbol,
+// for weighting ensemble members
+type MemberAccuracySource interface {
+	// MemberAccuracy returns, per model, the MAPE of up to window of its most
+	// recent scored next-session predictions for symbol
+	MemberAccuracy(ctx context.Context, symbol string, window int) (map[string]models.ModelAccuracy, error)
+}
+
+// SetMemberAccuracySource sets where ensemble weights come from. It is
+// called once at startup, before predictions are served.
+func (s *Service) SetMemberAccuracySource(accuracy MemberAccuracySource) {
+	s.accuracy = accuracy
+}
+
+// EnsemblePredictor runs several registered models concurrently and blends
+// their predictions, weighting each by the inverse of its recent error on
+// the symbol
+type EnsemblePredictor struct {
+	service *Service
+	members []string
+}
+
+// newEnsemblePredictor returns an ensemble of the named models. Names that
+// are not registered, or are themselves ensembles, are logged and skipped.
+func newEnsemblePredictor(service *Service, names []string) (*EnsemblePredictor, error) {
+	seen := make(map[string]bool)
+	var members []string
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if spec, ok := service.registry.Spec(name); !ok || spec.Backend == BackendEnsemble {
+			service.logger.WithField("model", name).Warn("Skipping unknown ensemble member")
+			continue
+		}
+		members = append(members, name)
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no registered ensemble members in %v", names)
+	}
+	return &EnsemblePredictor{service: service, members: members}, nil
+}
+
+// Name returns the model name
+func (p *EnsemblePredictor) Name() string {
+	return string(models.ModelEnsemble)
+}
+
+// Members returns the names of the blended models
+func (p *EnsemblePredictor) Members() []string {
+	return p.members
+}
+
+// Predict blends the members with equal weights; without a symbol there is
+// no track record to weight them by
+func (p *EnsemblePredictor) Predict(ctx context.Context, prices []float64) (*models.ModelOutput, error) {
+	return p.PredictSymbol(ctx, "", prices, nil)
+}
+
+// memberResult is one member's prediction or failure
+type memberResult struct {
+	output    *models.ModelOutput
+	version   string // of the member model that ran
+	versionID int64
+	err       error
+}
+
+// PredictSymbol runs every member and blends their predictions. Members
+// that fail are reported and left out of the blend; the prediction fails
+// only when all of them do.
+func (p *EnsemblePredictor) PredictSymbol(ctx context.Context, symbol string, prices []float64, input *models.ModelInput) (*models.ModelOutput, error) {
+	if err := validatePrices(prices); err != nil {
+		return nil, err
+	}
+
+	results := make([]memberResult, len(p.members))
+	var wg sync.WaitGroup
+	for i, name := range p.members {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			predictor, err := p.service.registry.Get(name)
+			if err != nil {
+				results[i].err = err
+				return
+			}
+			diagnostics, err := p.service.predict(ctx, symbol, name, predictor, prices, input, input != nil)
+			if err != nil {
+				results[i].err = err
+				return
+			}
+			results[i].output = diagnostics.Output
+			results[i].version, results[i].versionID = diagnostics.Version, diagnostics.VersionID
+		}(i, name)
+	}
+
+	accuracy := p.memberAccuracy(ctx, symbol)
+	weights, weighting := models.EnsembleWeights(p.members, accuracy, p.service.config.ML.EnsembleMinSamples)
+	wg.Wait()
+
+	result := &models.EnsembleResult{
+		Members:   make([]models.EnsembleMember, len(p.members)),
+		Weighting: weighting,
+	}
+	output := &models.ModelOutput{
+		Version:      models.ModelOutputVersion,
+		ModelVersion: "ensemble-1.0.0",
+		Ensemble:     result,
+	}
+
+	var errs []error
+	for i, name := range p.members {
+		member := models.EnsembleMember{Model: name, Weight: weights[i]}
+		if a, ok := accuracy[name]; ok {
+			mape := a.MAPE
+			member.RollingMAPE = &mape
+			member.Samples = a.Samples
+		}
+		if err := results[i].err; err != nil {
+			member.Error = err.Error()
+			member.ModelVersion, member.ModelVersionID = p.service.modelVersion(name)
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			output.Warnings = append(output.Warnings, fmt.Sprintf("member %s failed: %v", name, err))
+		} else {
+			member.PredictedPrice = results[i].output.PredictedPrice
+			member.ModelVersion, member.ModelVersionID = results[i].version, results[i].versionID
+		}
+		result.Members[i] = member
+	}
+	if len(errs) == len(p.members) {
+		return nil, fmt.Errorf("every ensemble member failed: %w", errors.Join(errs...))
+	}
+
+	output.PredictedPrice, result.Disagreement = models.BlendEnsemble(result.Members)
+	output.Quantiles = blendQuantiles(result.Members, results)
+	output.Features = map[string]float64{
+		"disagreement_pct": result.Disagreement,
+		"members":          float64(len(p.members) - len(errs)),
+	}
+	for _, member := range result.Members {
+		output.Features["weight_"+member.Model] = member.Weight
+	}
+
+	if len(errs) > 0 {
+		p.service.logger.WithFields(logrus.Fields{
+			"symbol": symbol,
+			"error":  errors.Join(errs...),
+		}).Warn("Ensemble members failed")
+	}
+	return checkPrediction(output)
+}
+
+// memberAccuracy looks up the members' rolling errors on symbol. A failed
+// lookup leaves the members equally weighted.
+func (p *EnsemblePredictor) memberAccuracy(ctx context.Context, symbol string) map[string]models.ModelAccuracy {
+	if symbol == "" || p.service.accuracy == nil {
+		return nil
+	}
+	accuracy, err := p.service.accuracy.MemberAccuracy(ctx, symbol, p.service.config.ML.EnsembleWindow)
+	if err != nil {
+		p.service.logger.WithFields(logrus.Fields{
+			"symbol": symbol,
+			"error":  err,
+		}).Warn("Failed to load ensemble member accuracy")
+		return nil
+	}
+	return accuracy
+}
+
+// blendQuantiles averages, with the members' weights, the quantiles at the
+// levels every successful member reported
+func blendQuantiles(members []models.EnsembleMember, results []memberResult) []models.Quantile {
+	var blended []models.Quantile
+	for _, q := range quantileZ {
+		var price float64
+		complete := true
+		for i, member := range members {
+			if member.Error != "" {
+				continue
+			}
+			quantile, ok := results[i].output.QuantileAt(q.level)
+			if !ok {
+				complete = false
+				break
+			}
+			price += member.Weight * quantile
+		}
+		if complete {
+			blended = append(blended, models.Quantile{Level: q.level, Price: price})
+		}
+	}
+	return blended
+}
+
+// HealthCheck passes when at least one member can serve predictions
+func (p *EnsemblePredictor) HealthCheck(ctx context.Context) error {
+	var errs []error
+	for _, name := range p.members {
+		err := p.service.checkModel(name)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+	}
+	return fmt.Errorf("no ensemble member is healthy: %w", errors.Join(errs...))
+}
@@ -0,0 +1,86 @@
+package prediction
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-prediction-us/internal/config"
+	"stock-prediction-us/internal/models"
+	"stock-prediction-us/internal/services/cache"
+	"stock-prediction-us/internal/services/prediction/native"
+)
+
+// fixedAccuracy serves the same member accuracy for every symbol
+type fixedAccuracy map[string]models.ModelAccuracy
+
+func (f fixedAccuracy) MemberAccuracy(ctx context.Context, symbol string, window int) (map[string]models.ModelAccuracy, error) {
+	return f, nil
+}
+
+func TestEnsembleBlendsMembersByAccuracy(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.ML.Model = "simple-go"
+	cfg.ML.MaxDataPoints = 30
+	cfg.ML.MinDataPoints = 5
+	cfg.ML.WorkerPoolSize = 1
+	cfg.ML.HTTPTimeout = time.Second
+	cfg.ML.EnableEnsemble = true
+	cfg.ML.Models = []string{"offline=http:http://127.0.0.1:1"}
+	cfg.ML.EnsembleModels = []string{"simple-go", "enhanced-go", "offline", "missing"}
+	cfg.ML.EnsembleMinSamples = 10
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	service := NewEnhancedPredictionService(cfg, logger, testMetrics, cache.NewPredictionCache(time.Minute, testMetrics))
+	t.Cleanup(service.Close)
+	service.SetMemberAccuracySource(fixedAccuracy{
+		"simple-go":   {Model: "simple-go", MAPE: 1, Samples: 20},
+		"enhanced-go": {Model: "enhanced-go", MAPE: 3, Samples: 20},
+	})
+
+	// The ensemble is the default; unknown members are dropped
+	assert.Equal(t, "ensemble", service.DefaultModel())
+	response, err := service.Predict(context.Background(), &models.PredictionRequest{
+		Symbol:         "NVDA",
+		HistoricalData: testPrices,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ensemble", response.Model)
+	require.NotNil(t, response.Ensemble)
+	require.Len(t, response.Ensemble.Members, 3)
+	assert.Equal(t, models.EnsembleWeightingInverseMAPE, response.Ensemble.Weighting)
+
+	// The offline member's share goes to the others, 3:1 by inverse MAPE
+	simple, enhanced, offline := response.Ensemble.Members[0], response.Ensemble.Members[1], response.Ensemble.Members[2]
+	assert.NotEmpty(t, offline.Error)
+	assert.Equal(t, 0.0, offline.Weight)
+	assert.InDelta(t, 0.75, simple.Weight, 1e-12)
+	assert.InDelta(t, 0.25, enhanced.Weight, 1e-12)
+	assert.InDelta(t, native.Simple(testPrices), simple.PredictedPrice, 1e-9)
+	assert.InDelta(t, native.Enhanced(testPrices).Prediction, enhanced.PredictedPrice, 1e-9)
+	assert.InDelta(t, 0.75*simple.PredictedPrice+0.25*enhanced.PredictedPrice, response.PredictedPrice, 1e-9)
+	assert.Equal(t, 1.0, *simple.RollingMAPE)
+
+	// Disagreement lowers confidence below that of the blend alone
+	_, disagreement := models.BlendEnsemble([]models.EnsembleMember{
+		{PredictedPrice: simple.PredictedPrice, Weight: 0.75},
+		{PredictedPrice: enhanced.PredictedPrice, Weight: 0.25},
+	})
+	assert.InDelta(t, disagreement, response.Ensemble.Disagreement, 1e-9)
+	base := models.CalculateEventAwareConfidence(105.5, response.PredictedPrice, testPrices, nil)
+	assert.InDelta(t, models.ApplyDisagreement(base, disagreement), response.Confidence, 1e-12)
+
+	// A single model can still be requested
+	response, err = service.Predict(context.Background(), &models.PredictionRequest{
+		Symbol:         "NVDA",
+		HistoricalData: testPrices,
+		Model:          "simple-go",
+	})
+	require.NoError(t, err)
+	assert.Nil(t, response.Ensemble)
+}
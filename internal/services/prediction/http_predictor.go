@@ -0,0 +1,175 @@
+package prediction
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"stock-prediction-us/internal/models"
+)
+
+// ModelServerRequest is the body of POST /predict on a model server.
+// Input is set for models that take OHLCV bars; Prices always holds the
+// closes.
+type ModelServerRequest struct {
+	Model  string             `json:"model"`
+	Prices []float64          `json:"prices"`
+	Input  *models.ModelInput `json:"input,omitempty"`
+}
+
+// ModelServerResponse is a model server's answer. Servers that predate the
+// JSON model output send only Prediction.
+type ModelServerResponse struct {
+	Model      string              `json:"model"`
+	Prediction float64             `json:"prediction"`
+	Output     *models.ModelOutput `json:"output,omitempty"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// HTTPPredictor calls a remote model server: POST {baseURL}/predict with a
+// ModelServerRequest, and GET {baseURL}/health for health checks
+type HTTPPredictor struct {
+	name    string
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPPredictor creates a predictor for a model served over HTTP
+func NewHTTPPredictor(name, baseURL string, timeout time.Duration) *HTTPPredictor {
+	return &HTTPPredictor{
+		name:    name,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns the model name
+func (p *HTTPPredictor) Name() string {
+	return p.name
+}
+
+// Predict posts the closes to the model server
+func (p *HTTPPredictor) Predict(ctx context.Context, prices []float64) (*models.ModelOutput, error) {
+	if err := validatePrices(prices); err != nil {
+		return nil, err
+	}
+	return p.post(ctx, ModelServerRequest{Model: p.name, Prices: prices})
+}
+
+// PredictInput posts the structured input along with its closes
+func (p *HTTPPredictor) PredictInput(ctx context.Context, input *models.ModelInput) (*models.ModelOutput, error) {
+	prices := input.Closes()
+	if err := validatePrices(prices); err != nil {
+		return nil, err
+	}
+	return p.post(ctx, ModelServerRequest{Model: p.name, Prices: prices, Input: input})
+}
+
+// post sends one prediction request to the model server
+func (p *HTTPPredictor) post(ctx context.Context, request ModelServerRequest) (*models.ModelOutput, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode model request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/predict", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create model request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("model server request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result ModelServerResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode model server response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || result.Error != "" {
+		return nil, fmt.Errorf("model server returned status %d: %s", resp.StatusCode, result.Error)
+	}
+	output := result.Output
+	if output == nil {
+		output = &models.ModelOutput{PredictedPrice: result.Prediction}
+	} else if output.Version > models.ModelOutputVersion {
+		return nil, fmt.Errorf("unsupported model output version %d (supported up to %d)", output.Version, models.ModelOutputVersion)
+	}
+	return checkPrediction(output)
+}
+
+// HealthCheck checks that the model server answers
+func (p *HTTPPredictor) HealthCheck(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/health", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create health request: %w", err)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("model server unreachable: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("model server health returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// NewModelServer serves a registry's models over the HTTPPredictor
+// protocol. It is the local stand-in for a remote model server in tests
+// and development.
+func NewModelServer(registry *Registry) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		writeModelServerJSON(w, http.StatusOK, map[string]interface{}{
+			"status": "healthy",
+			"models": registry.Names(),
+		})
+	})
+
+	mux.HandleFunc("/predict", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeModelServerJSON(w, http.StatusMethodNotAllowed, ModelServerResponse{Error: "method not allowed"})
+			return
+		}
+
+		var req ModelServerRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+			writeModelServerJSON(w, http.StatusBadRequest, ModelServerResponse{Error: "invalid request: " + err.Error()})
+			return
+		}
+
+		predictor, err := registry.Get(req.Model)
+		if err != nil {
+			writeModelServerJSON(w, http.StatusNotFound, ModelServerResponse{Model: req.Model, Error: err.Error()})
+			return
+		}
+
+		var output *models.ModelOutput
+		if inputPredictor, ok := predictor.(InputPredictor); ok && req.Input != nil {
+			output, err = inputPredictor.PredictInput(r.Context(), req.Input)
+		} else {
+			output, err = predictor.Predict(r.Context(), req.Prices)
+		}
+		if err != nil {
+			writeModelServerJSON(w, http.StatusUnprocessableEntity, ModelServerResponse{Model: req.Model, Error: err.Error()})
+			return
+		}
+		writeModelServerJSON(w, http.StatusOK, ModelServerResponse{Model: req.Model, Prediction: output.PredictedPrice, Output: output})
+	})
+
+	return mux
+}
+
+func writeModelServerJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,89 @@
+package prediction
+
+import (
+	"context"
+	"math"
+
+	"github.com/sirupsen/logrus"
+
+	"stock-prediction-us/internal/models"
+)
+
+// ResidualSource supplies the errors of past scored predictions, for
+// intervals around models that report no quantiles
+type ResidualSource interface {
+	// Residuals returns up to limit of the most recent relative errors,
+	// (actual - predicted) / predicted, of daily predictions made horizon
+	// trading days ahead
+	Residuals(ctx context.Context, symbol string, horizon int, limit int) ([]float64, error)
+}
+
+// SetResidualSource sets where empirical intervals come from. It is called
+// once at startup, before predictions are served.
+func (s *Service) SetResidualSource(residuals ResidualSource) {
+	s.residuals = residuals
+}
+
+// predictionIntervals returns a band around price at each configured
+// coverage. The model's quantiles are used when it reports the levels
+// needed, widened by the square root of the horizon beyond the next bar;
+// otherwise the symbol's past residuals at that horizon are, when enough
+// daily predictions have been scored.
+func (s *Service) predictionIntervals(ctx context.Context, symbol string, interval models.Interval, horizon int, price float64, output *models.ModelOutput) []models.PredictionInterval {
+	var residuals []float64
+	residualsLoaded := false
+
+	var intervals []models.PredictionInterval
+	for _, coverage := range s.config.ML.IntervalCoverages {
+		if models.ValidateCoverage(coverage) != nil {
+			continue
+		}
+
+		if band, ok := modelInterval(output, coverage, horizon, price); ok {
+			intervals = append(intervals, band)
+			continue
+		}
+
+		if interval.IsIntraday() || s.residuals == nil {
+			continue
+		}
+		if !residualsLoaded {
+			residualsLoaded = true
+			var err error
+			residuals, err = s.residuals.Residuals(ctx, symbol, horizon, s.config.ML.IntervalResidualWindow)
+			if err != nil {
+				s.logger.WithFields(logrus.Fields{
+					"symbol":  symbol,
+					"horizon": horizon,
+					"error":   err,
+				}).Warn("Failed to load prediction residuals")
+			}
+		}
+		if len(residuals) > 0 && len(residuals) >= s.config.ML.IntervalMinResiduals {
+			intervals = append(intervals, models.ResidualInterval(price, residuals, coverage))
+		}
+	}
+	return intervals
+}
+
+// modelInterval builds a band from the quantiles a model reported for its
+// next-bar prediction, scaled to price at the given horizon
+func modelInterval(output *models.ModelOutput, coverage float64, horizon int, price float64) (models.PredictionInterval, bool) {
+	if output == nil || output.PredictedPrice <= 0 {
+		return models.PredictionInterval{}, false
+	}
+	lowerLevel, upperLevel := models.CoverageLevels(coverage)
+	lower, okLower := output.QuantileAt(lowerLevel)
+	upper, okUpper := output.QuantileAt(upperLevel)
+	if !okLower || !okUpper {
+		return models.PredictionInterval{}, false
+	}
+
+	scale := math.Sqrt(float64(horizon))
+	return models.PredictionInterval{
+		Coverage: coverage,
+		Lower:    price * (1 + (lower/output.PredictedPrice-1)*scale),
+		Upper:    price * (1 + (upper/output.PredictedPrice-1)*scale),
+		Source:   models.IntervalSourceModel,
+	}, true
+}
@@ -0,0 +1,72 @@
+package prediction
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-prediction-us/internal/models"
+)
+
+// fixedResiduals serves the same residuals for every symbol and horizon
+type fixedResiduals struct {
+	residuals []float64
+	horizons  []int
+}
+
+func (f *fixedResiduals) Residuals(ctx context.Context, symbol string, horizon int, limit int) ([]float64, error) {
+	f.horizons = append(f.horizons, horizon)
+	if len(f.residuals) > limit {
+		return f.residuals[:limit], nil
+	}
+	return f.residuals, nil
+}
+
+func TestPredictionIntervals(t *testing.T) {
+	service := newTestEnhancedService(t, "simple-go")
+	service.config.ML.IntervalCoverages = []float64{0.8, 0.9, 0.95}
+	service.config.ML.IntervalResidualWindow = 100
+	service.config.ML.IntervalMinResiduals = 10
+
+	var residuals []float64
+	for i := -5; i <= 5; i++ {
+		residuals = append(residuals, float64(i)/100)
+	}
+	source := &fixedResiduals{residuals: residuals}
+	service.SetResidualSource(source)
+
+	response, err := service.Predict(context.Background(), &models.PredictionRequest{
+		Symbol:         "NVDA",
+		HistoricalData: testPrices,
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Intervals, 3)
+
+	// The native model reports the 80% and 95% quantiles; 90% falls back to residuals
+	sources := map[float64]string{}
+	for _, interval := range response.Intervals {
+		sources[interval.Coverage] = interval.Source
+		assert.Less(t, interval.Lower, response.PredictedPrice)
+		assert.Greater(t, interval.Upper, response.PredictedPrice)
+	}
+	assert.Equal(t, map[float64]string{
+		0.8:  models.IntervalSourceModel,
+		0.9:  models.IntervalSourceResiduals,
+		0.95: models.IntervalSourceModel,
+	}, sources)
+	assert.Less(t, response.Intervals[2].Lower, response.Intervals[0].Lower)
+	assert.Equal(t, []int{1}, source.horizons)
+
+	// Too few scored predictions gives no residual band
+	source.residuals = residuals[:5]
+	service.config.ML.IntervalCoverages = []float64{0.9}
+	service.cache.Clear()
+	response, err = service.Predict(context.Background(), &models.PredictionRequest{
+		Symbol:         "NVDA",
+		HistoricalData: testPrices,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, response.Intervals)
+}
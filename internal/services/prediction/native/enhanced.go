@@ -0,0 +1,191 @@
+package native
+
+import "math"
+
+// EnhancedResult is the enhanced ensemble forecast with its components
+type EnhancedResult struct {
+	Prediction    float64            `json:"prediction"`
+	Method        string             `json:"method"` // 'ensemble' or 'fallback'
+	Individual    map[string]float64 `json:"individual_predictions,omitempty"`
+	Weights       map[string]float64 `json:"weights,omitempty"`
+	Volatility    float64            `json:"volatility"`
+	TrendStrength float64            `json:"trend_strength"`
+}
+
+// Enhanced forecasts the next price with the ensemble of
+// scripts/ml/enhanced_predict.py: linear trend, moving averages, momentum
+// with RSI and Bollinger mean reversion, weighted by data length and
+// volatility and bounded by recent volatility.
+func Enhanced(prices []float64) EnhancedResult {
+	if len(prices) < 2 {
+		prediction := 100.0
+		if len(prices) == 1 {
+			prediction = prices[0] * 1.001
+		}
+		return EnhancedResult{Prediction: prediction, Method: "fallback", Volatility: 0.02, TrendStrength: 0.5}
+	}
+
+	individual := map[string]float64{
+		"linear":         linearPrediction(prices),
+		"moving_average": movingAveragePrediction(prices),
+		"momentum":       momentumPrediction(prices),
+		"bollinger":      bollingerPrediction(prices),
+	}
+	weights := methodWeights(prices)
+
+	ensemble := individual["linear"]*weights["linear"] +
+		individual["moving_average"]*weights["moving_average"] +
+		individual["momentum"]*weights["momentum"] +
+		individual["bollinger"]*weights["bollinger"]
+
+	// Volatility-based bounds
+	volatility := returnsVolatility(prices)
+	maxChange := math.Min(0.15, volatility*3)
+	current := prices[len(prices)-1]
+	prediction := math.Max(current*(1-maxChange), math.Min(current*(1+maxChange), ensemble))
+
+	return EnhancedResult{
+		Prediction:    prediction,
+		Method:        "ensemble",
+		Individual:    individual,
+		Weights:       weights,
+		Volatility:    volatility,
+		TrendStrength: trendStrength(prices),
+	}
+}
+
+// linearPrediction scales the regression forecast by the fit's R-squared
+func linearPrediction(prices []float64) float64 {
+	slope, intercept := linearFit(prices)
+	prediction := slope*float64(len(prices)) + intercept
+
+	last := prices[len(prices)-1]
+	adjusted := last + (prediction-last)*rSquared(prices, slope, intercept)
+	return math.Max(0.01, adjusted)
+}
+
+// movingAveragePrediction follows aligned 5/10/20 averages, or blends them
+func movingAveragePrediction(prices []float64) float64 {
+	current := prices[len(prices)-1]
+	if len(prices) < 3 {
+		return current * 1.001
+	}
+
+	last := func(period int) float64 {
+		values := SMA(prices, min(period, len(prices)))
+		return values[len(values)-1]
+	}
+	sma5, sma10, sma20 := last(5), last(10), last(20)
+
+	var prediction float64
+	if (sma5 > sma10 && sma10 > sma20) || (sma5 < sma10 && sma10 < sma20) {
+		// Strong trend either way
+		prediction = current + (sma5-current)*0.5
+	} else {
+		prediction = sma5*0.5 + sma10*0.3 + sma20*0.2
+	}
+	return math.Max(0.01, prediction)
+}
+
+// momentumPrediction extends 3 and 5 bar momentum, leaning against RSI
+// extremes
+func momentumPrediction(prices []float64) float64 {
+	n := len(prices)
+	current := prices[n-1]
+	if n < 5 {
+		return current * 1.001
+	}
+
+	momentum3 := (current - prices[n-4]) / prices[n-4]
+	momentum5 := 0.0
+	if n >= 6 {
+		momentum5 = (current - prices[n-6]) / prices[n-6]
+	}
+
+	rsi := RSI(prices, 14)
+	rsiFactor := 0.0
+	switch current := rsi[len(rsi)-1]; {
+	case current > 70:
+		rsiFactor = -0.02
+	case current < 30:
+		rsiFactor = 0.02
+	}
+
+	change := (momentum3+momentum5)/2*0.7 + rsiFactor
+	return math.Max(0.01, current*(1+change))
+}
+
+// bollingerPrediction reverts toward the middle band near the edges and
+// follows the recent trend in between
+func bollingerPrediction(prices []float64) float64 {
+	n := len(prices)
+	current := prices[n-1]
+	if n < 10 {
+		return current * 1.001
+	}
+
+	middle, upper, lower := BollingerBands(prices, 20, 2)
+	width := upper[n-1] - lower[n-1]
+	if width == 0 {
+		return current * 1.001
+	}
+
+	var prediction float64
+	position := (current - lower[n-1]) / width
+	if position > 0.8 || position < 0.2 {
+		prediction = current + (middle[n-1]-current)*0.3
+	} else {
+		trend := (current - prices[n-3]) / prices[n-3]
+		prediction = current * (1 + trend*0.5)
+	}
+	return math.Max(0.01, prediction)
+}
+
+// methodWeights weights the methods by data length and volatility
+func methodWeights(prices []float64) map[string]float64 {
+	n := len(prices)
+	weights := map[string]float64{"linear": 0.25, "moving_average": 0.30, "momentum": 0.25, "bollinger": 0.20}
+	switch {
+	case n < 5:
+		weights = map[string]float64{"linear": 0.6, "moving_average": 0.4, "momentum": 0.0, "bollinger": 0.0}
+	case n < 10:
+		weights = map[string]float64{"linear": 0.4, "moving_average": 0.4, "momentum": 0.2, "bollinger": 0.0}
+	case n < 20:
+		weights = map[string]float64{"linear": 0.3, "moving_average": 0.35, "momentum": 0.25, "bollinger": 0.1}
+	}
+
+	if returnsVolatility(prices) > 0.05 {
+		weights["momentum"] *= 0.7
+		weights["bollinger"] *= 1.3
+	}
+
+	// Normalise in Python's dict order so the sum rounds identically
+	total := weights["linear"] + weights["moving_average"] + weights["momentum"] + weights["bollinger"]
+	if total > 0 {
+		for name := range weights {
+			weights[name] /= total
+		}
+	}
+	return weights
+}
+
+// returnsVolatility is the sample standard deviation of simple returns
+func returnsVolatility(prices []float64) float64 {
+	if len(prices) < 3 {
+		return 0.02
+	}
+	returns := make([]float64, len(prices)-1)
+	for i := 1; i < len(prices); i++ {
+		returns[i-1] = (prices[i] - prices[i-1]) / prices[i-1]
+	}
+	return sampleStdDev(returns)
+}
+
+// trendStrength is the R-squared of the linear trend
+func trendStrength(prices []float64) float64 {
+	if len(prices) < 3 {
+		return 0.5
+	}
+	slope, intercept := linearFit(prices)
+	return rSquared(prices, slope, intercept)
+}
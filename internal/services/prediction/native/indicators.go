@@ -0,0 +1,166 @@
+// Package native implements the lightweight prediction models in Go so they
+// run in-process without a Python runtime. Each function mirrors its
+// counterpart in scripts/ml step for step, including edge cases, and the
+// parity tests hold the two implementations to the same numbers.
+package native
+
+import "math"
+
+// SMA returns the simple moving average at every point; the first points
+// average what is available. A series shorter than the period yields its
+// last price throughout, as in enhanced_predict.py.
+func SMA(prices []float64, period int) []float64 {
+	values := make([]float64, len(prices))
+	if len(prices) < period {
+		for i := range values {
+			values[i] = prices[len(prices)-1]
+		}
+		return values
+	}
+
+	for i := range prices {
+		start := 0
+		if i >= period-1 {
+			start = i - period + 1
+		}
+		values[i] = mean(prices[start : i+1])
+	}
+	return values
+}
+
+// EMA returns the exponential moving average seeded with the first price
+func EMA(prices []float64, period int) []float64 {
+	if len(prices) == 0 {
+		return nil
+	}
+
+	multiplier := 2 / float64(period+1)
+	values := make([]float64, len(prices))
+	values[0] = prices[0]
+	for i := 1; i < len(prices); i++ {
+		values[i] = prices[i]*multiplier + values[i-1]*(1-multiplier)
+	}
+	return values
+}
+
+// RSI returns Wilder's relative strength index, 50 until enough data
+func RSI(prices []float64, period int) []float64 {
+	values := make([]float64, 0, len(prices))
+	if len(prices) < period+1 {
+		for range prices {
+			values = append(values, 50.0)
+		}
+		return values
+	}
+
+	changes := make([]float64, len(prices)-1)
+	for i := 1; i < len(prices); i++ {
+		changes[i-1] = prices[i] - prices[i-1]
+	}
+
+	var avgGain, avgLoss float64
+	for _, change := range changes[:period] {
+		avgGain += math.Max(0, change)
+		avgLoss += math.Max(0, -change)
+	}
+	avgGain /= float64(period)
+	avgLoss /= float64(period)
+
+	values = append(values, 50.0)
+	for _, change := range changes[period:] {
+		avgGain = (avgGain*float64(period-1) + math.Max(0, change)) / float64(period)
+		avgLoss = (avgLoss*float64(period-1) + math.Max(0, -change)) / float64(period)
+		if avgLoss == 0 {
+			values = append(values, 100.0)
+		} else {
+			values = append(values, 100-100/(1+avgGain/avgLoss))
+		}
+	}
+	for len(values) < len(prices) {
+		values = append(values, values[len(values)-1])
+	}
+	return values
+}
+
+// BollingerBands returns the middle, upper and lower bands
+func BollingerBands(prices []float64, period int, stdDev float64) (middle, upper, lower []float64) {
+	middle = SMA(prices, period)
+	upper = make([]float64, len(prices))
+	lower = make([]float64, len(prices))
+	for i := range prices {
+		start := 0
+		if i >= period-1 {
+			start = i - period + 1
+		}
+		std := sampleStdDev(prices[start : i+1])
+		upper[i] = middle[i] + stdDev*std
+		lower[i] = middle[i] - stdDev*std
+	}
+	return middle, upper, lower
+}
+
+// MACD returns the MACD line and its signal line
+func MACD(prices []float64, fast, slow, signal int) (macd, signalLine []float64) {
+	emaFast := EMA(prices, fast)
+	emaSlow := EMA(prices, slow)
+	macd = make([]float64, len(prices))
+	for i := range prices {
+		macd[i] = emaFast[i] - emaSlow[i]
+	}
+	return macd, EMA(macd, signal)
+}
+
+// mean returns the arithmetic mean, summing in order like Python's sum()
+func mean(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values))
+}
+
+// sampleStdDev matches statistics.stdev; fewer than two values yield 0
+func sampleStdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	var ss float64
+	for _, v := range values {
+		ss += (v - m) * (v - m)
+	}
+	return math.Sqrt(ss / float64(len(values)-1))
+}
+
+// linearFit returns the least-squares slope and intercept over x = 0..n-1
+func linearFit(prices []float64) (slope, intercept float64) {
+	n := float64(len(prices))
+	xMean := (n - 1) / 2
+	yMean := mean(prices)
+
+	var numerator, denominator float64
+	for i, price := range prices {
+		dx := float64(i) - xMean
+		numerator += dx * (price - yMean)
+		denominator += dx * dx
+	}
+	if denominator != 0 {
+		slope = numerator / denominator
+	}
+	return slope, yMean - slope*xMean
+}
+
+// rSquared returns the fit's coefficient of determination clamped to [0, 1]
+func rSquared(prices []float64, slope, intercept float64) float64 {
+	yMean := mean(prices)
+	var ssTot, ssRes float64
+	for i, price := range prices {
+		ssTot += (price - yMean) * (price - yMean)
+		residual := price - (slope*float64(i) + intercept)
+		ssRes += residual * residual
+	}
+	if ssTot == 0 {
+		return 0
+	}
+	return math.Max(0, math.Min(1, 1-ssRes/ssTot))
+}
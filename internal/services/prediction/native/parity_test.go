@@ -0,0 +1,155 @@
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptsDir holds the Python models the Go ports must match
+var scriptsDir = filepath.Join("..", "..", "..", "..", "scripts", "ml")
+
+// parityScript evaluates the Python models on JSON series from stdin at
+// full precision
+const parityScript = `
+import json, sys
+sys.path.insert(0, sys.argv[1])
+import predict, enhanced_predict
+ind = enhanced_predict.TechnicalIndicators
+out = []
+for prices in json.load(sys.stdin):
+    result = enhanced_predict.EnhancedPredictor().ensemble_prediction(prices)
+    macd, signal = ind.macd(prices)
+    middle, upper, lower = ind.bollinger_bands(prices)
+    out.append({
+        "simple": predict.predict_price(prices),
+        "enhanced": result["prediction"],
+        "individual": result.get("individual_predictions", {}),
+        "sma": ind.sma(prices, 5), "ema": ind.ema(prices, 12), "rsi": ind.rsi(prices),
+        "macd": macd, "signal": signal, "upper": upper, "lower": lower,
+    })
+print(json.dumps(out))
+`
+
+type pythonResult struct {
+	Simple     float64            `json:"simple"`
+	Enhanced   float64            `json:"enhanced"`
+	Individual map[string]float64 `json:"individual"`
+	SMA        []float64          `json:"sma"`
+	EMA        []float64          `json:"ema"`
+	RSI        []float64          `json:"rsi"`
+	MACD       []float64          `json:"macd"`
+	Signal     []float64          `json:"signal"`
+	Upper      []float64          `json:"upper"`
+	Lower      []float64          `json:"lower"`
+}
+
+// paritySeries returns random walks of every length the models branch on,
+// plus flat and trending edge cases, rounded to cents like the service input
+func paritySeries() [][]float64 {
+	rng := rand.New(rand.NewSource(7))
+	var series [][]float64
+	for _, n := range []int{1, 2, 3, 4, 5, 6, 9, 10, 14, 15, 16, 19, 20, 21, 30, 60, 120} {
+		for _, vol := range []float64{0.01, 0.08} {
+			prices := make([]float64, n)
+			price := 50 + rng.Float64()*400
+			for i := range prices {
+				price *= 1 + rng.NormFloat64()*vol
+				prices[i] = math.Round(price*100) / 100
+			}
+			series = append(series, prices)
+		}
+	}
+
+	flat := make([]float64, 25)
+	rising := make([]float64, 25)
+	for i := range flat {
+		flat[i] = 100
+		rising[i] = 100 + float64(i)
+	}
+	return append(series, flat, rising)
+}
+
+func TestParityWithPythonModels(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not available")
+	}
+	if _, err := os.Stat(filepath.Join(scriptsDir, "enhanced_predict.py")); err != nil {
+		t.Skip("Python models not found")
+	}
+
+	series := paritySeries()
+	input, err := json.Marshal(series)
+	require.NoError(t, err)
+
+	cmd := exec.Command(python, "-c", parityScript, scriptsDir)
+	cmd.Stdin = bytes.NewReader(input)
+	output, err := cmd.Output()
+	require.NoError(t, err)
+
+	var expected []pythonResult
+	require.NoError(t, json.Unmarshal(output, &expected))
+	require.Len(t, expected, len(series))
+
+	for i, prices := range series {
+		want := expected[i]
+		assertClose(t, want.Simple, Simple(prices), "simple, series %d (%d prices)", i, len(prices))
+
+		got := Enhanced(prices)
+		assertClose(t, want.Enhanced, got.Prediction, "enhanced, series %d (%d prices)", i, len(prices))
+		for name, value := range want.Individual {
+			assertClose(t, value, got.Individual[name], "enhanced %s, series %d", name, i)
+		}
+
+		macd, signal := MACD(prices, 12, 26, 9)
+		_, upper, lower := BollingerBands(prices, 20, 2)
+		assertSeriesClose(t, want.SMA, SMA(prices, 5), "sma, series %d", i)
+		assertSeriesClose(t, want.EMA, EMA(prices, 12), "ema, series %d", i)
+		assertSeriesClose(t, want.RSI, RSI(prices, 14), "rsi, series %d", i)
+		assertSeriesClose(t, want.MACD, macd, "macd, series %d", i)
+		assertSeriesClose(t, want.Signal, signal, "macd signal, series %d", i)
+		assertSeriesClose(t, want.Upper, upper, "bollinger upper, series %d", i)
+		assertSeriesClose(t, want.Lower, lower, "bollinger lower, series %d", i)
+	}
+}
+
+// TestKnownPredictions pins outputs recorded from the Python models, so
+// parity is checked where python3 is not installed
+func TestKnownPredictions(t *testing.T) {
+	prices := []float64{100, 101.5, 99.8, 102.3, 103.1, 102.7, 104.2, 105, 103.9, 106.4, 107.1, 106.5}
+
+	assertClose(t, 105.62054653695546, Simple(prices), "simple")
+	assertClose(t, 107.14312176407476, Enhanced(prices).Prediction, "enhanced")
+	assertClose(t, 100.1, Simple([]float64{100}), "simple single price")
+}
+
+func TestPyRandomMatchesCPython(t *testing.T) {
+	// random.seed(123456789); random.random() / random.gauss(0, 1)
+	assert.Equal(t, 0.6414006161858726, newPyRandom(123456789).random())
+	assert.InDelta(t, -0.7882982151231055, newPyRandom(123456789).gauss(0, 1), 1e-12)
+	assert.Equal(t, 0.8444218515250481, newPyRandom(0).random())
+}
+
+func assertClose(t *testing.T, expected, actual float64, format string, args ...interface{}) {
+	t.Helper()
+	assert.InDelta(t, expected, actual, 1e-9*math.Max(1, math.Abs(expected)), append([]interface{}{format}, args...)...)
+}
+
+func assertSeriesClose(t *testing.T, expected, actual []float64, format string, args ...interface{}) {
+	t.Helper()
+	if !assert.Len(t, actual, len(expected), append([]interface{}{format}, args...)...) {
+		return
+	}
+	for i := range expected {
+		assertClose(t, expected[i], actual[i], format, args...)
+	}
+}
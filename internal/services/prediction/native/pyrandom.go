@@ -0,0 +1,97 @@
+package native
+
+import "math"
+
+// pyRandom reproduces CPython's random module (MT19937) for an integer seed,
+// so the simple model adds the same deterministic noise as predict.py
+type pyRandom struct {
+	state [624]uint32
+	index int
+}
+
+// newPyRandom seeds like random.seed(n) for a non-negative n below 2^32
+func newPyRandom(seed uint32) *pyRandom {
+	r := &pyRandom{}
+	r.initByArray([]uint32{seed})
+	return r
+}
+
+func (r *pyRandom) initGenrand(s uint32) {
+	r.state[0] = s
+	for i := 1; i < len(r.state); i++ {
+		prev := r.state[i-1]
+		r.state[i] = 1812433253*(prev^(prev>>30)) + uint32(i)
+	}
+	r.index = len(r.state)
+}
+
+func (r *pyRandom) initByArray(key []uint32) {
+	const n = len(r.state)
+	r.initGenrand(19650218)
+	i, j := 1, 0
+	k := n
+	if len(key) > k {
+		k = len(key)
+	}
+	for ; k > 0; k-- {
+		prev := r.state[i-1]
+		r.state[i] = (r.state[i] ^ ((prev ^ (prev >> 30)) * 1664525)) + key[j] + uint32(j)
+		i++
+		j++
+		if i >= n {
+			r.state[0] = r.state[n-1]
+			i = 1
+		}
+		if j >= len(key) {
+			j = 0
+		}
+	}
+	for k = n - 1; k > 0; k-- {
+		prev := r.state[i-1]
+		r.state[i] = (r.state[i] ^ ((prev ^ (prev >> 30)) * 1566083941)) - uint32(i)
+		i++
+		if i >= n {
+			r.state[0] = r.state[n-1]
+			i = 1
+		}
+	}
+	r.state[0] = 0x80000000
+}
+
+func (r *pyRandom) uint32() uint32 {
+	const n, m = 624, 397
+	if r.index >= n {
+		for kk := 0; kk < n; kk++ {
+			y := (r.state[kk] & 0x80000000) | (r.state[(kk+1)%n] & 0x7fffffff)
+			next := r.state[(kk+m)%n] ^ (y >> 1)
+			if y&1 != 0 {
+				next ^= 0x9908b0df
+			}
+			r.state[kk] = next
+		}
+		r.index = 0
+	}
+
+	y := r.state[r.index]
+	r.index++
+	y ^= y >> 11
+	y ^= (y << 7) & 0x9d2c5680
+	y ^= (y << 15) & 0xefc60000
+	y ^= y >> 18
+	return y
+}
+
+// random returns a float in [0, 1) with 53 bits of randomness
+func (r *pyRandom) random() float64 {
+	a := r.uint32() >> 5
+	b := r.uint32() >> 6
+	return (float64(a)*67108864.0 + float64(b)) * (1.0 / 9007199254740992.0)
+}
+
+// gauss returns the first normal deviate random.gauss(mu, sigma) draws
+// after seeding
+func (r *pyRandom) gauss(mu, sigma float64) float64 {
+	x2pi := r.random() * 2 * math.Pi
+	g2rad := math.Sqrt(-2.0 * math.Log(1.0-r.random()))
+	return mu + math.Cos(x2pi)*g2rad*sigma
+}
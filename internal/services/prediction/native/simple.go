@@ -0,0 +1,50 @@
+package native
+
+import "math"
+
+// SimpleNoiseFactor is the standard deviation of the simple model's noise
+// as a fraction of the price; it and simpleMaxChange match predict.py
+const (
+	SimpleNoiseFactor = 0.02
+	simpleMaxChange   = 0.10
+)
+
+// Simple forecasts the next price with the linear regression model of
+// scripts/ml/predict.py: the fitted trend plus deterministic noise seeded
+// from the prices, limited to a 10% move from the last price.
+func Simple(prices []float64) float64 {
+	if len(prices) == 0 {
+		return 100.0
+	}
+	last := prices[len(prices)-1]
+	if len(prices) < 2 {
+		return last * 1.001
+	}
+
+	slope, intercept := linearFit(prices)
+	predicted := slope*float64(len(prices)) + intercept
+
+	// random.seed(int(sum(prices) * 1000) % 2147483647)
+	total := 0.0
+	for _, price := range prices {
+		total += price
+	}
+	seed := math.Mod(math.Trunc(total*1000), 2147483647)
+	if seed < 0 {
+		seed += 2147483647
+	}
+	predicted += newPyRandom(uint32(seed)).gauss(0, predicted*SimpleNoiseFactor)
+
+	if predicted <= 0 {
+		predicted = last * 1.001
+	}
+
+	maxPrice := last * (1 + simpleMaxChange)
+	minPrice := last * (1 - simpleMaxChange)
+	if predicted > maxPrice {
+		predicted = maxPrice
+	} else if predicted < minPrice {
+		predicted = minPrice
+	}
+	return predicted
+}
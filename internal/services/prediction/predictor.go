@@ -0,0 +1,255 @@
+package prediction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"stock-prediction-us/internal/models"
+	"stock-prediction-us/internal/services/prediction/native"
+)
+
+// Predictor forecasts the next close from a series of closes
+type Predictor interface {
+	Name() string
+	Predict(ctx context.Context, prices []float64) (*models.ModelOutput, error)
+}
+
+// InputPredictor is implemented by predictors that can send a model the
+// structured input with OHLCV bars and symbol metadata
+type InputPredictor interface {
+	PredictInput(ctx context.Context, input *models.ModelInput) (*models.ModelOutput, error)
+}
+
+// SymbolPredictor is implemented by predictors whose prediction depends on
+// the symbol, such as the ensemble weighting its members by their record on
+// it. input is nil when OHLCV input is disabled.
+type SymbolPredictor interface {
+	PredictSymbol(ctx context.Context, symbol string, prices []float64, input *models.ModelInput) (*models.ModelOutput, error)
+}
+
+// HealthChecker is implemented by predictors that depend on something
+// outside the process, such as a Python worker or a model server
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// NativePredictor runs one of the pure-Go models in-process
+type NativePredictor struct {
+	model   models.PredictionModel
+	predict func(prices []float64) *models.ModelOutput
+}
+
+// NewNativePredictor returns the in-process implementation of a model
+func NewNativePredictor(model models.PredictionModel) (*NativePredictor, error) {
+	switch model {
+	case models.ModelSimpleGo:
+		return &NativePredictor{model: model, predict: simpleOutput}, nil
+	case models.ModelEnhancedGo:
+		return &NativePredictor{model: model, predict: enhancedOutput}, nil
+	default:
+		return nil, fmt.Errorf("model %s has no in-process implementation", model)
+	}
+}
+
+// Name returns the model name
+func (p *NativePredictor) Name() string {
+	return string(p.model)
+}
+
+// Predict forecasts the next close
+func (p *NativePredictor) Predict(ctx context.Context, prices []float64) (*models.ModelOutput, error) {
+	if err := validatePrices(prices); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	output := p.predict(prices)
+	output.TimingMs = map[string]float64{"total": float64(time.Since(start).Microseconds()) / 1000}
+	return checkPrediction(output)
+}
+
+// simpleOutput reports the simple model like scripts/ml/predict.py does
+func simpleOutput(prices []float64) *models.ModelOutput {
+	predicted := native.Simple(prices)
+	last := prices[len(prices)-1]
+	output := &models.ModelOutput{
+		Version:        models.ModelOutputVersion,
+		PredictedPrice: predicted,
+		ModelVersion:   "simple-go-1.0.0",
+		Quantiles:      normalQuantiles(predicted, last*native.SimpleNoiseFactor),
+		Features: map[string]float64{
+			"last_price":  last,
+			"data_points": float64(len(prices)),
+			"change_pct":  (predicted - last) / last * 100,
+		},
+	}
+	if len(prices) < 5 {
+		output.Warnings = append(output.Warnings, fmt.Sprintf("only %d prices, at least 5 recommended", len(prices)))
+	}
+	return output
+}
+
+// enhancedOutput reports the enhanced model like
+// scripts/ml/enhanced_predict.py does
+func enhancedOutput(prices []float64) *models.ModelOutput {
+	result := native.Enhanced(prices)
+	output := &models.ModelOutput{
+		Version:        models.ModelOutputVersion,
+		PredictedPrice: result.Prediction,
+		ModelVersion:   "enhanced-go-1.0.0",
+		Features: map[string]float64{
+			"volatility":     result.Volatility,
+			"data_points":    float64(len(prices)),
+			"trend_strength": result.TrendStrength,
+		},
+	}
+	for name, value := range result.Individual {
+		output.Features["prediction_"+name] = value
+	}
+	for name, value := range result.Weights {
+		output.Features["weight_"+name] = value
+	}
+
+	if result.Method == "fallback" {
+		output.Warnings = append(output.Warnings, "insufficient data, returned fallback prediction")
+	} else if len(prices) < 10 {
+		output.Warnings = append(output.Warnings, fmt.Sprintf("only %d prices, at least 10 recommended", len(prices)))
+	}
+	output.Quantiles = normalQuantiles(result.Prediction, prices[len(prices)-1]*result.Volatility)
+	return output
+}
+
+// quantileZ maps the quantile levels reported by the built-in models to
+// standard normal scores, as in scripts/ml/model_output.py
+var quantileZ = []struct{ level, z float64 }{
+	{0.025, -1.959963984540054},
+	{0.1, -1.2815515655446004},
+	{0.5, 0},
+	{0.9, 1.2815515655446004},
+	{0.975, 1.959963984540054},
+}
+
+// normalQuantiles returns quantiles of a normal forecast distribution
+func normalQuantiles(center, sigma float64) []models.Quantile {
+	if sigma <= 0 || math.IsNaN(sigma) || math.IsInf(sigma, 0) {
+		return nil
+	}
+	quantiles := make([]models.Quantile, len(quantileZ))
+	for i, q := range quantileZ {
+		quantiles[i] = models.Quantile{Level: q.level, Price: center + q.z*sigma}
+	}
+	return quantiles
+}
+
+// ScriptPredictor runs a Python model script on the worker pool
+type ScriptPredictor struct {
+	name     string
+	script   string
+	artifact string // Model artifact to load; empty for ML_MODEL_PATH
+	workers  *WorkerPool
+}
+
+// NewScriptPredictor creates a predictor for a model script taking comma
+// separated closes as its argument and printing its JSON output, or the
+// bare predicted price for legacy scripts
+func NewScriptPredictor(name, script string, workers *WorkerPool) *ScriptPredictor {
+	return &ScriptPredictor{name: name, script: script, workers: workers}
+}
+
+// Name returns the model name
+func (p *ScriptPredictor) Name() string {
+	return p.name
+}
+
+// Script returns the model script path
+func (p *ScriptPredictor) Script() string {
+	return p.script
+}
+
+// Artifact returns the model artifact the script loads, empty when it loads
+// the configured ML_MODEL_PATH
+func (p *ScriptPredictor) Artifact() string {
+	return p.artifact
+}
+
+// WithArtifact returns a copy of the predictor whose script loads the model
+// artifact at path
+func (p *ScriptPredictor) WithArtifact(path string) *ScriptPredictor {
+	bound := *p
+	bound.artifact = path
+	return &bound
+}
+
+// Predict runs the script on the closes
+func (p *ScriptPredictor) Predict(ctx context.Context, prices []float64) (*models.ModelOutput, error) {
+	if err := validatePrices(prices); err != nil {
+		return nil, err
+	}
+
+	// Convert prices to comma-separated string, at full precision so
+	// sub-dollar prices keep their digits
+	priceStrs := make([]string, len(prices))
+	for i, price := range prices {
+		priceStrs[i] = strconv.FormatFloat(price, 'f', -1, 64)
+	}
+
+	return p.run(ctx, strings.Join(priceStrs, ","))
+}
+
+// PredictInput runs the script with the structured input as JSON
+func (p *ScriptPredictor) PredictInput(ctx context.Context, input *models.ModelInput) (*models.ModelOutput, error) {
+	if err := validatePrices(input.Closes()); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode model input: %w", err)
+	}
+	return p.run(ctx, string(data))
+}
+
+// run runs the script on one input and parses what it printed
+func (p *ScriptPredictor) run(ctx context.Context, input string) (*models.ModelOutput, error) {
+	stdout, err := p.workers.RunArtifact(ctx, p.script, p.artifact, input)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := models.ParseModelOutput(stdout)
+	if err != nil {
+		return nil, err
+	}
+	return checkPrediction(output)
+}
+
+// HealthCheck checks that a worker answers, without running the model
+func (p *ScriptPredictor) HealthCheck(ctx context.Context) error {
+	return p.workers.Ping(ctx)
+}
+
+// validatePrices rejects empty series and non-positive prices
+func validatePrices(prices []float64) error {
+	if len(prices) == 0 {
+		return fmt.Errorf("no prices provided")
+	}
+	for i, price := range prices {
+		if price <= 0 {
+			return fmt.Errorf("invalid price at position %d: %f", i, price)
+		}
+	}
+	return nil
+}
+
+// checkPrediction rejects non-positive or non-finite predicted prices
+func checkPrediction(output *models.ModelOutput) (*models.ModelOutput, error) {
+	price := output.PredictedPrice
+	if price <= 0 || math.IsNaN(price) || math.IsInf(price, 0) {
+		return nil, fmt.Errorf("invalid predicted price: %f", price)
+	}
+	return output, nil
+}
@@ -0,0 +1,198 @@
+package prediction
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-prediction-us/internal/config"
+	"stock-prediction-us/internal/metrics"
+	"stock-prediction-us/internal/models"
+	"stock-prediction-us/internal/services/cache"
+	"stock-prediction-us/internal/services/prediction/native"
+)
+
+var testMetrics = metrics.NewMetrics()
+
+var testPrices = []float64{100, 101.5, 102.25, 101.75, 103, 104.5, 104, 105.25, 106, 105.5}
+
+func TestParseModelSpec(t *testing.T) {
+	spec, err := ParseModelSpec("lstm=script:scripts/ml/lstm_predict.py")
+	require.NoError(t, err)
+	assert.Equal(t, ModelSpec{Name: "lstm", Backend: BackendScript, Target: "scripts/ml/lstm_predict.py"}, spec)
+
+	spec, err = ParseModelSpec(" remote = HTTP:http://models:9000 ")
+	require.NoError(t, err)
+	assert.Equal(t, ModelSpec{Name: "remote", Backend: BackendHTTP, Target: "http://models:9000"}, spec)
+
+	spec, err = ParseModelSpec("bars=script+ohlcv:scripts/ml/advanced_predict.py")
+	require.NoError(t, err)
+	assert.Equal(t, ModelSpec{Name: "bars", Backend: BackendScript, Target: "scripts/ml/advanced_predict.py", OHLCV: true}, spec)
+
+	spec, err = ParseModelSpec("simple-go=native")
+	require.NoError(t, err)
+	assert.Equal(t, BackendNative, spec.Backend)
+
+	for _, invalid := range []string{"lstm", "=script:x.py", "lstm=script", "lstm=grpc:host:1", "simple-go=native+ohlcv"} {
+		_, err := ParseModelSpec(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func newNativeRegistry(t *testing.T) *Registry {
+	t.Helper()
+	registry := NewRegistry()
+	for _, model := range []models.PredictionModel{models.ModelSimpleGo, models.ModelEnhancedGo} {
+		predictor, err := NewNativePredictor(model)
+		require.NoError(t, err)
+		registry.Register(ModelSpec{Name: string(model), Backend: BackendNative}, predictor)
+	}
+	return registry
+}
+
+func TestHTTPPredictorAgainstModelServer(t *testing.T) {
+	server := httptest.NewServer(NewModelServer(newNativeRegistry(t)))
+	defer server.Close()
+
+	predictor := NewHTTPPredictor("enhanced-go", server.URL+"/", 5*time.Second)
+	require.NoError(t, predictor.HealthCheck(context.Background()))
+
+	output, err := predictor.Predict(context.Background(), testPrices)
+	require.NoError(t, err)
+	assert.Equal(t, native.Enhanced(testPrices).Prediction, output.PredictedPrice)
+	assert.Equal(t, models.ModelOutputVersion, output.Version)
+	assert.Equal(t, "enhanced-go-1.0.0", output.ModelVersion)
+	assert.Len(t, output.Quantiles, 5)
+	assert.Equal(t, native.Enhanced(testPrices).Volatility, output.Features["volatility"])
+
+	_, err = NewHTTPPredictor("missing", server.URL, 5*time.Second).Predict(context.Background(), testPrices)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown prediction model")
+
+	_, err = predictor.Predict(context.Background(), []float64{100, -1})
+	assert.Error(t, err)
+}
+
+func TestScriptPredictorParsesLastLine(t *testing.T) {
+	pool := newTestPool(t, WorkerSettings{Size: 1})
+	predictor := NewScriptPredictor("custom", "last.py", pool)
+
+	// Legacy scripts print a bare price
+	output, err := predictor.Predict(context.Background(), testPrices)
+	require.NoError(t, err)
+	assert.Equal(t, 105.5, output.PredictedPrice)
+	assert.Equal(t, 0, output.Version)
+	assert.NoError(t, predictor.HealthCheck(context.Background()))
+}
+
+func TestScriptPredictorReadsJSONOutput(t *testing.T) {
+	pool := newTestPool(t, WorkerSettings{Size: 1})
+	predictor := NewScriptPredictor("custom", "json.py", pool)
+
+	output, err := predictor.Predict(context.Background(), testPrices)
+	require.NoError(t, err)
+	assert.Equal(t, 105.5, output.PredictedPrice)
+	assert.Equal(t, 1, output.Version)
+	assert.Equal(t, "helper-1", output.ModelVersion)
+	assert.Equal(t, []string{"helper"}, output.Warnings)
+	upper, ok := output.QuantileAt(0.9)
+	assert.True(t, ok)
+	assert.Equal(t, 2.0, upper)
+}
+
+func TestScriptPredictorKeepsFullPrecision(t *testing.T) {
+	pool := newTestPool(t, WorkerSettings{Size: 1})
+	predictor := NewScriptPredictor("custom", "last.py", pool)
+
+	output, err := predictor.Predict(context.Background(), []float64{0.0123456789, 0.00098765432})
+	require.NoError(t, err)
+	assert.Equal(t, 0.00098765432, output.PredictedPrice)
+}
+
+func TestServiceSendsStructuredInput(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.ML.UseOHLCVData = true
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	pool := newTestPool(t, WorkerSettings{Size: 1})
+	registry := NewRegistry()
+	registry.Register(ModelSpec{Name: "bars", Backend: BackendScript, Target: "high.py", OHLCV: true}, NewScriptPredictor("bars", "high.py", pool))
+	registry.Register(ModelSpec{Name: "closes", Backend: BackendScript, Target: "last.py"}, NewScriptPredictor("closes", "last.py", pool))
+	service := &Service{config: cfg, logger: logger, metrics: testMetrics, workers: pool, registry: registry}
+
+	symbol, err := models.ParseSymbol("NVDA")
+	require.NoError(t, err)
+	input := models.NewModelInput(symbol, models.Interval1d, false, []models.StockData{
+		{Timestamp: time.Now(), Open: 0.5, High: 0.5123456, Low: 0.49, Close: 0.51, Volume: 1000},
+	})
+
+	// Models declaring OHLCV input get the bars
+	bars, _ := registry.Get("bars")
+	diagnostics, err := service.predict(context.Background(), symbol.Ticker, "bars", bars, input.Closes(), input, true)
+	require.NoError(t, err)
+	assert.Equal(t, 0.5123456, diagnostics.Output.PredictedPrice)
+	assert.Equal(t, "ohlcv", diagnostics.Input)
+	assert.Equal(t, BackendScript, diagnostics.Backend)
+
+	// Other models get the closes
+	closes, _ := registry.Get("closes")
+	diagnostics, err = service.predict(context.Background(), symbol.Ticker, "closes", closes, input.Closes(), input, true)
+	require.NoError(t, err)
+	assert.Equal(t, 0.51, diagnostics.Output.PredictedPrice)
+	assert.Equal(t, "closes", diagnostics.Input)
+
+	// With OHLCV input turned off high.py gets closes, which it rejects
+	_, err = service.predict(context.Background(), symbol.Ticker, "bars", bars, input.Closes(), input, false)
+	assert.Error(t, err)
+}
+
+func TestServiceDispatchesByModelName(t *testing.T) {
+	server := httptest.NewServer(NewModelServer(newNativeRegistry(t)))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.ML.Model = "simple-go"
+	cfg.ML.Models = []string{"enhanced-go=http:" + server.URL, "broken=grpc:nowhere"}
+	cfg.ML.HTTPTimeout = 5 * time.Second
+	cfg.ML.MaxDataPoints = 30
+	cfg.ML.MinDataPoints = 5
+	cfg.ML.WorkerPoolSize = 1
+	cfg.Stock.BuyThreshold = 0.02
+	cfg.Stock.SellThreshold = -0.02
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	service := NewEnhancedPredictionService(cfg, logger, testMetrics, cache.NewPredictionCache(time.Minute, testMetrics))
+	defer service.Close()
+
+	spec, ok := service.Models().Spec("enhanced-go")
+	require.True(t, ok)
+	assert.Equal(t, BackendHTTP, spec.Backend)
+	assert.NotContains(t, service.Models().Names(), "broken")
+
+	// The default model runs when the request names none
+	response, err := service.Predict(context.Background(), &models.PredictionRequest{Symbol: "NVDA", HistoricalData: testPrices})
+	require.NoError(t, err)
+	assert.Equal(t, native.Simple(testPrices), response.PredictedPrice)
+	assert.Equal(t, "v3.3.0-simple-go", response.ModelVersion)
+	require.NotNil(t, response.Diagnostics)
+	assert.Equal(t, BackendNative, response.Diagnostics.Backend)
+	assert.Equal(t, "simple-go-1.0.0", response.Diagnostics.Output.ModelVersion)
+
+	// A configured model replaces the built-in one of the same name
+	response, err = service.Predict(context.Background(), &models.PredictionRequest{Symbol: "NVDA", HistoricalData: testPrices, Model: "enhanced-go"})
+	require.NoError(t, err)
+	assert.Equal(t, native.Enhanced(testPrices).Prediction, response.PredictedPrice)
+	assert.Equal(t, "v3.3.0-enhanced-go", response.ModelVersion)
+	assert.Equal(t, BackendHTTP, response.Diagnostics.Backend)
+	assert.Equal(t, "enhanced-go-1.0.0", response.Diagnostics.Output.ModelVersion)
+
+	_, err = service.Predict(context.Background(), &models.PredictionRequest{Symbol: "NVDA", HistoricalData: testPrices, Model: "missing"})
+	assert.ErrorIs(t, err, ErrUnknownModel)
+}
@@ -0,0 +1,193 @@
+package prediction
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"stock-prediction-us/internal/config"
+	"stock-prediction-us/internal/models"
+)
+
+// Model backends
+const (
+	BackendScript = "script" // Python script on the worker pool
+	BackendHTTP   = "http"   // Remote model server
+	BackendNative = "native" // In-process Go model
+	BackendEnsemble = "ensemble" // Weighted blend of other registered models
+)
+
+// ErrUnknownModel is returned for a model name that is not registered
+var ErrUnknownModel = errors.New("unknown prediction model")
+
+// ModelSpec describes how a named model is served
+type ModelSpec struct {
+	Name    string `json:"name"`
+	Backend string `json:"backend"`
+	Target  string `json:"target,omitempty"` // Script path or server base URL
+	OHLCV   bool   `json:"ohlcv,omitempty"`  // Model takes the structured OHLCV input
+}
+
+// ohlcvSuffix on a backend declares that the model takes OHLCV input
+const ohlcvSuffix = "+ohlcv"
+
+// ParseModelSpec parses "name=backend:target", e.g.
+// "lstm=script:scripts/ml/lstm_predict.py" or "remote=http:http://models:9000".
+// A backend of "script+ohlcv" or "http+ohlcv" sends the model OHLCV bars
+// instead of closes.
+func ParseModelSpec(s string) (ModelSpec, error) {
+	name, rest, ok := strings.Cut(strings.TrimSpace(s), "=")
+	if !ok || strings.TrimSpace(name) == "" {
+		return ModelSpec{}, fmt.Errorf("invalid model spec %q: expected name=backend:target", s)
+	}
+	backend, target, ok := strings.Cut(rest, ":")
+	if !ok {
+		backend, target = rest, ""
+	}
+	spec := ModelSpec{
+		Name:    strings.TrimSpace(name),
+		Backend: strings.ToLower(strings.TrimSpace(backend)),
+		Target:  strings.TrimSpace(target),
+	}
+	if strings.HasSuffix(spec.Backend, ohlcvSuffix) {
+		spec.Backend = strings.TrimSuffix(spec.Backend, ohlcvSuffix)
+		spec.OHLCV = true
+	}
+
+	switch spec.Backend {
+	case BackendScript, BackendHTTP:
+		if spec.Target == "" {
+			return ModelSpec{}, fmt.Errorf("invalid model spec %q: %s backend needs a target", s, spec.Backend)
+		}
+	case BackendNative:
+		if spec.OHLCV {
+			return ModelSpec{}, fmt.Errorf("invalid model spec %q: native models take closes only", s)
+		}
+	default:
+		return ModelSpec{}, fmt.Errorf("invalid model spec %q: unknown backend %q", s, spec.Backend)
+	}
+	return spec, nil
+}
+
+// DefaultModelSpecs returns the built-in models. The simple model keeps
+// running ML_PYTHON_SCRIPT so existing deployments behave as before.
+func DefaultModelSpecs(cfg *config.Config) []ModelSpec {
+	return []ModelSpec{
+		{Name: string(models.ModelSimple), Backend: BackendScript, Target: cfg.ML.PythonScript},
+		{Name: string(models.ModelEnhanced), Backend: BackendScript, Target: "scripts/ml/enhanced_predict.py"},
+		{Name: string(models.ModelAdvanced), Backend: BackendScript, Target: "scripts/ml/advanced_predict.py",
+			OHLCV: models.ModelAdvanced.RequiresOHLCVData()},
+		{Name: string(models.ModelSimpleGo), Backend: BackendNative},
+		{Name: string(models.ModelEnhancedGo), Backend: BackendNative},
+	}
+}
+
+// NewPredictor creates the predictor a spec describes
+func NewPredictor(spec ModelSpec, workers *WorkerPool, httpTimeout time.Duration) (Predictor, error) {
+	switch spec.Backend {
+	case BackendScript:
+		return NewScriptPredictor(spec.Name, spec.Target, workers), nil
+	case BackendHTTP:
+		return NewHTTPPredictor(spec.Name, spec.Target, httpTimeout), nil
+	case BackendNative:
+		return NewNativePredictor(models.PredictionModel(spec.Name))
+	default:
+		return nil, fmt.Errorf("unknown model backend %q", spec.Backend)
+	}
+}
+
+// Registry maps model names to predictors
+type Registry struct {
+	mu         sync.RWMutex
+	predictors map[string]Predictor
+	specs      map[string]ModelSpec
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		predictors: make(map[string]Predictor),
+		specs:      make(map[string]ModelSpec),
+	}
+}
+
+// NewModelRegistry registers the built-in models and those configured in
+// ML_MODELS. A configured model replaces a built-in one of the same name;
+// invalid entries are logged and skipped.
+func NewModelRegistry(cfg *config.Config, workers *WorkerPool, logger *logrus.Logger) *Registry {
+	registry := NewRegistry()
+
+	specs := DefaultModelSpecs(cfg)
+	for _, entry := range cfg.ML.Models {
+		spec, err := ParseModelSpec(entry)
+		if err != nil {
+			logger.WithError(err).Warn("Skipping configured model")
+			continue
+		}
+		specs = append(specs, spec)
+	}
+
+	for _, spec := range specs {
+		predictor, err := NewPredictor(spec, workers, cfg.ML.HTTPTimeout)
+		if err != nil {
+			logger.WithError(err).WithField("model", spec.Name).Warn("Skipping configured model")
+			continue
+		}
+		registry.Register(spec, predictor)
+	}
+
+	logger.WithField("models", registry.Names()).Debug("Prediction models registered")
+	return registry
+}
+
+// Register adds or replaces the predictor for spec.Name
+func (r *Registry) Register(spec ModelSpec, predictor Predictor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.predictors[spec.Name] = predictor
+	r.specs[spec.Name] = spec
+}
+
+// Get returns the predictor registered under name
+func (r *Registry) Get(name string) (Predictor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	predictor, ok := r.predictors[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownModel, name)
+	}
+	return predictor, nil
+}
+
+// Has reports whether a model is registered under name
+func (r *Registry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.predictors[name]
+	return ok
+}
+
+// Spec returns how the named model is served
+func (r *Registry) Spec(name string) (ModelSpec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	spec, ok := r.specs[name]
+	return spec, ok
+}
+
+// Names returns the registered model names in sorted order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.predictors))
+	for name := range r.predictors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
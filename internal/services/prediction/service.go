@@ -4,9 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
-	"strconv"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -19,216 +17,257 @@ import (
 
 // Service handles stock price predictions
 type Service struct {
-	config  *config.Config
-	logger  *logrus.Logger
-	metrics *metrics.Metrics
-	cache   *cache.PredictionCache
+	config   *config.Config
+	logger   *logrus.Logger
+	metrics  *metrics.Metrics
+	cache    *cache.PredictionCache
+	workers  *WorkerPool
+	registry *Registry
+	residuals ResidualSource // May be nil; intervals then come from models only
+	accuracy MemberAccuracySource // May be nil; ensemble members are then equally weighted
+	versions VersionSource // May be nil; responses then carry built-in versions
+	artifactMu sync.Mutex
+	verified map[int64]bool // Registry versions whose artifact digest was checked
 }
 
-// NewService creates a new prediction service
-func NewService(cfg *config.Config, logger *logrus.Logger, metrics *metrics.Metrics, cache *cache.PredictionCache) *Service {
-	return &Service{
-		config:  cfg,
-		logger:  logger,
-		metrics: metrics,
-		cache:   cache,
-	}
+// VersionSource supplies the production version of each model from the
+// model registry
+type VersionSource interface {
+	Production(name string) (*models.ModelVersion, bool)
 }
 
-// PredictStock predicts stock price using ML model
-func (s *Service) PredictStock(ctx context.Context, req *models.PredictionRequest) (*models.PredictionResponse, error) {
-	start := time.Now()
-	
-	// Validate request
-	if err := req.Validate(); err != nil {
-		s.metrics.RecordPrediction(time.Since(start).Seconds(), false)
-		return nil, fmt.Errorf("invalid request: %w", err)
-	}
-	
-	s.logger.WithFields(logrus.Fields{
-		"symbol":      req.Symbol,
-		"data_points": len(req.HistoricalData),
-	}).Info("Processing prediction request")
-	
-	// Check cache first
-	if cached, found := s.cache.Get(req.Symbol, req.HistoricalData); found {
-		s.logger.WithField("symbol", req.Symbol).Debug("Returning cached prediction")
-		s.metrics.RecordPrediction(time.Since(start).Seconds(), true)
-		return cached, nil
+// SetVersionSource sets where model versions come from. It is called once
+// at startup, before predictions are served.
+func (s *Service) SetVersionSource(versions VersionSource) {
+	s.versions = versions
+}
+
+// NewService creates a new prediction service
+func NewService(cfg *config.Config, logger *logrus.Logger, metrics *metrics.Metrics, cache *cache.PredictionCache) *Service {
+	workers := newWorkerPool(cfg, logger, metrics)
+	service := &Service{
+		config:   cfg,
+		logger:   logger,
+		metrics:  metrics,
+		cache:    cache,
+		workers:  workers,
+		registry: NewModelRegistry(cfg, workers, logger),
 	}
 	
-	// Make prediction
-	predictedPrice, err := s.callPythonModel(ctx, req.HistoricalData)
-	if err != nil {
-		s.metrics.RecordPrediction(time.Since(start).Seconds(), false)
-		return nil, fmt.Errorf("prediction failed: %w", err)
+	// The ensemble blends models already registered, so it is added last
+	if cfg.ML.EnableEnsemble {
+		ensemble, err := newEnsemblePredictor(service, cfg.ML.EnsembleModels)
+		if err != nil {
+			logger.WithError(err).Warn("Ensemble model disabled")
+		} else {
+			service.registry.Register(ModelSpec{Name: ensemble.Name(), Backend: BackendEnsemble}, ensemble)
+			logger.WithField("members", ensemble.Members()).Info("Using ensemble prediction model")
+		}
 	}
 	
-	// Get current price (last data point)
-	currentPrice := req.HistoricalData[len(req.HistoricalData)-1]
-	
-	// Generate trading signal
-	signal := models.GenerateTradingSignal(
-		currentPrice, 
-		predictedPrice, 
-		s.config.Stock.BuyThreshold, 
-		s.config.Stock.SellThreshold,
-	)
-	
-	// Use historical data directly for advanced confidence calculation
-	// req.HistoricalData is already []float64
-	confidence := models.CalculateAdvancedConfidence(currentPrice, predictedPrice, req.HistoricalData)
-	
-	// Create response
-	response := &models.PredictionResponse{
-		Symbol:         req.Symbol,
-		CurrentPrice:   currentPrice,
-		PredictedPrice: predictedPrice,
-		TradingSignal:  string(signal),
-		Confidence:     confidence,
-		PredictionTime: time.Now(),
-		ModelVersion:   "v3.3.0", // This could be dynamic based on actual model version
+	if !service.registry.Has(cfg.ML.Model) {
+		logger.WithField("model", cfg.ML.Model).Warn("Unknown default prediction model, using simple")
+	} else if spec, _ := service.registry.Spec(cfg.ML.Model); spec.Backend == BackendNative {
+		logger.WithField("model", cfg.ML.Model).Info("Using in-process Go prediction model")
 	}
 	
-	// Cache the result
-	s.cache.Set(req.Symbol, req.HistoricalData, response)
-	
-	s.logger.WithFields(logrus.Fields{
-		"symbol":          req.Symbol,
-		"current_price":   currentPrice,
-		"predicted_price": predictedPrice,
-		"signal":          signal,
-		"confidence":      confidence,
-		"duration":        time.Since(start),
-	}).Info("Prediction completed")
-	
-	s.metrics.RecordPrediction(time.Since(start).Seconds(), true)
-	return response, nil
+	return service
 }
 
-// callPythonModel executes the Python ML model
-func (s *Service) callPythonModel(ctx context.Context, prices []float64) (float64, error) {
-	// Convert prices to comma-separated string
-	priceStrs := make([]string, len(prices))
-	for i, price := range prices {
-		priceStrs[i] = fmt.Sprintf("%.2f", price)
-	}
-	inputString := strings.Join(priceStrs, ",")
-	
-	s.logger.WithFields(logrus.Fields{
-		"script": s.config.ML.PythonScript,
-		"input":  inputString,
-	}).Debug("Calling Python model")
-	
-	// Create command with context for timeout
+// newWorkerPool creates the Python worker pool that runs the model scripts
+func newWorkerPool(cfg *config.Config, logger *logrus.Logger, metrics *metrics.Metrics) *WorkerPool {
 	// Use virtual environment Python interpreter
-	venvPython := "venv/bin/python3"
-	if _, err := os.Stat(venvPython); os.IsNotExist(err) {
+	python := "venv/bin/python3"
+	if _, err := os.Stat(python); os.IsNotExist(err) {
 		// Fallback to system python if venv doesn't exist
-		venvPython = "python3"
+		python = "python3"
 	}
-	cmd := exec.CommandContext(ctx, venvPython, s.config.ML.PythonScript, inputString)
-	
-	// Set working directory to project root to ensure model files are found
-	// Note: ModelPath is a file path, not a directory path
-	// cmd.Dir should be set to the project root, not the model file path
-	
-	// Capture both stdout and stderr
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		s.logger.WithFields(logrus.Fields{
-			"error":  err,
-			"output": string(output),
-		}).Error("Python model execution failed")
-		return 0, fmt.Errorf("model execution failed: %w, output: %s", err, string(output))
+
+	var env []string
+	if cfg.ML.DebugMode {
+		env = append(env, "DEBUG=1")
 	}
-	
-	// Parse output
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(lines) == 0 {
-		return 0, fmt.Errorf("no output from Python model")
+
+	return NewWorkerPool(WorkerSettings{
+		Command:        python,
+		Args:           []string{cfg.ML.WorkerScript},
+		Env:            env,
+		Size:           cfg.ML.WorkerPoolSize,
+		RequestTimeout: cfg.ML.WorkerTimeout,
+		MaxRequests:    cfg.ML.WorkerMaxRequests,
+	}, logger, metrics)
+}
+
+// predict runs a model, sending the structured input instead of the closes
+// to models that declare they take it, and describes how it ran. Script
+// models load their production version's artifact, once its digest checks
+// out, so the version in the diagnostics is the one that made the prediction.
+func (s *Service) predict(ctx context.Context, symbol string, model string, predictor Predictor, prices []float64, input *models.ModelInput, useOHLCV bool) (*models.PredictionDiagnostics, error) {
+	spec, _ := s.registry.Spec(model)
+	diagnostics := &models.PredictionDiagnostics{
+		Backend:    spec.Backend,
+		Input:      "closes",
+		DataPoints: len(prices),
+		Version:    builtinModelVersion(model),
 	}
-	
-	// Get the last non-empty line (predicted price)
-	var predictedPriceStr string
-	for i := len(lines) - 1; i >= 0; i-- {
-		if strings.TrimSpace(lines[i]) != "" {
-			predictedPriceStr = strings.TrimSpace(lines[i])
-			break
+	if script, ok := predictor.(*ScriptPredictor); ok {
+		if version, ok := s.productionVersion(model); ok {
+			if err := s.verifyArtifact(ctx, version); err != nil {
+				return nil, err
+			}
+			predictor = script.WithArtifact(version.ArtifactPath)
+			diagnostics.Version = version.Version
+			diagnostics.VersionID = version.ID
+			diagnostics.Artifact = version.ArtifactPath
 		}
 	}
 	
-	if predictedPriceStr == "" {
-		return 0, fmt.Errorf("empty prediction output")
+	start := time.Now()
+	var output *models.ModelOutput
+	var err error
+	inputPredictor, ok := predictor.(InputPredictor)
+	if symbolPredictor, isSymbol := predictor.(SymbolPredictor); isSymbol {
+		if !useOHLCV {
+			input = nil
+		}
+		output, err = symbolPredictor.PredictSymbol(ctx, symbol, prices, input)
+	} else if input != nil && useOHLCV && spec.OHLCV && ok {
+		diagnostics.Input = "ohlcv"
+		diagnostics.DataPoints = len(input.Bars)
+		output, err = inputPredictor.PredictInput(ctx, input)
+	} else {
+		output, err = predictor.Predict(ctx, prices)
 	}
-	
-	// Parse predicted price
-	predictedPrice, err := strconv.ParseFloat(predictedPriceStr, 64)
 	if err != nil {
-		return 0, fmt.Errorf("failed to parse prediction output '%s': %w", predictedPriceStr, err)
+		return nil, err
 	}
 	
-	// Validate predicted price
-	if predictedPrice <= 0 {
-		return 0, fmt.Errorf("invalid predicted price: %f", predictedPrice)
+	diagnostics.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+	diagnostics.Output = output
+	if len(output.Warnings) > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"model":    model,
+			"warnings": output.Warnings,
+		}).Debug("Model reported warnings")
 	}
-	
-	s.logger.WithField("predicted_price", predictedPrice).Debug("Python model prediction successful")
-	return predictedPrice, nil
+	return diagnostics, nil
+}
+
+// Models returns the registered prediction models
+func (s *Service) Models() *Registry {
+	return s.registry
+}
+
+// modelVersion identifies the model that serves predictions: the production
+// version and registry id of the artifact it loads, or its built-in version
+// and 0 when it loads none from the registry
+func (s *Service) modelVersion(model string) (string, int64) {
+	if version, ok := s.productionVersion(model); ok {
+		return version.Version, version.ID
+	}
+	return builtinModelVersion(model), 0
+}
+
+// builtinModelVersion identifies a model with no registered version
+func builtinModelVersion(model string) string {
+	if model == string(models.ModelSimple) {
+		return "v3.3.0" // This could be dynamic based on actual model version
+	}
+	return "v3.3.0-" + model
+}
+
+// eventCacheSuffix keys cached predictions by target date and event flag,
+// since the same closes can be flagged for one target date and not the next
+func eventCacheSuffix(risk *models.EventRisk) string {
+	if risk == nil {
+		return ""
+	}
+	return fmt.Sprintf("_%s_%t", risk.TargetDate.Format("2006-01-02"), risk.Flagged)
+}
+
+// versionCacheSuffix keys cached predictions by the registry versions that
+// make them, so a promotion or rollback takes effect at once
+func (s *Service) versionCacheSuffix(model string) string {
+	names := []string{model}
+	if predictor, err := s.registry.Get(model); err == nil {
+		if ensemble, ok := predictor.(*EnsemblePredictor); ok {
+			names = ensemble.Members()
+		}
+	}
+	suffix := ""
+	for _, name := range names {
+		if version, ok := s.productionVersion(name); ok {
+			suffix += fmt.Sprintf("_%s@%d", name, version.ID)
+		}
+	}
+	return suffix
 }
 
-// HealthCheck checks if the prediction service is healthy
-func (s *Service) HealthCheck() error {
-	// Check if Python script exists
-	if _, err := os.Stat(s.config.ML.PythonScript); os.IsNotExist(err) {
-		return fmt.Errorf("Python script not found: %s", s.config.ML.PythonScript)
+// horizonCacheSuffix keys cached predictions by their forecast path, which
+// depends on the requested horizons and their target dates
+func horizonCacheSuffix(req *models.PredictionRequest) string {
+	if len(req.Horizons) == 0 {
+		return ""
+	}
+	furthest := models.MaxHorizon(req.Horizons)
+	return fmt.Sprintf("_h%v_%s", req.Horizons, req.ForecastDates[furthest-1].Format("2006-01-02"))
+}
+
+// checkModel checks that a model can serve predictions
+func (s *Service) checkModel(model string) error {
+	predictor, err := s.registry.Get(model)
+	if err != nil {
+		return err
 	}
 	
-	// Check if model files exist
-	if _, err := os.Stat(s.config.ML.ModelPath); os.IsNotExist(err) {
-		return fmt.Errorf("model path not found: %s", s.config.ML.ModelPath)
+	// Script models need the script and the model files on disk;
+	// in-process models need neither
+	if script, ok := predictor.(*ScriptPredictor); ok {
+		if _, err := os.Stat(script.Script()); os.IsNotExist(err) {
+			return fmt.Errorf("Python script not found: %s", script.Script())
+		}
+		
+		// A registry version's artifact replaces the configured model files
+		if version, ok := s.productionVersion(model); ok {
+			if _, err := os.Stat(version.ArtifactPath); os.IsNotExist(err) {
+				return fmt.Errorf("model artifact not found: %s", version.ArtifactPath)
+			}
+		} else {
+			// Check if model files exist
+			if _, err := os.Stat(s.config.ML.ModelPath); os.IsNotExist(err) {
+				return fmt.Errorf("model path not found: %s", s.config.ML.ModelPath)
+			}
+			
+			if _, err := os.Stat(s.config.ML.ScalerPath); os.IsNotExist(err) {
+				return fmt.Errorf("scaler file not found: %s", s.config.ML.ScalerPath)
+			}
+		}
 	}
 	
-	if _, err := os.Stat(s.config.ML.ScalerPath); os.IsNotExist(err) {
-		return fmt.Errorf("scaler file not found: %s", s.config.ML.ScalerPath)
+	checker, ok := predictor.(HealthChecker)
+	if !ok {
+		return nil
 	}
 	
-	// Test with dummy data
+	// Check that the backend answers; probes must not run a full prediction
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	
-	testData := []float64{100.0, 101.0, 102.0, 103.0, 104.0}
-	_, err := s.callPythonModel(ctx, testData)
-	if err != nil {
+	if err := checker.HealthCheck(ctx); err != nil {
 		return fmt.Errorf("model health check failed: %w", err)
 	}
 	
 	return nil
 }
 
-// GetModelInfo returns information about the current model
-func (s *Service) GetModelInfo() map[string]interface{} {
-	info := map[string]interface{}{
-		"model_path":    s.config.ML.ModelPath,
-		"scaler_path":   s.config.ML.ScalerPath,
-		"python_script": s.config.ML.PythonScript,
-		"version":       "v3.3.0",
-	}
-	
-	// Check if files exist
-	if _, err := os.Stat(s.config.ML.ModelPath); err == nil {
-		if stat, err := os.Stat(s.config.ML.ModelPath); err == nil {
-			info["model_modified"] = stat.ModTime()
-		}
-	}
-	
-	if _, err := os.Stat(s.config.ML.ScalerPath); err == nil {
-		if stat, err := os.Stat(s.config.ML.ScalerPath); err == nil {
-			info["scaler_modified"] = stat.ModTime()
-		}
-	}
-	
-	return info
+// GetWorkerPoolStats returns the Python worker pool statistics
+func (s *Service) GetWorkerPoolStats() WorkerPoolStats {
+	return s.workers.Stats()
+}
+
+// Close stops the Python workers
+func (s *Service) Close() {
+	s.workers.Close()
 }
 
 // ClearCache clears the prediction cache
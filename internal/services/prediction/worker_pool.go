@@ -0,0 +1,457 @@
+package prediction
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"stock-prediction-us/internal/metrics"
+)
+
+// ErrWorkerPoolClosed is returned for requests made after Close
+var ErrWorkerPoolClosed = errors.New("python worker pool is closed")
+
+// WorkerSettings configures the Python worker pool
+type WorkerSettings struct {
+	Command        string        // Interpreter, e.g. venv/bin/python3
+	Args           []string      // Arguments starting the worker loop, e.g. scripts/ml/worker.py
+	Env            []string      // Extra environment variables for the workers
+	Size           int           // Number of worker processes
+	RequestTimeout time.Duration // Deadline per prediction; the worker is killed when exceeded
+	MaxRequests    int           // Recycle a worker after this many requests (0 never)
+}
+
+// WorkerPoolStats reports the pool's workers and request outcomes
+type WorkerPoolStats struct {
+	Size     int    `json:"size"`
+	Live     int    `json:"live"`     // Running worker processes
+	Busy     int    `json:"busy"`     // Workers serving a request
+	Started  uint64 `json:"started"`  // Processes started, including replacements
+	Requests uint64 `json:"requests"` // Model runs, excluding health pings
+	Failures uint64 `json:"failures"` // Model runs that returned an error
+	Timeouts uint64 `json:"timeouts"` // Workers killed for exceeding the request timeout
+	Crashes  uint64 `json:"crashes"`  // Workers that exited unexpectedly
+	Recycled uint64 `json:"recycled"` // Workers retired after MaxRequests
+}
+
+// workerRequest is one line written to a worker's stdin
+type workerRequest struct {
+	ID       uint64 `json:"id"`
+	Script   string `json:"script,omitempty"`
+	Input    string `json:"input,omitempty"`
+	Artifact string `json:"artifact,omitempty"` // Run as the script's ML_MODEL_PATH
+	Ping     bool   `json:"ping,omitempty"`
+}
+
+// workerResponse is one line read from a worker's stdout
+type workerResponse struct {
+	ID     uint64 `json:"id"`
+	Output string `json:"output"`
+	Error  string `json:"error"`
+	OK     bool   `json:"ok"`
+}
+
+// WorkerPool keeps persistent Python processes that run model scripts over a
+// line-delimited JSON protocol (see scripts/ml/worker.py), so interpreter
+// start-up and model imports are paid once per worker, not per prediction.
+// Workers start on first use; a worker that crashes or times out is replaced
+// on the next request.
+type WorkerPool struct {
+	settings WorkerSettings
+	logger   *logrus.Logger
+	metrics  *metrics.Metrics // May be nil
+
+	// slots holds one entry per pool slot; nil means the slot has no process
+	slots  chan *pythonWorker
+	nextID atomic.Uint64
+
+	mutex  sync.Mutex
+	closed bool
+	live   int
+	busy   int
+
+	started  atomic.Uint64
+	requests atomic.Uint64
+	failures atomic.Uint64
+	timeouts atomic.Uint64
+	crashes  atomic.Uint64
+	recycled atomic.Uint64
+}
+
+// pythonWorker is one running worker process
+type pythonWorker struct {
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	responses chan workerResponse // Closed when the process exits
+	done      chan struct{}       // Closed by stop; readers stop delivering
+	readers   sync.WaitGroup      // Stdout and stderr readers, which run to EOF
+	served    int
+}
+
+// NewWorkerPool creates a worker pool; no process is started until the
+// first request
+func NewWorkerPool(settings WorkerSettings, logger *logrus.Logger, metrics *metrics.Metrics) *WorkerPool {
+	if settings.Size < 1 {
+		settings.Size = 1
+	}
+
+	pool := &WorkerPool{
+		settings: settings,
+		logger:   logger,
+		metrics:  metrics,
+		slots:    make(chan *pythonWorker, settings.Size),
+	}
+	for i := 0; i < settings.Size; i++ {
+		pool.slots <- nil
+	}
+	return pool
+}
+
+// Run executes a model script with the given command line input and returns
+// what it printed. It waits for a free worker, bounded by ctx.
+func (p *WorkerPool) Run(ctx context.Context, script, input string) (string, error) {
+	return p.RunArtifact(ctx, script, "", input)
+}
+
+// RunArtifact is Run for a script loading the model artifact at the given
+// path, which the worker passes it as ML_MODEL_PATH. An empty artifact
+// leaves ML_MODEL_PATH as configured.
+func (p *WorkerPool) RunArtifact(ctx context.Context, script, artifact, input string) (string, error) {
+	start := time.Now()
+	p.requests.Add(1)
+	response, err := p.do(ctx, workerRequest{Script: script, Input: input, Artifact: artifact})
+	if err == nil && response.Error != "" {
+		err = fmt.Errorf("model execution failed: %s", response.Error)
+	}
+	if err != nil {
+		p.failures.Add(1)
+	}
+	if p.metrics != nil {
+		p.metrics.RecordPythonWorkerRequest(time.Since(start).Seconds(), err == nil)
+	}
+	if err != nil {
+		return "", err
+	}
+	return response.Output, nil
+}
+
+// Ping checks that a worker answers without running a model, starting one
+// if needed
+func (p *WorkerPool) Ping(ctx context.Context) error {
+	response, err := p.do(ctx, workerRequest{Ping: true})
+	if err != nil {
+		return err
+	}
+	if !response.OK {
+		return fmt.Errorf("python worker did not acknowledge ping")
+	}
+	return nil
+}
+
+// Stats returns a snapshot of the pool
+func (p *WorkerPool) Stats() WorkerPoolStats {
+	p.mutex.Lock()
+	live, busy := p.live, p.busy
+	p.mutex.Unlock()
+
+	return WorkerPoolStats{
+		Size:     p.settings.Size,
+		Live:     live,
+		Busy:     busy,
+		Started:  p.started.Load(),
+		Requests: p.requests.Load(),
+		Failures: p.failures.Load(),
+		Timeouts: p.timeouts.Load(),
+		Crashes:  p.crashes.Load(),
+		Recycled: p.recycled.Load(),
+	}
+}
+
+// Close stops idle workers and rejects new requests; busy workers are
+// stopped when their request finishes
+func (p *WorkerPool) Close() {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return
+	}
+	p.closed = true
+	p.mutex.Unlock()
+
+	// Empty idle slots stay available so later requests fail fast
+	idle := 0
+	for drained := false; !drained; {
+		select {
+		case worker := <-p.slots:
+			if worker != nil {
+				p.stop(worker)
+			}
+			idle++
+		default:
+			drained = true
+		}
+	}
+	for i := 0; i < idle; i++ {
+		p.slots <- nil
+	}
+}
+
+// do sends one request to a free worker and waits for its response
+func (p *WorkerPool) do(ctx context.Context, req workerRequest) (workerResponse, error) {
+	p.mutex.Lock()
+	closed := p.closed
+	p.mutex.Unlock()
+	if closed {
+		return workerResponse{}, ErrWorkerPoolClosed
+	}
+
+	var worker *pythonWorker
+	select {
+	case worker = <-p.slots:
+	case <-ctx.Done():
+		return workerResponse{}, fmt.Errorf("no python worker available: %w", ctx.Err())
+	}
+
+	p.mutex.Lock()
+	closed = p.closed
+	if !closed {
+		p.busy++
+	}
+	p.updateGauges()
+	p.mutex.Unlock()
+	if closed {
+		if worker != nil {
+			p.stop(worker)
+		}
+		p.slots <- nil
+		return workerResponse{}, ErrWorkerPoolClosed
+	}
+
+	response, worker, err := p.send(ctx, worker, req)
+
+	p.mutex.Lock()
+	p.busy--
+	closed = p.closed
+	p.updateGauges()
+	p.mutex.Unlock()
+
+	if worker != nil && (closed || p.recycleDue(worker)) {
+		if !closed {
+			p.recycled.Add(1)
+			p.recordStop("recycle")
+			p.logger.WithField("requests", worker.served).Debug("Recycling python worker")
+		}
+		p.stop(worker)
+		worker = nil
+	}
+	p.slots <- worker
+
+	return response, err
+}
+
+// send writes a request to the worker, starting or replacing the process as
+// needed, and returns the worker to put back in its slot (nil if it died)
+func (p *WorkerPool) send(ctx context.Context, worker *pythonWorker, req workerRequest) (workerResponse, *pythonWorker, error) {
+	req.ID = p.nextID.Add(1)
+	line, err := json.Marshal(req)
+	if err != nil {
+		return workerResponse{}, worker, fmt.Errorf("failed to encode worker request: %w", err)
+	}
+	line = append(line, '\n')
+
+	// A worker that died while idle is only found when writing to it;
+	// retry once on a fresh process
+	for attempt := 0; ; attempt++ {
+		if worker == nil {
+			if worker, err = p.start(); err != nil {
+				return workerResponse{}, nil, err
+			}
+		}
+		if _, err = worker.stdin.Write(line); err == nil {
+			break
+		}
+		p.crashed(worker, "python worker exited while idle")
+		worker = nil
+		if attempt > 0 {
+			return workerResponse{}, nil, fmt.Errorf("failed to send request to python worker: %w", err)
+		}
+	}
+
+	timeout := p.settings.RequestTimeout
+	if req.Ping || timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case response, ok := <-worker.responses:
+		if !ok {
+			p.crashed(worker, "python worker exited during request")
+			return workerResponse{}, nil, fmt.Errorf("python worker exited during request")
+		}
+		if response.ID != req.ID {
+			p.crashed(worker, "python worker answered out of order")
+			return workerResponse{}, nil, fmt.Errorf("python worker answered request %d, expected %d", response.ID, req.ID)
+		}
+		worker.served++
+		return response, worker, nil
+	case <-timer.C:
+		p.abandon(worker, "timeout")
+		return workerResponse{}, nil, fmt.Errorf("python worker timed out after %v", timeout)
+	case <-ctx.Done():
+		p.abandon(worker, "cancelled")
+		return workerResponse{}, nil, fmt.Errorf("python worker request cancelled: %w", ctx.Err())
+	}
+}
+
+// start launches a worker process and its stdout and stderr readers
+func (p *WorkerPool) start() (*pythonWorker, error) {
+	cmd := exec.Command(p.settings.Command, p.settings.Args...)
+	cmd.Env = append(os.Environ(), p.settings.Env...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worker stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worker stdout: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worker stderr: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start python worker: %w", err)
+	}
+
+	worker := &pythonWorker{
+		cmd:       cmd,
+		stdin:     stdin,
+		responses: make(chan workerResponse, 1),
+		done:      make(chan struct{}),
+	}
+
+	// The readers drain their pipes until the process exits, so a worker
+	// writing more than is asked of it never blocks on a full pipe
+	worker.readers.Add(2)
+	go func() {
+		defer worker.readers.Done()
+		defer close(worker.responses)
+		reader := bufio.NewReader(stdout)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				var response workerResponse
+				if jsonErr := json.Unmarshal(line, &response); jsonErr != nil {
+					p.logger.WithField("line", string(line)).Warn("Ignoring malformed python worker output")
+				} else {
+					select {
+					case worker.responses <- response:
+					case <-worker.done:
+					}
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer worker.readers.Done()
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			p.logger.WithField("pid", cmd.Process.Pid).Debug("python worker: " + scanner.Text())
+		}
+	}()
+
+	p.started.Add(1)
+	if p.metrics != nil {
+		p.metrics.RecordPythonWorkerStart()
+	}
+
+	p.mutex.Lock()
+	p.live++
+	p.updateGauges()
+	p.mutex.Unlock()
+
+	p.logger.WithField("pid", cmd.Process.Pid).Debug("Started python worker")
+	return worker, nil
+}
+
+// crashed records a worker that exited unexpectedly
+func (p *WorkerPool) crashed(worker *pythonWorker, reason string) {
+	p.crashes.Add(1)
+	p.recordStop("crash")
+	p.logger.WithField("pid", worker.cmd.Process.Pid).Warn(reason)
+	p.stop(worker)
+}
+
+// abandon kills a worker whose request timed out or was cancelled, since
+// its late answer would be read as the reply to the next request
+func (p *WorkerPool) abandon(worker *pythonWorker, reason string) {
+	if reason == "timeout" {
+		p.timeouts.Add(1)
+	}
+	p.recordStop(reason)
+	p.logger.WithField("pid", worker.cmd.Process.Pid).Warn("Killing python worker after " + reason)
+	worker.cmd.Process.Kill()
+	p.stop(worker)
+}
+
+// stop closes a worker's stdin, gives it a moment to exit and kills it.
+// Wait closes the stdout and stderr pipes, so it is only called once both
+// readers have seen EOF.
+func (p *WorkerPool) stop(worker *pythonWorker) {
+	close(worker.done)
+	worker.stdin.Close()
+
+	drained := make(chan struct{})
+	go func() {
+		worker.readers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(2 * time.Second):
+		worker.cmd.Process.Kill()
+		<-drained
+	}
+	worker.cmd.Wait()
+
+	p.mutex.Lock()
+	p.live--
+	p.updateGauges()
+	p.mutex.Unlock()
+}
+
+// recycleDue reports whether a worker has served its request quota
+func (p *WorkerPool) recycleDue(worker *pythonWorker) bool {
+	return p.settings.MaxRequests > 0 && worker.served >= p.settings.MaxRequests
+}
+
+// recordStop counts a worker stopped other than by Close
+func (p *WorkerPool) recordStop(reason string) {
+	if p.metrics != nil {
+		p.metrics.RecordPythonWorkerStop(reason)
+	}
+}
+
+// updateGauges publishes live and busy counts; callers hold the mutex
+func (p *WorkerPool) updateGauges() {
+	if p.metrics != nil {
+		p.metrics.UpdatePythonWorkers(p.live, p.busy)
+	}
+}
@@ -0,0 +1,212 @@
+package prediction
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-prediction-us/internal/models"
+)
+
+// helperWorkerEnv makes the test binary act as a worker process
+const helperWorkerEnv = "PREDICTION_HELPER_WORKER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(helperWorkerEnv) == "1" {
+		runHelperWorker()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runHelperWorker speaks the worker protocol. The input selects the
+// behaviour: "crash" exits, "hang" never answers, "fail" returns an error,
+// "flood" answers and then writes unrequested lines, the script "last.py"
+// prints a log line then the last price, "json.py" prints a log line then
+// JSON output for the last price, "high.py" prints the last high of a
+// structured input, "artifact.py" prints the contents of the artifact it was
+// given, and anything else is echoed with the process id.
+func runHelperWorker() {
+	scanner := bufio.NewScanner(os.Stdin)
+	encoder := json.NewEncoder(os.Stdout)
+	for scanner.Scan() {
+		var req workerRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			os.Exit(2)
+		}
+		switch {
+		case req.Ping:
+			encoder.Encode(workerResponse{ID: req.ID, OK: true})
+		case req.Input == "crash":
+			os.Exit(1)
+		case req.Input == "hang":
+			time.Sleep(time.Minute)
+		case req.Input == "flood":
+			for i := 0; i < 4; i++ {
+				encoder.Encode(workerResponse{ID: req.ID, Output: "flood"})
+			}
+		case req.Script == "last.py":
+			prices := strings.Split(req.Input, ",")
+			encoder.Encode(workerResponse{ID: req.ID, Output: "loading model\n" + prices[len(prices)-1] + "\n"})
+		case req.Script == "json.py":
+			prices := strings.Split(req.Input, ",")
+			encoder.Encode(workerResponse{ID: req.ID, Output: `{"note": "stray print"}` + "\n" +
+				`{"version": 1, "predicted_price": ` + prices[len(prices)-1] + `, "model_version": "helper-1",` +
+				` "quantiles": [{"level": 0.1, "price": 1}, {"level": 0.9, "price": 2}], "warnings": ["helper"]}` + "\n"})
+		case req.Script == "high.py":
+			var input models.ModelInput
+			if err := json.Unmarshal([]byte(req.Input), &input); err != nil {
+				encoder.Encode(workerResponse{ID: req.ID, Error: err.Error()})
+				continue
+			}
+			high := input.Bars[len(input.Bars)-1].High
+			encoder.Encode(workerResponse{ID: req.ID, Output: strconv.FormatFloat(high, 'f', -1, 64) + "\n"})
+		case req.Script == "artifact.py":
+			contents, err := os.ReadFile(req.Artifact)
+			if err != nil {
+				encoder.Encode(workerResponse{ID: req.ID, Error: err.Error()})
+				continue
+			}
+			encoder.Encode(workerResponse{ID: req.ID, Output: string(contents)})
+		case req.Input == "fail":
+			encoder.Encode(workerResponse{ID: req.ID, Error: "Prediction error: bad input"})
+		default:
+			encoder.Encode(workerResponse{ID: req.ID, Output: fmt.Sprintf("%s %d\n", req.Input, os.Getpid())})
+		}
+	}
+}
+
+func newTestPool(t *testing.T, settings WorkerSettings) *WorkerPool {
+	t.Helper()
+	settings.Command = os.Args[0]
+	settings.Env = []string{helperWorkerEnv + "=1"}
+	if settings.RequestTimeout == 0 {
+		settings.RequestTimeout = 5 * time.Second
+	}
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	pool := NewWorkerPool(settings, logger, nil)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// runPID runs a request and returns the pid of the worker that served it
+func runPID(t *testing.T, pool *WorkerPool) int {
+	t.Helper()
+	output, err := pool.Run(context.Background(), "model.py", "101.50")
+	require.NoError(t, err)
+	var input string
+	var pid int
+	_, err = fmt.Sscanf(output, "%s %d", &input, &pid)
+	require.NoError(t, err)
+	assert.Equal(t, "101.50", input)
+	return pid
+}
+
+func TestWorkerPoolReusesWorkers(t *testing.T) {
+	pool := newTestPool(t, WorkerSettings{Size: 1})
+
+	first := runPID(t, pool)
+	second := runPID(t, pool)
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, os.Getpid(), first)
+
+	stats := pool.Stats()
+	assert.Equal(t, 1, stats.Live)
+	assert.Equal(t, 0, stats.Busy)
+	assert.Equal(t, uint64(1), stats.Started)
+	assert.Equal(t, uint64(2), stats.Requests)
+}
+
+func TestWorkerPoolReportsModelErrors(t *testing.T) {
+	pool := newTestPool(t, WorkerSettings{Size: 1})
+
+	_, err := pool.Run(context.Background(), "model.py", "fail")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad input")
+
+	// A model error leaves the worker running
+	runPID(t, pool)
+	stats := pool.Stats()
+	assert.Equal(t, uint64(1), stats.Started)
+	assert.Equal(t, uint64(1), stats.Failures)
+}
+
+func TestWorkerPoolRestartsCrashedWorker(t *testing.T) {
+	pool := newTestPool(t, WorkerSettings{Size: 1})
+
+	before := runPID(t, pool)
+	_, err := pool.Run(context.Background(), "model.py", "crash")
+	require.Error(t, err)
+
+	after := runPID(t, pool)
+	assert.NotEqual(t, before, after)
+	stats := pool.Stats()
+	assert.Equal(t, uint64(1), stats.Crashes)
+	assert.Equal(t, uint64(2), stats.Started)
+	assert.Equal(t, 1, stats.Live)
+}
+
+func TestWorkerPoolKillsWorkerOnTimeout(t *testing.T) {
+	pool := newTestPool(t, WorkerSettings{Size: 1, RequestTimeout: 100 * time.Millisecond})
+
+	_, err := pool.Run(context.Background(), "model.py", "hang")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+
+	runPID(t, pool)
+	stats := pool.Stats()
+	assert.Equal(t, uint64(1), stats.Timeouts)
+	assert.Equal(t, uint64(2), stats.Started)
+}
+
+func TestWorkerPoolRecyclesAfterMaxRequests(t *testing.T) {
+	pool := newTestPool(t, WorkerSettings{Size: 1, MaxRequests: 2})
+
+	first := runPID(t, pool)
+	assert.Equal(t, first, runPID(t, pool))
+	third := runPID(t, pool)
+	assert.NotEqual(t, first, third)
+	assert.Equal(t, uint64(1), pool.Stats().Recycled)
+}
+
+func TestWorkerPoolPingAndClose(t *testing.T) {
+	pool := newTestPool(t, WorkerSettings{Size: 2})
+
+	require.NoError(t, pool.Ping(context.Background()))
+	assert.Equal(t, uint64(0), pool.Stats().Requests)
+
+	pool.Close()
+	assert.Equal(t, 0, pool.Stats().Live)
+	_, err := pool.Run(context.Background(), "model.py", "101.50")
+	assert.ErrorIs(t, err, ErrWorkerPoolClosed)
+}
+
+func TestWorkerPoolCloseStopsReaders(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	pool := newTestPool(t, WorkerSettings{Size: 1})
+
+	// The unrequested lines fill the response buffer and block the reader
+	output, err := pool.Run(context.Background(), "model.py", "flood")
+	require.NoError(t, err)
+	assert.Equal(t, "flood", output)
+
+	pool.Close()
+	assert.Equal(t, 0, pool.Stats().Live)
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), baseline)
+}
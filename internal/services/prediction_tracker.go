@@ -6,24 +6,48 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"stock-prediction-us/internal/models"
+	"stock-prediction-us/internal/services/events"
+	"stock-prediction-us/internal/services/marketdata"
 	"stock-prediction-us/internal/services/prediction"
 )
 
 type PredictionTrackerService struct {
 	db                    *sql.DB
 	marketCalendarService *MarketCalendarService
-	predictionService     *prediction.Service
+	predictionService     *prediction.EnhancedPredictionService
+	marketData            marketdata.MarketDataProvider
+	batchFetcher          *marketdata.BatchFetcher
+	fxRates               *marketdata.FXRates
+	eventCalendar         *events.Calendar
+}
+
+// predictionTrackingColumns are the prediction_tracking columns read by
+// scanPredictionTracking, in order
+const predictionTrackingColumns = `id, symbol, prediction_date, horizon_days, predicted_price, predicted_direction,
+			   confidence, actual_close, accuracy_mape, direction_correct,
+			   market_was_open, currency, prediction_timestamp, actual_price_timestamp,
+			   created_at, updated_at, event_types, residual_pct,
+			   model, model_version, model_version_id`
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
 }
 
 // NewPredictionTrackerService creates a new prediction tracker service
-func NewPredictionTrackerService(db *sql.DB, marketCalendarService *MarketCalendarService, predictionService *prediction.Service) *PredictionTrackerService {
+func NewPredictionTrackerService(db *sql.DB, marketCalendarService *MarketCalendarService, predictionService *prediction.EnhancedPredictionService, marketData marketdata.MarketDataProvider, batchFetcher *marketdata.BatchFetcher, fxRates *marketdata.FXRates, eventCalendar *events.Calendar) *PredictionTrackerService {
 	return &PredictionTrackerService{
 		db:                    db,
 		marketCalendarService: marketCalendarService,
 		predictionService:     predictionService,
+		marketData:            marketData,
+		batchFetcher:          batchFetcher,
+		fxRates:               fxRates,
+		eventCalendar:         eventCalendar,
 	}
 }
 
@@ -31,105 +55,209 @@ func NewPredictionTrackerService(db *sql.DB, marketCalendarService *MarketCalend
 func (s *PredictionTrackerService) CreatePrediction(req models.CreatePredictionRequest) (*models.PredictionTracking, error) {
 	query := `
 		INSERT INTO prediction_tracking (
-			symbol, prediction_date, predicted_price, predicted_direction, 
-			confidence, market_was_open, prediction_timestamp
-		) VALUES (?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(symbol, prediction_date) DO UPDATE SET
+			symbol, prediction_date, horizon_days, predicted_price, predicted_direction, 
+			confidence, market_was_open, currency, prediction_timestamp, event_types,
+			model, model_version, model_version_id
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(symbol, prediction_date, horizon_days) DO UPDATE SET
 			predicted_price = excluded.predicted_price,
 			predicted_direction = excluded.predicted_direction,
 			confidence = excluded.confidence,
 			market_was_open = excluded.market_was_open,
+			currency = excluded.currency,
 			prediction_timestamp = excluded.prediction_timestamp,
+			event_types = excluded.event_types,
+			model = excluded.model,
+			model_version = excluded.model_version,
+			model_version_id = excluded.model_version_id,
 			updated_at = CURRENT_TIMESTAMP
 	`
 
+	currency := req.Currency
+	if currency == "" {
+		currency = models.CurrencyOf(req.Symbol)
+	}
+
+	var eventTypes interface{}
+	if req.EventTypes != "" {
+		eventTypes = req.EventTypes
+	}
+
+	horizon := req.HorizonDays
+	if horizon == 0 {
+		horizon = 1
+	}
+
 	now := time.Now()
 	_, err := s.db.Exec(query,
 		req.Symbol,
 		req.PredictionDate.Format("2006-01-02"),
+		horizon,
 		req.PredictedPrice,
 		req.PredictedDirection,
 		req.Confidence,
 		req.MarketWasOpen,
+		currency,
 		now,
+		eventTypes,
+		nullString(req.Model),
+		nullString(req.ModelVersion),
+		nullID(req.ModelVersionID),
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create prediction: %v", err)
 	}
 
-	// Retrieve the created/updated record
-	return s.GetPrediction(req.Symbol, req.PredictionDate)
+	// Retrieve the created/updated record and replace its intervals
+	prediction, err := s.GetPrediction(req.Symbol, req.PredictionDate, horizon)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.saveIntervals(prediction.ID, req.Intervals); err != nil {
+		return nil, fmt.Errorf("failed to save prediction intervals: %v", err)
+	}
+	if err := s.saveMembers(prediction.ID, req.Members); err != nil {
+		return nil, fmt.Errorf("failed to save ensemble members: %v", err)
+	}
+	prediction.Intervals = req.Intervals
+
+	return prediction, nil
 }
 
-// GetPrediction retrieves a specific prediction record
-func (s *PredictionTrackerService) GetPrediction(symbol string, date time.Time) (*models.PredictionTracking, error) {
+// GetPrediction retrieves the prediction made horizon trading days ahead
+// for a symbol's close on date
+func (s *PredictionTrackerService) GetPrediction(symbol string, date time.Time, horizon int) (*models.PredictionTracking, error) {
 	query := `
-		SELECT id, symbol, prediction_date, predicted_price, predicted_direction,
-			   confidence, actual_close, accuracy_mape, direction_correct,
-			   market_was_open, prediction_timestamp, actual_price_timestamp,
-			   created_at, updated_at
+		SELECT ` + predictionTrackingColumns + `
 		FROM prediction_tracking
-		WHERE symbol = ? AND prediction_date = ?
+		WHERE symbol = ? AND prediction_date = ? AND horizon_days = ?
 	`
 
-	var p models.PredictionTracking
-	var predictionDateStr string
-	var actualPriceTimestamp sql.NullTime
-
-	err := s.db.QueryRow(query, symbol, date.Format("2006-01-02")).Scan(
-		&p.ID, &p.Symbol, &predictionDateStr, &p.PredictedPrice, &p.PredictedDirection,
-		&p.Confidence, &p.ActualClose, &p.AccuracyMAPE, &p.DirectionCorrect,
-		&p.MarketWasOpen, &p.PredictionTimestamp, &actualPriceTimestamp,
-		&p.CreatedAt, &p.UpdatedAt,
-	)
-
+	p, err := scanPredictionTracking(s.db.QueryRow(query, symbol, date.Format("2006-01-02"), horizon))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get prediction: %v", err)
 	}
 
-	// Try parsing as date first, then as timestamp if that fails
-	p.PredictionDate, err = time.Parse("2006-01-02", predictionDateStr)
+	predictions := []models.PredictionTracking{p}
+	if err := loadIntervals(s.db, predictions); err != nil {
+		return nil, err
+	}
+
+	return &predictions[0], nil
+}
+
+// getPredictionsForDate retrieves every horizon's prediction for a symbol's
+// close on date
+func (s *PredictionTrackerService) getPredictionsForDate(symbol string, date time.Time) ([]models.PredictionTracking, error) {
+	query := `
+		SELECT ` + predictionTrackingColumns + `
+		FROM prediction_tracking
+		WHERE symbol = ? AND prediction_date = ?
+		ORDER BY horizon_days
+	`
+
+	rows, err := s.db.Query(query, symbol, date.Format("2006-01-02"))
 	if err != nil {
-		// Try parsing as timestamp format
-		p.PredictionDate, err = time.Parse("2006-01-02T15:04:05Z", predictionDateStr)
+		return nil, fmt.Errorf("failed to query predictions: %v", err)
+	}
+	defer rows.Close()
+
+	var predictions []models.PredictionTracking
+	for rows.Next() {
+		p, err := scanPredictionTracking(rows)
 		if err != nil {
-			// Try parsing as timestamp with milliseconds
-			p.PredictionDate, err = time.Parse("2006-01-02T15:04:05.000Z", predictionDateStr)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse prediction date '%s': %v", predictionDateStr, err)
-			}
+			return nil, fmt.Errorf("failed to scan prediction row: %v", err)
 		}
+		predictions = append(predictions, p)
 	}
-
-	if actualPriceTimestamp.Valid {
-		p.ActualPriceTimestamp = &actualPriceTimestamp.Time
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read predictions: %v", err)
+	}
+	if len(predictions) == 0 {
+		return nil, sql.ErrNoRows
 	}
 
-	return &p, nil
+	return predictions, nil
 }
 
 // UpdateActualPrice updates the actual closing price and calculates accuracy
-func (s *PredictionTrackerService) UpdateActualPrice(req models.UpdateActualPriceRequest) error {
-	// First, get the existing prediction
-	prediction, err := s.GetPrediction(req.Symbol, req.Date)
+// for every horizon that predicted the close on the given date
+func (s *PredictionTrackerService) UpdateActualPrice(ctx context.Context, req models.UpdateActualPriceRequest) error {
+	if symbol, err := models.NormalizeSymbol(req.Symbol); err == nil {
+		req.Symbol = symbol
+	}
+
+	// First, get the existing predictions
+	predictions, err := s.getPredictionsForDate(req.Symbol, req.Date)
 	if err != nil {
 		return fmt.Errorf("prediction not found: %v", err)
 	}
 
+	// Reconcile against stored market data when no actual close was supplied
+	if req.ActualClose == 0 {
+		req.ActualClose, err = s.getClosingPrice(ctx, req.Symbol, req.Date)
+		if err != nil {
+			return fmt.Errorf("actual close not available: %v", err)
+		}
+	}
+
+	for i := range predictions {
+		if err := s.scorePrediction(ctx, req, &predictions[i]); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Updated actual price for %s on %s: $%.2f (%d horizons)", req.Symbol, req.Date.Format("2006-01-02"), req.ActualClose, len(predictions))
+	return nil
+}
+
+// scorePrediction records the actual close against one prediction. The
+// direction is judged from the last close the prediction was made on.
+func (s *PredictionTrackerService) scorePrediction(ctx context.Context, req models.UpdateActualPriceRequest, prediction *models.PredictionTracking) error {
+	var err error
+
+	// Splits between the prediction's last input close and the target date
+	// put the predicted and actual prices on different bases
+	var actions []models.CorporateAction
+	if req.Adjusted == nil || *req.Adjusted {
+		actions, err = s.getCorporateActions(ctx, req.Symbol, prediction.PredictionTimestamp)
+		if err != nil {
+			log.Printf("Failed to fetch corporate actions for %s, comparing raw prices: %v", req.Symbol, err)
+		}
+	}
+
 	// Calculate accuracy metrics
-	var accuracyMAPE *float64
+	var accuracyMAPE, residualPct *float64
 	var directionCorrect *bool
 
+	basisDate := s.predictionBasisDate(prediction)
+	factor := models.SplitFactorBetween(actions, basisDate, req.Date)
 	if prediction.PredictedPrice != nil {
-		mape := models.CalculateMAPE(*prediction.PredictedPrice, req.ActualClose)
+		predictedPrice := *prediction.PredictedPrice
+		if factor != 1 {
+			predictedPrice /= factor
+			log.Printf("Adjusted predicted price for %s by split factor %.4f: $%.2f -> $%.2f",
+				req.Symbol, factor, *prediction.PredictedPrice, predictedPrice)
+		}
+		mape := models.CalculateMAPE(predictedPrice, req.ActualClose)
 		accuracyMAPE = &mape
+		if predictedPrice > 0 {
+			residual := (req.ActualClose - predictedPrice) / predictedPrice * 100
+			residualPct = &residual
+		}
 	}
 
 	if prediction.PredictedDirection != nil && prediction.PredictedPrice != nil {
-		// Get previous day's closing price to determine actual direction
-		previousClose, err := s.getPreviousClosingPrice(req.Symbol, req.Date)
-		if err == nil && previousClose > 0 {
+		// Next-session predictions compare with the previous day's close;
+		// longer horizons with the close on or before their basis date
+		basis := req.Date
+		if prediction.HorizonDays > 1 {
+			basis = basisDate.AddDate(0, 0, 1)
+		}
+		previousBar, err := s.getPreviousClosingBar(ctx, req.Symbol, basis)
+		if err == nil && previousBar.Close > 0 {
+			previousClose := previousBar.Close / models.SplitFactorBetween(actions, previousBar.Timestamp, req.Date)
 			actualDirection := models.CalculateDirection(req.ActualClose, previousClose, 0.01)
 			correct := *prediction.PredictedDirection == actualDirection
 			directionCorrect = &correct
@@ -139,27 +267,48 @@ func (s *PredictionTrackerService) UpdateActualPrice(req models.UpdateActualPric
 	// Update the record
 	query := `
 		UPDATE prediction_tracking
-		SET actual_close = ?, accuracy_mape = ?, direction_correct = ?,
+		SET actual_close = ?, accuracy_mape = ?, direction_correct = ?, residual_pct = ?,
 			actual_price_timestamp = ?, updated_at = CURRENT_TIMESTAMP
-		WHERE symbol = ? AND prediction_date = ?
+		WHERE id = ?
 	`
 
 	now := time.Now()
 	_, err = s.db.Exec(query,
-		req.ActualClose, accuracyMAPE, directionCorrect, now,
-		req.Symbol, req.Date.Format("2006-01-02"),
+		req.ActualClose, accuracyMAPE, directionCorrect, residualPct, now,
+		prediction.ID,
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to update actual price: %v", err)
 	}
 
-	log.Printf("Updated actual price for %s on %s: $%.2f", req.Symbol, req.Date.Format("2006-01-02"), req.ActualClose)
+	// Bands are split-adjusted like the predicted price
+	_, err = s.db.Exec(`
+		UPDATE prediction_intervals
+		SET covered = (? BETWEEN lower_price / ? AND upper_price / ?)
+		WHERE prediction_id = ?
+	`, req.ActualClose, factor, factor, prediction.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update interval coverage: %v", err)
+	}
+
+	// So are ensemble member predictions
+	if req.ActualClose > 0 {
+		_, err = s.db.Exec(`
+			UPDATE prediction_members
+			SET accuracy_mape = ABS(predicted_price / ? - ?) / ? * 100
+			WHERE prediction_id = ?
+		`, factor, req.ActualClose, req.ActualClose, prediction.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update ensemble member accuracy: %v", err)
+		}
+	}
+
 	return nil
 }
 
 // ExecuteDailyPredictions runs predictions for specified symbols
-func (s *PredictionTrackerService) ExecuteDailyPredictions(req models.DailyPredictionRequest) (*models.DailyExecutionLog, error) {
+func (s *PredictionTrackerService) ExecuteDailyPredictions(ctx context.Context, req models.DailyPredictionRequest) (*models.DailyExecutionLog, error) {
 	startTime := time.Now()
 
 	// Create execution log
@@ -170,8 +319,15 @@ func (s *PredictionTrackerService) ExecuteDailyPredictions(req models.DailyPredi
 		CreatedAt:     startTime,
 	}
 
-	// Determine symbols to process
-	symbols := req.Symbols
+	// Determine symbols to process; unparsable ones are kept so they are
+	// reported as failed rather than silently dropped
+	var symbols []string
+	for _, symbol := range req.Symbols {
+		if normalized, err := models.NormalizeSymbol(symbol); err == nil {
+			symbol = normalized
+		}
+		symbols = append(symbols, symbol)
+	}
 	if len(symbols) == 0 {
 		symbols = s.getDefaultSymbols()
 	}
@@ -202,12 +358,21 @@ func (s *PredictionTrackerService) ExecuteDailyPredictions(req models.DailyPredi
 		}
 	}
 
+	// Fetch recent closes for all symbols concurrently
+	results := s.batchFetcher.FetchStockData(ctx, symbols, "1mo", models.Interval1d)
+
 	// Execute predictions for each symbol
 	var successfulSymbols []string
 	var failedSymbols []string
 
-	for _, symbol := range symbols {
-		err := s.executePredictionForSymbol(symbol, predictionDate)
+	for _, result := range results {
+		symbol := result.Symbol
+		err := result.Err
+		if err != nil {
+			err = fmt.Errorf("failed to fetch stock data: %v", err)
+		} else {
+			err = s.executePredictionForSymbol(ctx, symbol, predictionDate, result.Prices)
+		}
 		if err != nil {
 			log.Printf("Failed to execute prediction for %s: %v", symbol, err)
 			failedSymbols = append(failedSymbols, symbol)
@@ -259,66 +424,117 @@ func (s *PredictionTrackerService) ExecuteDailyPredictions(req models.DailyPredi
 }
 
 // executePredictionForSymbol executes prediction for a single symbol
-func (s *PredictionTrackerService) executePredictionForSymbol(symbol string, date time.Time) error {
+func (s *PredictionTrackerService) executePredictionForSymbol(ctx context.Context, symbol string, date time.Time, historicalData []float64) error {
 	// Check if market was open
 	wasOpen, err := s.marketCalendarService.IsMarketOpen(date.AddDate(0, 0, -1))
 	if err != nil {
 		return fmt.Errorf("failed to check market status: %v", err)
 	}
 
-	// Get prediction from the existing prediction service
-	// We need to create a prediction request with historical data
-	// For now, we'll create a simple request - in production you'd get actual historical data
-	predictionReq := &models.PredictionRequest{
-		Symbol: symbol,
-		HistoricalData: []float64{100.0, 101.0, 102.0, 103.0, 104.0}, // Placeholder data
-	}
-	
-	prediction, err := s.predictionService.PredictStock(context.Background(), predictionReq)
+	// Forecast each tracked horizon on the symbol's own market calendar; the
+	// first session is the first trading day on or after the prediction date
+	parsed, err := models.ParseSymbol(symbol)
 	if err != nil {
-		return fmt.Errorf("failed to get prediction: %v", err)
+		return fmt.Errorf("invalid symbol: %v", err)
 	}
-
-	// Create prediction tracking record
-	req := models.CreatePredictionRequest{
+	horizons := models.DefaultForecastHorizons
+	predictionReq := &models.PredictionRequest{
 		Symbol:         symbol,
-		PredictionDate: date,
-		MarketWasOpen:  wasOpen,
+		HistoricalData: historicalData,
+		Interval:       models.Interval1d,
+		RequestTime:    time.Now(),
+		Horizons:       horizons,
+		ForecastDates:  parsed.TradingDaysAfter(date.AddDate(0, 0, -1), models.MaxHorizon(horizons), s.marketCalendarService.IsTradingDay),
 	}
 
-	if prediction.PredictedPrice > 0 {
-		req.PredictedPrice = &prediction.PredictedPrice
+	// The event calendar is advisory; a lookup failure does not skip the symbol
+	if s.eventCalendar != nil {
+		risk, err := s.eventCalendar.Risk(ctx, symbol, predictionReq.ForecastDates[0])
+		if err != nil {
+			log.Printf("Failed to check corporate events for %s: %v", symbol, err)
+		} else {
+			predictionReq.EventRisk = risk
+		}
 	}
 
-	if prediction.Confidence > 0 {
-		req.Confidence = &prediction.Confidence
+	prediction, err := s.predictionService.Predict(ctx, predictionReq)
+	if err != nil {
+		return fmt.Errorf("failed to get prediction: %v", err)
 	}
 
-	// Determine direction based on trading signal
-	if prediction.TradingSignal != "" {
-		var direction string
-		switch prediction.TradingSignal {
-		case "BUY":
-			direction = models.DirectionUp
-		case "SELL":
-			direction = models.DirectionDown
-		default:
-			direction = models.DirectionHold
+	// Create a prediction tracking record per horizon
+	for _, forecast := range prediction.Forecasts {
+		req := models.CreatePredictionRequest{
+			Symbol:         symbol,
+			PredictionDate: forecast.TargetDate,
+			HorizonDays:    forecast.HorizonDays,
+			MarketWasOpen:  wasOpen,
+			Currency:       models.CurrencyOf(symbol),
+			EventTypes:     strings.Join(predictionReq.EventRisk.EventTypes(), ","),
+			Intervals:      forecast.Intervals,
+			Model:          prediction.Model,
+			ModelVersion:   prediction.ModelVersion,
+			ModelVersionID: prediction.ModelVersionID,
+		}
+		if forecast.HorizonDays > 1 {
+			req.EventTypes = s.eventTypesOn(ctx, symbol, forecast.TargetDate)
+		} else if prediction.Ensemble != nil {
+			// Members are only known for the next session
+			req.Members = prediction.Ensemble.Members
+		}
+
+		if forecast.PredictedPrice > 0 {
+			price := forecast.PredictedPrice
+			req.PredictedPrice = &price
+		}
+
+		// Confidence falls with the horizon
+		if forecast.Confidence > 0 {
+			confidence := forecast.Confidence
+			req.Confidence = &confidence
+		}
+
+		// Determine direction based on trading signal
+		if forecast.TradingSignal != "" {
+			var direction string
+			switch forecast.TradingSignal {
+			case "BUY":
+				direction = models.DirectionUp
+			case "SELL":
+				direction = models.DirectionDown
+			default:
+				direction = models.DirectionHold
+			}
+			req.PredictedDirection = &direction
+		}
+
+		if _, err := s.CreatePrediction(req); err != nil {
+			return err
 		}
-		req.PredictedDirection = &direction
 	}
 
-	_, err = s.CreatePrediction(req)
-	return err
+	return nil
+}
+
+// eventTypesOn returns the corporate events flagged for a target date,
+// comma-separated, or "" when there are none or the lookup fails
+func (s *PredictionTrackerService) eventTypesOn(ctx context.Context, symbol string, date time.Time) string {
+	if s.eventCalendar == nil {
+		return ""
+	}
+	risk, err := s.eventCalendar.Risk(ctx, symbol, date)
+	if err != nil {
+		log.Printf("Failed to check corporate events for %s on %s: %v", symbol, date.Format("2006-01-02"), err)
+		return ""
+	}
+	return strings.Join(risk.EventTypes(), ",")
 }
 
-// GetPredictionHistory retrieves prediction history with optional filtering
-func (s *PredictionTrackerService) GetPredictionHistory(query models.PredictionHistoryQuery) ([]models.PredictionTracking, error) {
+// GetPredictionHistory retrieves prediction history with optional filtering.
+// With query.Currency set, prices are converted at each prediction date's FX rate.
+func (s *PredictionTrackerService) GetPredictionHistory(ctx context.Context, query models.PredictionHistoryQuery) ([]models.PredictionTracking, error) {
 	sqlQuery := `
-		SELECT id, symbol, prediction_date, predicted_price, predicted_direction,
-			   confidence, actual_close, accuracy_mape, direction_correct,
-			   market_was_open, prediction_timestamp, actual_price_timestamp,
-			   created_at, updated_at
+		SELECT ` + predictionTrackingColumns + `
 		FROM prediction_tracking
 		WHERE 1=1
 	`
@@ -329,6 +545,11 @@ func (s *PredictionTrackerService) GetPredictionHistory(query models.PredictionH
 		args = append(args, *query.Symbol)
 	}
 
+	if query.Horizon != nil {
+		sqlQuery += " AND horizon_days = ?"
+		args = append(args, *query.Horizon)
+	}
+
 	if query.StartDate != nil {
 		sqlQuery += " AND prediction_date >= ?"
 		args = append(args, query.StartDate.Format("2006-01-02"))
@@ -374,39 +595,27 @@ func (s *PredictionTrackerService) GetPredictionHistory(query models.PredictionH
 
 	var predictions []models.PredictionTracking
 	for rows.Next() {
-		var p models.PredictionTracking
-		var predictionDateStr string
-		var actualPriceTimestamp sql.NullTime
-
-		err := rows.Scan(
-			&p.ID, &p.Symbol, &predictionDateStr, &p.PredictedPrice, &p.PredictedDirection,
-			&p.Confidence, &p.ActualClose, &p.AccuracyMAPE, &p.DirectionCorrect,
-			&p.MarketWasOpen, &p.PredictionTimestamp, &actualPriceTimestamp,
-			&p.CreatedAt, &p.UpdatedAt,
-		)
+		p, err := scanPredictionTracking(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan prediction row: %v", err)
 		}
+		predictions = append(predictions, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read prediction history: %v", err)
+	}
+	rows.Close()
 
-		// Try parsing as date first, then as timestamp if that fails
-		p.PredictionDate, err = time.Parse("2006-01-02", predictionDateStr)
-		if err != nil {
-			// Try parsing as timestamp format
-			p.PredictionDate, err = time.Parse("2006-01-02T15:04:05Z", predictionDateStr)
-			if err != nil {
-				// Try parsing as timestamp with milliseconds
-				p.PredictionDate, err = time.Parse("2006-01-02T15:04:05.000Z", predictionDateStr)
-				if err != nil {
-					return nil, fmt.Errorf("failed to parse prediction date '%s': %v", predictionDateStr, err)
-				}
-			}
-		}
+	if err := loadIntervals(s.db, predictions); err != nil {
+		return nil, err
+	}
 
-		if actualPriceTimestamp.Valid {
-			p.ActualPriceTimestamp = &actualPriceTimestamp.Time
+	if query.Currency != "" {
+		for i := range predictions {
+			if err := convertPrediction(ctx, s.fxRates, &predictions[i], query.Currency); err != nil {
+				return nil, err
+			}
 		}
-
-		predictions = append(predictions, p)
 	}
 
 	return predictions, nil
@@ -469,8 +678,240 @@ func (s *PredictionTrackerService) updateExecutionLogError(log *models.DailyExec
 	return log, err
 }
 
-func (s *PredictionTrackerService) getPreviousClosingPrice(symbol string, date time.Time) (float64, error) {
-	// This would typically call the Yahoo Finance API or use cached data
-	// For now, we'll return an error to indicate it's not implemented
-	return 0, fmt.Errorf("previous closing price lookup not implemented")
+func (s *PredictionTrackerService) getPreviousClosingBar(ctx context.Context, symbol string, date time.Time) (models.StockData, error) {
+	return s.findClosingBar(ctx, symbol, date, true)
+}
+
+func (s *PredictionTrackerService) getClosingPrice(ctx context.Context, symbol string, date time.Time) (float64, error) {
+	bar, err := s.findClosingBar(ctx, symbol, date, false)
+	return bar.Close, err
+}
+
+// findClosingBar looks up the bar on a date, or the last bar strictly
+// before it, from the market data provider (the bar store when enabled)
+func (s *PredictionTrackerService) findClosingBar(ctx context.Context, symbol string, date time.Time, before bool) (models.StockData, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	bars, err := s.marketData.FetchHistoricalData(ctx, symbol, lookbackSince(day), models.Interval1d)
+	if err != nil {
+		return models.StockData{}, fmt.Errorf("failed to fetch historical data: %v", err)
+	}
+
+	for i := len(bars) - 1; i >= 0; i-- {
+		ts := bars[i].Timestamp.UTC()
+		barDay := time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, time.UTC)
+		if before && barDay.Before(day) {
+			return bars[i], nil
+		}
+		if !before && barDay.Equal(day) {
+			return bars[i], nil
+		}
+	}
+
+	return models.StockData{}, fmt.Errorf("no closing price found for %s on %s", symbol, date.Format("2006-01-02"))
+}
+
+// getCorporateActions returns corporate actions since a few days before the given time
+func (s *PredictionTrackerService) getCorporateActions(ctx context.Context, symbol string, since time.Time) ([]models.CorporateAction, error) {
+	return s.marketData.FetchCorporateActions(ctx, symbol, lookbackSince(since))
+}
+
+// lookbackSince returns a day count reaching comfortably back past the given date
+func lookbackSince(date time.Time) int {
+	lookback := int(time.Since(date).Hours()/24) + 10
+	if lookback < 30 {
+		lookback = 30
+	}
+	return lookback
+}
+
+// predictionBasisDate returns the session of the last close a prediction
+// was made from: horizon trading days of the symbol's market before its
+// target date
+func (s *PredictionTrackerService) predictionBasisDate(prediction *models.PredictionTracking) time.Time {
+	horizon := prediction.HorizonDays
+	if horizon < 1 {
+		horizon = 1
+	}
+	symbol, err := models.ParseSymbol(prediction.Symbol)
+	if err != nil {
+		symbol = models.Symbol{Ticker: prediction.Symbol, Exchange: models.ExchangeUS}
+	}
+	return symbol.TradingDayBefore(prediction.PredictionDate, horizon, s.marketCalendarService.IsTradingDay)
+}
+
+// saveIntervals replaces the intervals stored for a prediction
+func (s *PredictionTrackerService) saveIntervals(predictionID int, intervals []models.PredictionInterval) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM prediction_intervals WHERE prediction_id = ?`, predictionID); err != nil {
+		return err
+	}
+	for _, interval := range intervals {
+		_, err := tx.Exec(`
+			INSERT INTO prediction_intervals (prediction_id, coverage, lower_price, upper_price, source)
+			VALUES (?, ?, ?, ?, ?)
+		`, predictionID, interval.Coverage, interval.Lower, interval.Upper, interval.Source)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// saveMembers replaces the ensemble members stored for a prediction.
+// Members that failed have no prediction to score and are not stored.
+func (s *PredictionTrackerService) saveMembers(predictionID int, members []models.EnsembleMember) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM prediction_members WHERE prediction_id = ?`, predictionID); err != nil {
+		return err
+	}
+	for _, member := range members {
+		if member.Error != "" {
+			continue
+		}
+		_, err := tx.Exec(`
+			INSERT INTO prediction_members (prediction_id, model, predicted_price, weight, model_version, model_version_id)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, predictionID, member.Model, member.PredictedPrice, member.Weight,
+			nullString(member.ModelVersion), nullID(member.ModelVersionID))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// loadIntervals attaches their stored intervals to predictions
+func loadIntervals(db *sql.DB, predictions []models.PredictionTracking) error {
+	if len(predictions) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*models.PredictionTracking, len(predictions))
+	placeholders := make([]string, len(predictions))
+	args := make([]interface{}, len(predictions))
+	for i := range predictions {
+		byID[predictions[i].ID] = &predictions[i]
+		placeholders[i] = "?"
+		args[i] = predictions[i].ID
+	}
+
+	rows, err := db.Query(`
+		SELECT prediction_id, coverage, lower_price, upper_price, source, covered
+		FROM prediction_intervals
+		WHERE prediction_id IN (`+strings.Join(placeholders, ", ")+`)
+		ORDER BY prediction_id, coverage
+	`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query prediction intervals: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var predictionID int
+		var interval models.PredictionInterval
+		var covered sql.NullBool
+		if err := rows.Scan(&predictionID, &interval.Coverage, &interval.Lower, &interval.Upper, &interval.Source, &covered); err != nil {
+			return fmt.Errorf("failed to scan prediction interval: %v", err)
+		}
+		if covered.Valid {
+			interval.Covered = &covered.Bool
+		}
+		if p := byID[predictionID]; p != nil {
+			p.Intervals = append(p.Intervals, interval)
+		}
+	}
+
+	return rows.Err()
+}
+
+// scanPredictionTracking scans a row selected with predictionTrackingColumns
+func scanPredictionTracking(row rowScanner) (models.PredictionTracking, error) {
+	var p models.PredictionTracking
+	var predictionDateStr string
+	var actualPriceTimestamp sql.NullTime
+	var eventTypes, model, modelVersion sql.NullString
+	var modelVersionID sql.NullInt64
+
+	err := row.Scan(
+		&p.ID, &p.Symbol, &predictionDateStr, &p.HorizonDays, &p.PredictedPrice, &p.PredictedDirection,
+		&p.Confidence, &p.ActualClose, &p.AccuracyMAPE, &p.DirectionCorrect,
+		&p.MarketWasOpen, &p.Currency, &p.PredictionTimestamp, &actualPriceTimestamp,
+		&p.CreatedAt, &p.UpdatedAt, &eventTypes, &p.ResidualPercent,
+		&model, &modelVersion, &modelVersionID,
+	)
+	if err != nil {
+		return p, err
+	}
+
+	// Try parsing as date first, then as timestamp if that fails
+	p.PredictionDate, err = time.Parse("2006-01-02", predictionDateStr)
+	if err != nil {
+		// Try parsing as timestamp format
+		p.PredictionDate, err = time.Parse("2006-01-02T15:04:05Z", predictionDateStr)
+		if err != nil {
+			// Try parsing as timestamp with milliseconds
+			p.PredictionDate, err = time.Parse("2006-01-02T15:04:05.000Z", predictionDateStr)
+			if err != nil {
+				return p, fmt.Errorf("failed to parse prediction date '%s': %v", predictionDateStr, err)
+			}
+		}
+	}
+
+	if actualPriceTimestamp.Valid {
+		p.ActualPriceTimestamp = &actualPriceTimestamp.Time
+	}
+	p.EventTypes = eventTypes.String
+	p.Model = model.String
+	p.ModelVersion = modelVersion.String
+	if modelVersionID.Valid {
+		p.ModelVersionID = &modelVersionID.Int64
+	}
+
+	return p, nil
+}
+
+// convertPrediction restates a prediction's prices in currency at the FX
+// rate of its prediction date
+func convertPrediction(ctx context.Context, fxRates *marketdata.FXRates, p *models.PredictionTracking, currency string) error {
+	if p.Currency == currency {
+		return nil
+	}
+	if fxRates == nil {
+		return fmt.Errorf("currency conversion is not available")
+	}
+
+	rate, err := fxRates.Rate(ctx, p.Currency, currency, p.PredictionDate)
+	if err != nil {
+		return fmt.Errorf("failed to convert %s prediction for %s: %w", p.Currency, p.Symbol, err)
+	}
+
+	if p.PredictedPrice != nil {
+		converted := *p.PredictedPrice * rate
+		p.PredictedPrice = &converted
+	}
+	if p.ActualClose != nil {
+		converted := *p.ActualClose * rate
+		p.ActualClose = &converted
+	}
+	for i := range p.Intervals {
+		p.Intervals[i].Lower *= rate
+		p.Intervals[i].Upper *= rate
+	}
+	p.NativeCurrency = p.Currency
+	p.Currency = currency
+	p.FXRate = &rate
+	return nil
 }
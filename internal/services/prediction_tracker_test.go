@@ -0,0 +1,301 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"stock-prediction-us/internal/models"
+)
+
+// barsProvider serves fixed daily bars
+type barsProvider struct {
+	bars []models.StockData
+}
+
+func (p *barsProvider) Name() string { return "fixture" }
+
+func (p *barsProvider) FetchLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	return 0, fmt.Errorf("not used")
+}
+
+func (p *barsProvider) FetchStockData(ctx context.Context, symbol string, period string, interval models.Interval) ([]float64, error) {
+	return nil, fmt.Errorf("not used")
+}
+
+func (p *barsProvider) FetchHistoricalData(ctx context.Context, symbol string, days int, interval models.Interval) ([]models.StockData, error) {
+	return p.bars, nil
+}
+
+func (p *barsProvider) FetchCorporateActions(ctx context.Context, symbol string, days int) ([]models.CorporateAction, error) {
+	return nil, nil
+}
+
+func (p *barsProvider) HealthCheck(ctx context.Context) error { return nil }
+
+// trackingMigrations create the prediction tracking schema
+var trackingMigrations = []string{
+	"001_prediction_tracking.sql",
+	"006_prediction_currency.sql",
+	"007_corporate_events.sql",
+	"008_prediction_horizons.sql",
+	"009_prediction_intervals.sql",
+	"010_ensemble_members.sql",
+	"011_model_registry.sql",
+}
+
+func newTestDB(t *testing.T, migrations ...string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	for _, name := range migrations {
+		migration, err := os.ReadFile("../database/migrations/" + name)
+		require.NoError(t, err)
+		_, err = db.Exec(string(migration))
+		require.NoError(t, err, name)
+	}
+	return db
+}
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestHorizonMigrationKeepsPredictions(t *testing.T) {
+	db := newTestDB(t, trackingMigrations[:3]...)
+	_, err := db.Exec(`INSERT INTO prediction_tracking (symbol, prediction_date, predicted_price, event_types) VALUES ('NVDA', '2026-10-16', 104, 'earnings')`)
+	require.NoError(t, err)
+
+	for _, name := range trackingMigrations[3:] {
+		migration, err := os.ReadFile("../database/migrations/" + name)
+		require.NoError(t, err)
+		_, err = db.Exec(string(migration))
+		require.NoError(t, err)
+	}
+
+	tracker := NewPredictionTrackerService(db, NewMarketCalendarService(db), nil, &barsProvider{}, nil, nil, nil)
+	p, err := tracker.GetPrediction("NVDA", day(2026, 10, 16), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.HorizonDays)
+	assert.Equal(t, 104.0, *p.PredictedPrice)
+	assert.Equal(t, "USD", p.Currency)
+	assert.Equal(t, "earnings", p.EventTypes)
+}
+
+func TestTrackerScoresEachHorizon(t *testing.T) {
+	db := newTestDB(t, trackingMigrations...)
+	provider := &barsProvider{bars: []models.StockData{
+		{Timestamp: day(2026, 10, 9), Close: 95},
+		{Timestamp: day(2026, 10, 15), Close: 102},
+		{Timestamp: day(2026, 10, 16), Close: 105},
+	}}
+	tracker := NewPredictionTrackerService(db, NewMarketCalendarService(db), nil, provider, nil, nil, nil)
+
+	// The next-session and one-week forecasts both target Friday's close
+	up := models.DirectionUp
+	for _, prediction := range []struct {
+		horizon int
+		price   float64
+	}{{1, 104}, {5, 100}} {
+		price := prediction.price
+		_, err := tracker.CreatePrediction(models.CreatePredictionRequest{
+			Symbol:             "NVDA",
+			PredictionDate:     day(2026, 10, 16),
+			HorizonDays:        prediction.horizon,
+			PredictedPrice:     &price,
+			PredictedDirection: &up,
+		})
+		require.NoError(t, err)
+	}
+
+	// The one-week forecast was made after the close on Friday 2026-10-09
+	_, err := db.Exec(`UPDATE prediction_tracking SET prediction_timestamp = ? WHERE horizon_days = 5`,
+		time.Date(2026, 10, 9, 21, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	require.NoError(t, tracker.UpdateActualPrice(context.Background(), models.UpdateActualPriceRequest{Symbol: "NVDA", Date: day(2026, 10, 16)}))
+
+	next, err := tracker.GetPrediction("NVDA", day(2026, 10, 16), 1)
+	require.NoError(t, err)
+	require.NotNil(t, next.ActualClose)
+	assert.Equal(t, 105.0, *next.ActualClose)
+	assert.InDelta(t, models.CalculateMAPE(104, 105), *next.AccuracyMAPE, 1e-9)
+	assert.True(t, *next.DirectionCorrect) // 102 -> 105
+
+	week, err := tracker.GetPrediction("NVDA", day(2026, 10, 16), 5)
+	require.NoError(t, err)
+	assert.InDelta(t, models.CalculateMAPE(100, 105), *week.AccuracyMAPE, 1e-9)
+	assert.True(t, *week.DirectionCorrect) // 95 -> 105
+
+	horizon := 5
+	history, err := tracker.GetPredictionHistory(context.Background(), models.PredictionHistoryQuery{Horizon: &horizon})
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, 5, history[0].HorizonDays)
+
+	accuracy := NewAccuracyCalculatorService(db, nil)
+	summary, err := accuracy.GetAccuracySummary("NVDA")
+	require.NoError(t, err)
+	require.Len(t, summary.Horizons, 2)
+	assert.Equal(t, 1, summary.Horizons[0].HorizonDays)
+	assert.Equal(t, 5, summary.Horizons[1].HorizonDays)
+	assert.Equal(t, 1, summary.Horizons[1].PredictionsWithActual)
+
+	// Headline figures cover next-session predictions only
+	assert.Equal(t, 1, summary.TotalPredictions)
+	assert.InDelta(t, models.CalculateMAPE(104, 105), summary.AverageAccuracyMAPE, 1e-9)
+	metrics, err := accuracy.GetOverallPerformanceMetrics(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, metrics.TotalPredictions)
+	assert.InDelta(t, models.CalculateMAPE(104, 105), metrics.OverallAccuracyMAPE, 1e-9)
+	require.Len(t, metrics.Horizons, 2)
+}
+
+func TestTrackerScoresOnVenueCalendar(t *testing.T) {
+	db := newTestDB(t, trackingMigrations...)
+	provider := &barsProvider{bars: []models.StockData{
+		{Timestamp: day(2025, 12, 18), Close: 90},
+		{Timestamp: day(2025, 12, 19), Close: 110},
+		{Timestamp: day(2025, 12, 26), Close: 105},
+	}}
+	tracker := NewPredictionTrackerService(db, NewMarketCalendarService(db), nil, provider, nil, nil, nil)
+
+	// Taiwan trades on Christmas, so the one-week forecast for Friday
+	// 2025-12-26 was made from the close on 2025-12-19, not 2025-12-18
+	price, up := 104.0, models.DirectionUp
+	_, err := tracker.CreatePrediction(models.CreatePredictionRequest{
+		Symbol:             "2330.TW",
+		PredictionDate:     day(2025, 12, 26),
+		HorizonDays:        5,
+		PredictedPrice:     &price,
+		PredictedDirection: &up,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, tracker.UpdateActualPrice(context.Background(), models.UpdateActualPriceRequest{Symbol: "2330.TW", Date: day(2025, 12, 26)}))
+
+	week, err := tracker.GetPrediction("2330.TW", day(2025, 12, 26), 5)
+	require.NoError(t, err)
+	require.NotNil(t, week.DirectionCorrect)
+	assert.False(t, *week.DirectionCorrect) // 110 -> 105
+}
+
+func TestTrackerScoresIntervals(t *testing.T) {
+	db := newTestDB(t, trackingMigrations...)
+	provider := &barsProvider{bars: []models.StockData{
+		{Timestamp: day(2026, 10, 15), Close: 100},
+		{Timestamp: day(2026, 10, 16), Close: 105},
+	}}
+	tracker := NewPredictionTrackerService(db, NewMarketCalendarService(db), nil, provider, nil, nil, nil)
+
+	price := 100.0
+	_, err := tracker.CreatePrediction(models.CreatePredictionRequest{
+		Symbol:         "NVDA",
+		PredictionDate: day(2026, 10, 16),
+		PredictedPrice: &price,
+		Intervals: []models.PredictionInterval{
+			{Coverage: 0.8, Lower: 97, Upper: 103, Source: models.IntervalSourceModel},
+			{Coverage: 0.95, Lower: 94, Upper: 106, Source: models.IntervalSourceModel},
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, tracker.UpdateActualPrice(context.Background(), models.UpdateActualPriceRequest{Symbol: "NVDA", Date: day(2026, 10, 16)}))
+
+	p, err := tracker.GetPrediction("NVDA", day(2026, 10, 16), 1)
+	require.NoError(t, err)
+	require.NotNil(t, p.ResidualPercent)
+	assert.InDelta(t, 5.0, *p.ResidualPercent, 1e-9)
+	require.Len(t, p.Intervals, 2)
+	assert.False(t, *p.Intervals[0].Covered) // 105 is outside 97-103
+	assert.True(t, *p.Intervals[1].Covered)  // but inside 94-106
+
+	accuracy := NewAccuracyCalculatorService(db, nil)
+	residuals, err := accuracy.Residuals(context.Background(), "NVDA", 1, 10)
+	require.NoError(t, err)
+	require.Len(t, residuals, 1)
+	assert.InDelta(t, 0.05, residuals[0], 1e-9)
+	residuals, err = accuracy.Residuals(context.Background(), "NVDA", 5, 10)
+	require.NoError(t, err)
+	assert.Empty(t, residuals)
+
+	summary, err := accuracy.GetAccuracySummary("NVDA")
+	require.NoError(t, err)
+	require.Len(t, summary.Intervals, 2)
+	assert.Equal(t, 0.8, summary.Intervals[0].Coverage)
+	assert.Equal(t, 1, summary.Intervals[0].IntervalsWithActual)
+	assert.Equal(t, 0.0, summary.Intervals[0].ObservedCoverage)
+	assert.InDelta(t, 6.0, summary.Intervals[0].AverageWidthPercent, 1e-9)
+	assert.Equal(t, 100.0, summary.Intervals[1].ObservedCoverage)
+	assert.InDelta(t, 12.0, summary.Intervals[1].AverageWidthPercent, 1e-9)
+	require.Len(t, summary.Horizons, 1)
+	assert.Len(t, summary.Horizons[0].Intervals, 2)
+}
+
+func TestTrackerScoresEnsembleMembers(t *testing.T) {
+	db := newTestDB(t, trackingMigrations...)
+	provider := &barsProvider{}
+	tracker := NewPredictionTrackerService(db, NewMarketCalendarService(db), nil, provider, nil, nil, nil)
+	accuracy := NewAccuracyCalculatorService(db, nil)
+
+	// Three sessions where simple is 1% off and enhanced 4% off
+	for _, d := range []int{14, 15, 16} {
+		date := day(2026, 10, d)
+		price := 100.0
+		_, err := tracker.CreatePrediction(models.CreatePredictionRequest{
+			Symbol:         "NVDA",
+			PredictionDate: date,
+			PredictedPrice: &price,
+			Members: []models.EnsembleMember{
+				{Model: "simple", PredictedPrice: 99, Weight: 0.5},
+				{Model: "enhanced", PredictedPrice: 96, Weight: 0.5},
+				{Model: "advanced", Weight: 0, Error: "worker timeout"},
+			},
+		})
+		require.NoError(t, err)
+
+		provider.bars = append(provider.bars, models.StockData{Timestamp: date, Close: 100})
+		require.NoError(t, tracker.UpdateActualPrice(context.Background(), models.UpdateActualPriceRequest{Symbol: "NVDA", Date: date}))
+	}
+
+	members, err := accuracy.MemberAccuracy(context.Background(), "NVDA", 2)
+	require.NoError(t, err)
+	require.Len(t, members, 2) // The failed member was not stored
+	assert.InDelta(t, 1.0, members["simple"].MAPE, 1e-9)
+	assert.Equal(t, 2, members["simple"].Samples) // Limited to the window
+	assert.InDelta(t, 4.0, members["enhanced"].MAPE, 1e-9)
+
+	members, err = accuracy.MemberAccuracy(context.Background(), "AAPL", 60)
+	require.NoError(t, err)
+	assert.Empty(t, members)
+}
+
+func TestTradingDaysAfterSkipsHolidays(t *testing.T) {
+	db := newTestDB(t, "001_prediction_tracking.sql")
+	calendar := NewMarketCalendarService(db)
+
+	// Christmas 2025 falls on a Thursday
+	nvda, err := models.ParseSymbol("NVDA")
+	require.NoError(t, err)
+	days := nvda.TradingDaysAfter(day(2025, 12, 23), 3, calendar.IsTradingDay)
+	assert.Equal(t, []time.Time{day(2025, 12, 24), day(2025, 12, 26), day(2025, 12, 29)}, days)
+
+	// Closures recorded in the calendar apply to US listings only
+	require.NoError(t, calendar.AddHoliday(day(2025, 12, 29), "Unscheduled closure"))
+	days = nvda.TradingDaysAfter(day(2025, 12, 26), 1, calendar.IsTradingDay)
+	assert.Equal(t, []time.Time{day(2025, 12, 30)}, days)
+	tsmc, err := models.ParseSymbol("2330.TW")
+	require.NoError(t, err)
+	days = tsmc.TradingDaysAfter(day(2025, 12, 23), 3, calendar.IsTradingDay)
+	assert.Equal(t, []time.Time{day(2025, 12, 24), day(2025, 12, 25), day(2025, 12, 26)}, days)
+}
@@ -0,0 +1,399 @@
+// Package stream fans quote updates out to Server-Sent Events subscribers.
+// Each symbol is polled by a single goroutine however many clients follow it,
+// at a cadence that depends on whether its market is open.
+package stream
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"stock-prediction-us/internal/metrics"
+	"stock-prediction-us/internal/models"
+	"stock-prediction-us/internal/services/marketdata"
+)
+
+// Quote is one price update for a symbol
+type Quote struct {
+	Symbol        string    `json:"symbol"`
+	Price         float64   `json:"price"`
+	PreviousClose float64   `json:"previous_close,omitempty"`
+	Change        float64   `json:"change"`
+	ChangePercent float64   `json:"change_percent"`
+	Currency      string    `json:"currency"`
+	MarketOpen    bool      `json:"market_open"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// Event is a published quote. IDs increase across all symbols so a single
+// Last-Event-ID resumes a multi-symbol stream.
+type Event struct {
+	ID    uint64
+	Quote Quote
+}
+
+// MarketClock reports US market hours; MarketCalendarService implements it
+type MarketClock interface {
+	IsMarketOpenAt(t time.Time) (bool, error)
+}
+
+// Settings configures polling and per-subscriber buffering
+type Settings struct {
+	OpenInterval   time.Duration // Poll cadence while the symbol's market is open
+	ClosedInterval time.Duration // Poll cadence outside market hours
+	BufferSize     int           // Queued events per subscriber
+	History        int           // Events kept per symbol for Last-Event-ID replay
+}
+
+// Subscription receives events for a set of symbols
+type Subscription struct {
+	symbols []string
+	events  chan Event
+	done    chan struct{}
+	lagging int // Consecutive publishes that found the buffer full
+}
+
+// Events returns the channel new events are delivered on. It is never closed;
+// watch Done to learn that the subscription was dropped.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Done is closed when the hub drops the subscriber for falling behind or
+// shuts down
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Symbols returns the normalised symbols the subscription follows
+func (s *Subscription) Symbols() []string {
+	return s.symbols
+}
+
+// poller polls one symbol on behalf of all of its subscribers
+type poller struct {
+	symbol      models.Symbol
+	subscribers map[*Subscription]struct{}
+	history     []Event
+	cancel      context.CancelFunc
+
+	// Owned by the polling goroutine
+	lastPrice     float64
+	lastOpen      bool
+	published     bool
+	previousClose float64
+	closeDate     string // Exchange-local date previousClose was fetched for
+}
+
+// Hub owns the pollers and subscriptions
+type Hub struct {
+	provider marketdata.MarketDataProvider
+	clock    MarketClock
+	settings Settings
+	logger   *logrus.Logger
+	metrics  *metrics.Metrics // May be nil
+	now      func() time.Time
+
+	mu            sync.Mutex
+	seq           uint64
+	pollers       map[string]*poller
+	subscriptions map[*Subscription]struct{}
+}
+
+// NewHub creates a quote hub. clock may be nil, in which case US hours are
+// approximated by weekdays 09:30-16:00 New York time.
+func NewHub(provider marketdata.MarketDataProvider, clock MarketClock, settings Settings, logger *logrus.Logger, metrics *metrics.Metrics) *Hub {
+	if settings.OpenInterval <= 0 {
+		settings.OpenInterval = 15 * time.Second
+	}
+	if settings.ClosedInterval <= 0 {
+		settings.ClosedInterval = 5 * time.Minute
+	}
+	if settings.BufferSize < 1 {
+		settings.BufferSize = 32
+	}
+	if settings.History < 1 {
+		settings.History = 1
+	}
+	return &Hub{
+		provider: provider,
+		clock:    clock,
+		settings: settings,
+		logger:   logger,
+		metrics:  metrics,
+		now:      time.Now,
+		// Millisecond start keeps IDs increasing across restarts, so a stale
+		// Last-Event-ID from a previous process never hides new events
+		seq:           uint64(time.Now().UnixMilli()),
+		pollers:       make(map[string]*poller),
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe follows the given normalised symbols. With a Last-Event-ID the
+// returned replay holds retained events newer than it; without one it holds
+// the latest quote per symbol. Events published after Subscribe returns are
+// delivered on the subscription channel, so replay and live events never
+// overlap.
+func (h *Hub) Subscribe(symbols []models.Symbol, lastEventID uint64, resume bool) (*Subscription, []Event) {
+	sub := &Subscription{
+		events: make(chan Event, h.settings.BufferSize),
+		done:   make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var replay []Event
+	for _, symbol := range symbols {
+		p, ok := h.pollers[symbol.Ticker]
+		if !ok {
+			p = h.startPoller(symbol)
+		}
+		if _, ok := p.subscribers[sub]; ok {
+			continue
+		}
+		p.subscribers[sub] = struct{}{}
+		sub.symbols = append(sub.symbols, symbol.Ticker)
+
+		switch {
+		case resume:
+			for _, event := range p.history {
+				if event.ID > lastEventID {
+					replay = append(replay, event)
+				}
+			}
+		case len(p.history) > 0:
+			replay = append(replay, p.history[len(p.history)-1])
+		}
+	}
+	sort.Slice(replay, func(i, j int) bool { return replay[i].ID < replay[j].ID })
+
+	h.subscriptions[sub] = struct{}{}
+	h.updateMetrics()
+	return sub, replay
+}
+
+// Unsubscribe removes a subscription and stops pollers nobody follows
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(sub)
+}
+
+// Close drops every subscriber and stops all pollers
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subscriptions {
+		h.remove(sub)
+	}
+}
+
+// Stats returns the number of subscriptions and polled symbols
+func (h *Hub) Stats() (subscribers, pollers int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscriptions), len(h.pollers)
+}
+
+// remove detaches a subscription; the caller holds h.mu
+func (h *Hub) remove(sub *Subscription) {
+	if _, ok := h.subscriptions[sub]; !ok {
+		return
+	}
+	delete(h.subscriptions, sub)
+	close(sub.done)
+
+	for _, symbol := range sub.symbols {
+		p, ok := h.pollers[symbol]
+		if !ok {
+			continue
+		}
+		delete(p.subscribers, sub)
+		if len(p.subscribers) == 0 {
+			p.cancel()
+			delete(h.pollers, symbol)
+		}
+	}
+	h.updateMetrics()
+}
+
+// startPoller registers and starts a poller; the caller holds h.mu
+func (h *Hub) startPoller(symbol models.Symbol) *poller {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &poller{
+		symbol:      symbol,
+		subscribers: make(map[*Subscription]struct{}),
+		cancel:      cancel,
+	}
+	h.pollers[symbol.Ticker] = p
+	go h.run(ctx, p)
+	return p
+}
+
+// run polls until the poller is cancelled, faster while the market is open
+func (h *Hub) run(ctx context.Context, p *poller) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		open := h.marketOpen(p.symbol, h.now())
+		h.poll(ctx, p, open)
+
+		interval := h.settings.ClosedInterval
+		if open {
+			interval = h.settings.OpenInterval
+		}
+		timer.Reset(interval)
+	}
+}
+
+// poll fetches the latest price and publishes it when it or the market state
+// changed since the last event
+func (h *Hub) poll(ctx context.Context, p *poller, open bool) {
+	now := h.now()
+	h.refreshPreviousClose(ctx, p, now)
+
+	price, err := h.provider.FetchLatestPrice(ctx, p.symbol.Ticker)
+	if err != nil {
+		if ctx.Err() == nil {
+			h.logger.WithError(err).WithField("symbol", p.symbol.Ticker).Warn("Quote stream poll failed")
+		}
+		return
+	}
+	if p.published && price == p.lastPrice && open == p.lastOpen {
+		return
+	}
+	p.lastPrice, p.lastOpen, p.published = price, open, true
+
+	quote := Quote{
+		Symbol:        p.symbol.Ticker,
+		Price:         price,
+		PreviousClose: p.previousClose,
+		Currency:      p.symbol.Currency(),
+		MarketOpen:    open,
+		Timestamp:     now.UTC(),
+	}
+	if p.previousClose > 0 {
+		quote.Change = price - p.previousClose
+		quote.ChangePercent = quote.Change / p.previousClose * 100
+	}
+	h.publish(p, quote)
+}
+
+// refreshPreviousClose loads the last close before today's session once per
+// exchange-local day
+func (h *Hub) refreshPreviousClose(ctx context.Context, p *poller, now time.Time) {
+	loc := p.symbol.Exchange.Location()
+	today := now.In(loc).Format("2006-01-02")
+	if p.closeDate == today {
+		return
+	}
+
+	bars, err := h.provider.FetchHistoricalData(ctx, p.symbol.Ticker, 5, models.Interval1d)
+	if err != nil {
+		if ctx.Err() == nil {
+			h.logger.WithError(err).WithField("symbol", p.symbol.Ticker).Debug("Quote stream could not load previous close")
+		}
+		return
+	}
+	for i := len(bars) - 1; i >= 0; i-- {
+		if bars[i].Timestamp.In(loc).Format("2006-01-02") < today {
+			p.previousClose = bars[i].Close
+			break
+		}
+	}
+	p.closeDate = today
+}
+
+// publish records an event and delivers it without blocking. A full buffer
+// drops its oldest event; a subscriber that stays full for more publishes
+// than its buffer holds is disconnected and resumes via Last-Event-ID.
+func (h *Hub) publish(p *poller, quote Quote) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// A poller replaced after its last subscriber left must not publish
+	if h.pollers[p.symbol.Ticker] != p {
+		return
+	}
+
+	h.seq++
+	event := Event{ID: h.seq, Quote: quote}
+	p.history = append(p.history, event)
+	if len(p.history) > h.settings.History {
+		p.history = p.history[len(p.history)-h.settings.History:]
+	}
+
+	var slow []*Subscription
+	for sub := range p.subscribers {
+		select {
+		case sub.events <- event:
+			sub.lagging = 0
+			continue
+		default:
+		}
+
+		select {
+		case <-sub.events:
+		default:
+		}
+		sub.events <- event
+		sub.lagging++
+		if h.metrics != nil {
+			h.metrics.RecordStreamDroppedEvent()
+		}
+		if sub.lagging > h.settings.BufferSize {
+			slow = append(slow, sub)
+		}
+	}
+
+	for _, sub := range slow {
+		h.logger.WithField("symbols", sub.symbols).Warn("Dropping slow quote stream subscriber")
+		h.remove(sub)
+	}
+}
+
+// marketOpen reports whether the symbol's market is trading at t. Crypto
+// never closes, US listings use the market calendar, currencies trade on
+// weekdays and other exchanges are approximated by weekdays 09:00-17:00
+// local time.
+func (h *Hub) marketOpen(symbol models.Symbol, t time.Time) bool {
+	local := t.In(symbol.Exchange.Location())
+	minutes := local.Hour()*60 + local.Minute()
+
+	switch {
+	case symbol.AssetClass == models.AssetCrypto:
+		return true
+	case symbol.AssetClass == models.AssetCurrency:
+		return symbol.IsTradingDay(local)
+	case symbol.IsUS():
+		if h.clock != nil {
+			open, err := h.clock.IsMarketOpenAt(t)
+			if err == nil {
+				return open
+			}
+			h.logger.WithError(err).Debug("Market calendar unavailable, assuming regular hours")
+		}
+		return symbol.IsTradingDay(local) && minutes >= 9*60+30 && minutes < 16*60
+	default:
+		return symbol.IsTradingDay(local) && minutes >= 9*60 && minutes < 17*60
+	}
+}
+
+// updateMetrics publishes subscriber and poller gauges; the caller holds h.mu
+func (h *Hub) updateMetrics() {
+	if h.metrics != nil {
+		h.metrics.UpdateStreamStats(len(h.subscriptions), len(h.pollers))
+	}
+}
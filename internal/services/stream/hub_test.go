@@ -0,0 +1,162 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-prediction-us/internal/models"
+)
+
+// tickingProvider returns a higher price on every poll
+type tickingProvider struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (p *tickingProvider) Name() string { return "ticking" }
+
+func (p *tickingProvider) FetchLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls[symbol]++
+	return 100 + float64(p.calls[symbol]), nil
+}
+
+func (p *tickingProvider) FetchStockData(ctx context.Context, symbol string, period string, interval models.Interval) ([]float64, error) {
+	return nil, fmt.Errorf("not used")
+}
+
+func (p *tickingProvider) FetchHistoricalData(ctx context.Context, symbol string, days int, interval models.Interval) ([]models.StockData, error) {
+	yesterday := time.Now().AddDate(0, 0, -1)
+	return []models.StockData{{Symbol: symbol, Timestamp: yesterday, Close: 100}}, nil
+}
+
+func (p *tickingProvider) FetchCorporateActions(ctx context.Context, symbol string, days int) ([]models.CorporateAction, error) {
+	return nil, nil
+}
+
+func (p *tickingProvider) HealthCheck(ctx context.Context) error { return nil }
+
+// fixedClock reports the US market as always open or closed
+type fixedClock bool
+
+func (c fixedClock) IsMarketOpenAt(t time.Time) (bool, error) { return bool(c), nil }
+
+func newTestHub(bufferSize int) (*Hub, *tickingProvider) {
+	provider := &tickingProvider{calls: make(map[string]int)}
+	hub := NewHub(provider, fixedClock(true), Settings{
+		OpenInterval:   5 * time.Millisecond,
+		ClosedInterval: 5 * time.Millisecond,
+		BufferSize:     bufferSize,
+		History:        10,
+	}, logrus.New(), nil)
+	return hub, provider
+}
+
+func mustParse(t *testing.T, raw string) models.Symbol {
+	symbol, err := models.ParseSymbol(raw)
+	require.NoError(t, err)
+	return symbol
+}
+
+func receive(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+	select {
+	case event := <-sub.Events():
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for quote event")
+		return Event{}
+	}
+}
+
+func TestHubSharesOnePollerPerSymbol(t *testing.T) {
+	hub, _ := newTestHub(64)
+	defer hub.Close()
+
+	btc := mustParse(t, "BTC-USD")
+	first, _ := hub.Subscribe([]models.Symbol{btc}, 0, false)
+	second, _ := hub.Subscribe([]models.Symbol{btc, mustParse(t, "AAPL")}, 0, false)
+
+	subscribers, pollers := hub.Stats()
+	assert.Equal(t, 2, subscribers)
+	assert.Equal(t, 2, pollers)
+
+	event := receive(t, first)
+	assert.Equal(t, "BTC-USD", event.Quote.Symbol)
+	assert.Equal(t, 100.0, event.Quote.PreviousClose)
+	assert.InDelta(t, event.Quote.Price-100, event.Quote.Change, 1e-9)
+
+	hub.Unsubscribe(first)
+	_, pollers = hub.Stats()
+	assert.Equal(t, 2, pollers, "BTC-USD is still followed by the second subscriber")
+
+	hub.Unsubscribe(second)
+	subscribers, pollers = hub.Stats()
+	assert.Equal(t, 0, subscribers)
+	assert.Equal(t, 0, pollers)
+}
+
+func TestHubReplaysEventsAfterLastEventID(t *testing.T) {
+	hub, _ := newTestHub(64)
+	defer hub.Close()
+
+	btc := mustParse(t, "BTC-USD")
+	sub, _ := hub.Subscribe([]models.Symbol{btc}, 0, false)
+	events := []Event{receive(t, sub), receive(t, sub), receive(t, sub)}
+	assert.Less(t, events[0].ID, events[1].ID)
+
+	resumed, replay := hub.Subscribe([]models.Symbol{btc}, events[0].ID, true)
+	defer hub.Unsubscribe(resumed)
+	require.GreaterOrEqual(t, len(replay), 2)
+	assert.Equal(t, events[1], replay[0])
+	assert.Equal(t, events[2], replay[1])
+
+	// Without a Last-Event-ID only the latest quote is replayed
+	fresh, replay := hub.Subscribe([]models.Symbol{btc}, 0, false)
+	defer hub.Unsubscribe(fresh)
+	require.Len(t, replay, 1)
+	assert.GreaterOrEqual(t, replay[0].ID, events[2].ID)
+}
+
+func TestHubDropsSlowSubscriber(t *testing.T) {
+	hub, _ := newTestHub(2)
+	defer hub.Close()
+
+	btc := mustParse(t, "BTC-USD")
+	slow, _ := hub.Subscribe([]models.Symbol{btc}, 0, false)
+
+	select {
+	case <-slow.Done():
+	case <-time.After(time.Second):
+		t.Fatal("slow subscriber was not dropped")
+	}
+	assert.Len(t, slow.Events(), 2, "the buffer keeps the newest events")
+
+	subscribers, pollers := hub.Stats()
+	assert.Equal(t, 0, subscribers)
+	assert.Equal(t, 0, pollers)
+}
+
+func TestHubMarketHours(t *testing.T) {
+	hub := NewHub(&tickingProvider{}, fixedClock(false), Settings{}, logrus.New(), nil)
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	require.NoError(t, err)
+
+	weekday := time.Date(2026, 10, 14, 10, 0, 0, 0, taipei) // Wednesday
+	saturday := time.Date(2026, 10, 17, 10, 0, 0, 0, taipei)
+	evening := time.Date(2026, 10, 14, 20, 0, 0, 0, taipei)
+
+	assert.False(t, hub.marketOpen(mustParse(t, "AAPL"), weekday), "US hours come from the market calendar")
+	assert.True(t, hub.marketOpen(mustParse(t, "BTC-USD"), saturday))
+	assert.True(t, hub.marketOpen(mustParse(t, "2330.TW"), weekday))
+	assert.False(t, hub.marketOpen(mustParse(t, "2330.TW"), saturday))
+	assert.False(t, hub.marketOpen(mustParse(t, "2330.TW"), evening))
+}
@@ -3,10 +3,15 @@ package yahoo
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -15,6 +20,13 @@ import (
 	"stock-prediction-us/internal/config"
 	"stock-prediction-us/internal/metrics"
 	"stock-prediction-us/internal/models"
+	"stock-prediction-us/internal/services/fixtures"
+	"stock-prediction-us/internal/services/marketdata"
+)
+
+var (
+	_ marketdata.MarketDataProvider = (*Client)(nil)
+	_ marketdata.SymbolDirectory    = (*Client)(nil)
 )
 
 // Client represents Yahoo Finance API client
@@ -24,6 +36,10 @@ type Client struct {
 	config      *config.Config
 	logger      *logrus.Logger
 	metrics     *metrics.Metrics
+	gapPolicy   models.GapPolicy
+	
+	metadataMutex sync.RWMutex
+	metadata      map[string]models.SymbolMetadata // captured from chart responses
 }
 
 // NewClient creates a new Yahoo Finance client
@@ -31,10 +47,28 @@ func NewClient(cfg *config.Config, logger *logrus.Logger, metrics *metrics.Metri
 	// Create rate limiter
 	limiter := rate.NewLimiter(rate.Limit(cfg.Stock.RequestsPerSec), 1)
 	
-	// Create HTTP client with timeout
+	// Create HTTP client with timeout, recording or replaying fixtures if configured
 	httpClient := &http.Client{
 		Timeout: cfg.API.Timeout,
 	}
+	fixtureMode, err := fixtures.ParseMode(cfg.API.FixtureMode)
+	if err != nil {
+		logger.WithError(err).Warn("Invalid fixture mode, using live requests")
+		fixtureMode = fixtures.ModeLive
+	}
+	if fixtureMode != fixtures.ModeLive {
+		httpClient.Transport = fixtures.NewTransport(fixtureMode, cfg.API.FixtureDir, nil)
+		logger.WithFields(logrus.Fields{
+			"mode": fixtureMode,
+			"dir":  cfg.API.FixtureDir,
+		}).Info("Yahoo Finance fixture transport enabled")
+	}
+	
+	gapPolicy, err := models.ParseGapPolicy(cfg.MarketData.GapPolicy)
+	if err != nil {
+		logger.WithError(err).Warn("Invalid gap policy, using forward_fill")
+		gapPolicy = models.GapPolicyForwardFill
+	}
 	
 	return &Client{
 		httpClient:  httpClient,
@@ -42,40 +76,103 @@ func NewClient(cfg *config.Config, logger *logrus.Logger, metrics *metrics.Metri
 		config:      cfg,
 		logger:      logger,
 		metrics:     metrics,
+		gapPolicy:   gapPolicy,
+		metadata:    make(map[string]models.SymbolMetadata),
 	}
 }
 
+// Name returns the provider identifier
+func (c *Client) Name() string {
+	return "yahoo"
+}
+
+// HTTPError is a non-200 response from the Yahoo Finance API
+type HTTPError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // from the Retry-After header, zero if absent
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// ClientError reports 4xx responses other than throttling, which are caused
+// by the request (e.g. unknown symbol) rather than an unhealthy upstream
+func (e *HTTPError) ClientError() bool {
+	return e.StatusCode >= 400 && e.StatusCode < 500 && e.StatusCode != http.StatusTooManyRequests
+}
+
 // FetchStockData fetches stock data with retry logic
-func (c *Client) FetchStockData(symbol string, period string) ([]float64, error) {
-	start := time.Now()
-	var lastErr error
-	
+func (c *Client) FetchStockData(ctx context.Context, symbol string, period string, interval models.Interval) ([]float64, error) {
 	// Validate symbol
 	if err := models.ValidateSymbol(symbol); err != nil {
-		c.metrics.RecordStockDataFetch(time.Since(start).Seconds(), false)
+		c.metrics.RecordStockDataFetch(0, false)
 		return nil, fmt.Errorf("invalid symbol: %w", err)
 	}
 	
+	var data []float64
+	err := c.withRetry(ctx, symbol, func(ctx context.Context) error {
+		var err error
+		data, err = c.fetchStockDataOnce(ctx, symbol, period, interval)
+		return err
+	})
+	return data, err
+}
+
+// withRetry runs fn with exponential backoff and records fetch metrics.
+// Each attempt gets its own deadline; backoff waits stop as soon as ctx is
+// done, and a Retry-After header on HTTP 429 replaces the computed backoff.
+func (c *Client) withRetry(ctx context.Context, symbol string, fn func(ctx context.Context) error) error {
+	start := time.Now()
+	var lastErr error
+	
 	// Retry logic with exponential backoff
 	for attempt := 0; attempt < c.config.Stock.MaxRetries; attempt++ {
 		if attempt > 0 {
-			// Exponential backoff
+			// Exponential backoff, unless the server asked for a specific wait
 			backoff := time.Duration(math.Pow(2, float64(attempt))) * time.Second
+			var httpErr *HTTPError
+			if errors.As(lastErr, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests && httpErr.RetryAfter > 0 {
+				backoff = httpErr.RetryAfter
+				if max := c.config.API.MaxRetryAfter; max > 0 && backoff > max {
+					backoff = max
+				}
+			}
 			c.logger.WithFields(logrus.Fields{
 				"symbol":  symbol,
 				"attempt": attempt + 1,
 				"backoff": backoff,
 			}).Warn("Retrying stock data fetch")
-			time.Sleep(backoff)
+			
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				c.metrics.RecordStockDataFetch(time.Since(start).Seconds(), false)
+				return fmt.Errorf("fetch cancelled after %d attempts: %w", attempt, ctx.Err())
+			case <-timer.C:
+			}
 		}
 		
-		data, err := c.fetchStockDataOnce(symbol, period)
+		err := c.attempt(ctx, fn)
 		if err == nil {
 			c.metrics.RecordStockDataFetch(time.Since(start).Seconds(), true)
-			return data, nil
+			return nil
 		}
 		
 		lastErr = err
+		if ctx.Err() != nil {
+			c.metrics.RecordStockDataFetch(time.Since(start).Seconds(), false)
+			return fmt.Errorf("fetch cancelled after %d attempts: %w", attempt+1, ctx.Err())
+		}
+		
+		// An unknown symbol, bad request or missing fixture will not succeed on retry
+		var clientErr interface{ ClientError() bool }
+		if errors.As(err, &clientErr) && clientErr.ClientError() {
+			c.metrics.RecordStockDataFetch(time.Since(start).Seconds(), false)
+			return fmt.Errorf("request rejected: %w", err)
+		}
 		c.logger.WithFields(logrus.Fields{
 			"symbol":  symbol,
 			"attempt": attempt + 1,
@@ -84,52 +181,65 @@ func (c *Client) FetchStockData(symbol string, period string) ([]float64, error)
 	}
 	
 	c.metrics.RecordStockDataFetch(time.Since(start).Seconds(), false)
-	return nil, fmt.Errorf("failed after %d attempts: %w", c.config.Stock.MaxRetries, lastErr)
+	return fmt.Errorf("failed after %d attempts: %w", c.config.Stock.MaxRetries, lastErr)
+}
+
+// attempt runs fn under the per-attempt deadline
+func (c *Client) attempt(ctx context.Context, fn func(ctx context.Context) error) error {
+	if c.config.API.AttemptTimeout <= 0 {
+		return fn(ctx)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, c.config.API.AttemptTimeout)
+	defer cancel()
+	return fn(attemptCtx)
 }
 
 // fetchStockDataOnce performs a single stock data fetch
-func (c *Client) fetchStockDataOnce(symbol string, period string) ([]float64, error) {
-	// Rate limiting
-	if err := c.rateLimiter.Wait(context.Background()); err != nil {
-		return nil, fmt.Errorf("rate limiter error: %w", err)
+func (c *Client) fetchStockDataOnce(ctx context.Context, symbol string, period string, interval models.Interval) ([]float64, error) {
+	result, err := c.fetchChart(ctx, symbol, period, interval)
+	if err != nil {
+		return nil, err
 	}
 	
-	// Build URL
-	url := fmt.Sprintf("%s/v8/finance/chart/%s?interval=1d&range=%s", 
-		c.config.API.BaseURL, symbol, period)
-	
-	c.logger.WithFields(logrus.Fields{
-		"symbol": symbol,
-		"url":    url,
-	}).Debug("Fetching stock data")
+	bars := c.barsFromChart(symbol, result, interval)
+	if len(bars) == 0 {
+		return nil, fmt.Errorf("no close prices in response")
+	}
+	closePrices := models.ClosePrices(bars, false)
 	
-	// Create request
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	// Validate data quality
+	if err := models.ValidateStockData(closePrices, 1); err != nil {
+		return nil, fmt.Errorf("invalid stock data: %w", err)
 	}
 	
-	// Set headers
-	req.Header.Set("User-Agent", c.config.API.UserAgent)
-	req.Header.Set("Accept", "application/json")
+	c.logger.WithFields(logrus.Fields{
+		"symbol":     symbol,
+		"interval":   interval,
+		"data_points": len(closePrices),
+		"latest_price": closePrices[len(closePrices)-1],
+	}).Info("Successfully fetched stock data")
 	
-	// Execute request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
-	}
-	defer resp.Body.Close()
+	return closePrices, nil
+}
+
+// fetchChart performs a single rate-limited chart request and returns the first result
+func (c *Client) fetchChart(ctx context.Context, symbol string, period string, interval models.Interval) (*models.YahooChartResult, error) {
+	// Build URL; symbols such as ^GSPC and EURUSD=X must be path-escaped
+	query := url.Values{}
+	query.Set("interval", string(interval))
+	query.Set("range", period)
+	query.Set("events", "div,splits")
+	chartURL := fmt.Sprintf("%s/v8/finance/chart/%s?%s",
+		c.config.API.BaseURL, url.PathEscape(symbol), query.Encode())
 	
-	// Check status code
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
-	}
+	c.logger.WithFields(logrus.Fields{
+		"symbol": symbol,
+		"url":    chartURL,
+	}).Debug("Fetching stock data")
 	
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.get(ctx, chartURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 	
 	// Parse JSON response
@@ -154,121 +264,259 @@ func (c *Client) fetchStockDataOnce(symbol string, period string) ([]float64, er
 		return nil, fmt.Errorf("no quote data in response")
 	}
 	
-	closePrices := result.Indicators.Quote[0].Close
-	if len(closePrices) == 0 {
-		return nil, fmt.Errorf("no close prices in response")
-	}
-	
-	// Validate data quality
-	if err := models.ValidateStockData(closePrices, 1); err != nil {
-		return nil, fmt.Errorf("invalid stock data: %w", err)
-	}
-	
-	c.logger.WithFields(logrus.Fields{
-		"symbol":     symbol,
-		"data_points": len(closePrices),
-		"latest_price": closePrices[len(closePrices)-1],
-	}).Info("Successfully fetched stock data")
+	// Every chart carries instrument metadata; keep it for symbol lookups
+	c.rememberMetadata(symbol, &result)
 	
-	return closePrices, nil
+	return &result, nil
 }
 
-// FetchLatestPrice fetches the latest stock price
-func (c *Client) FetchLatestPrice(symbol string) (float64, error) {
-	data, err := c.FetchStockData(symbol, "1d")
-	if err != nil {
-		return 0, err
-	}
-	
-	if len(data) == 0 {
-		return 0, fmt.Errorf("no price data available")
+// get performs a single rate-limited GET and returns the body of a 200 response
+func (c *Client) get(ctx context.Context, requestURL string) ([]byte, error) {
+	// Rate limiting
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limiter error: %w", err)
 	}
 	
-	return data[len(data)-1], nil
-}
-
-// FetchHistoricalData fetches historical data for the specified number of days
-func (c *Client) FetchHistoricalData(symbol string, days int) ([]models.StockData, error) {
-	var period string
-	switch {
-	case days <= 7:
-		period = "7d"
-	case days <= 30:
-		period = "1mo"
-	case days <= 90:
-		period = "3mo"
-	case days <= 180:
-		period = "6mo"
-	case days <= 365:
-		period = "1y"
-	default:
-		period = "2y"
-	}
-	
-	// Build URL for detailed data
-	url := fmt.Sprintf("%s/v8/finance/chart/%s?interval=1d&range=%s", 
-		c.config.API.BaseURL, symbol, period)
-	
-	req, err := http.NewRequest("GET", url, nil)
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	
+	// Set headers
 	req.Header.Set("User-Agent", c.config.API.UserAgent)
 	req.Header.Set("Accept", "application/json")
 	
+	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 	
+	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &HTTPError{
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
 	}
 	
+	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 	
-	var response models.YahooFinanceResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	return body, nil
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// FetchLatestPrice fetches the latest stock price
+func (c *Client) FetchLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	data, err := c.FetchStockData(ctx, symbol, "1d", models.Interval1d)
+	if err != nil {
+		return 0, err
 	}
 	
-	if len(response.Chart.Result) == 0 {
-		return nil, fmt.Errorf("no data in response")
+	if len(data) == 0 {
+		return 0, fmt.Errorf("no price data available")
 	}
 	
-	result := response.Chart.Result[0]
-	timestamps := result.Timestamp
+	return data[len(data)-1], nil
+}
+
+// FetchHistoricalData fetches bars covering the last N trading days.
+// Daily series return the last N bars; intraday series return every bar
+// from the last N sessions.
+func (c *Client) FetchHistoricalData(ctx context.Context, symbol string, days int, interval models.Interval) ([]models.StockData, error) {
+	if err := models.ValidateSymbol(symbol); err != nil {
+		return nil, fmt.Errorf("invalid symbol: %w", err)
+	}
+	
+	var result *models.YahooChartResult
+	err := c.withRetry(ctx, symbol, func(ctx context.Context) error {
+		var err error
+		result, err = c.fetchChart(ctx, symbol, interval.RangeForTradingDays(days), interval)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	stockData := c.barsFromChart(symbol, result, interval)
+	
+	// Limit to requested number of days
+	if !interval.IsIntraday() {
+		if len(stockData) > days {
+			stockData = stockData[len(stockData)-days:]
+		}
+		return stockData, nil
+	}
+	
+	return lastSessions(stockData, days, result.Meta.GmtOffset), nil
+}
+
+// barsFromChart converts a chart result into bars, resolving null closes
+// with the configured gap policy. Arrays shorter than the timestamps are
+// treated as trailing nulls.
+func (c *Client) barsFromChart(symbol string, result *models.YahooChartResult, interval models.Interval) []models.StockData {
 	quotes := result.Indicators.Quote[0]
 	
-	stockData := make([]models.StockData, len(timestamps))
-	for i, timestamp := range timestamps {
-		stockData[i] = models.StockData{
+	// Adjusted closes are only published for daily and longer bars
+	var adjCloses []*float64
+	if len(result.Indicators.AdjClose) > 0 {
+		adjCloses = result.Indicators.AdjClose[0].AdjClose
+	}
+	
+	// LSE listings report GBp; the ticker grammar covers feeds without meta
+	currency := result.Meta.Currency
+	if currency == "" {
+		currency = models.CurrencyOf(symbol)
+	}
+	
+	bars := make([]models.StockData, len(result.Timestamp))
+	missing := make([]bool, len(result.Timestamp))
+	gaps := 0
+	for i, timestamp := range result.Timestamp {
+		bars[i] = models.StockData{
 			Symbol:    symbol,
 			Timestamp: time.Unix(timestamp, 0),
-			Open:      quotes.Open[i],
-			High:      quotes.High[i],
-			Low:       quotes.Low[i],
-			Close:     quotes.Close[i],
-			Volume:    quotes.Volume[i],
+			Open:      floatAt(quotes.Open, i),
+			High:      floatAt(quotes.High, i),
+			Low:       floatAt(quotes.Low, i),
+			Close:     floatAt(quotes.Close, i),
+			AdjClose:  floatAt(adjCloses, i),
+			Volume:    intAt(quotes.Volume, i),
+			Interval:  interval,
+			Currency:  currency,
+		}
+		if bars[i].Close <= 0 || math.IsNaN(bars[i].Close) {
+			missing[i] = true
+			gaps++
 		}
 	}
 	
-	// Limit to requested number of days
-	if len(stockData) > days {
-		stockData = stockData[len(stockData)-days:]
+	if gaps == 0 {
+		return bars
+	}
+	
+	cleaned := models.ApplyGapPolicy(bars, missing, c.gapPolicy)
+	c.logger.WithFields(logrus.Fields{
+		"symbol":     symbol,
+		"interval":   interval,
+		"null_bars":  gaps,
+		"gap_policy": c.gapPolicy,
+		"bars":       len(cleaned),
+	}).Warn("Resolved null bars in chart response")
+	
+	return cleaned
+}
+
+// floatAt returns values[i], or 0 when null or out of range
+func floatAt(values []*float64, i int) float64 {
+	if i >= len(values) || values[i] == nil {
+		return 0
+	}
+	return *values[i]
+}
+
+// intAt returns values[i], or 0 when null or out of range
+func intAt(values []*int64, i int) int64 {
+	if i >= len(values) || values[i] == nil {
+		return 0
+	}
+	return *values[i]
+}
+
+// FetchCorporateActions fetches splits and dividends with an ex-date in the last N days
+func (c *Client) FetchCorporateActions(ctx context.Context, symbol string, days int) ([]models.CorporateAction, error) {
+	if err := models.ValidateSymbol(symbol); err != nil {
+		return nil, fmt.Errorf("invalid symbol: %w", err)
+	}
+	
+	var result *models.YahooChartResult
+	err := c.withRetry(ctx, symbol, func(ctx context.Context) error {
+		var err error
+		result, err = c.fetchChart(ctx, symbol, models.Interval1d.RangeForTradingDays(days), models.Interval1d)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	var actions []models.CorporateAction
+	for _, split := range result.Events.Splits {
+		actions = append(actions, models.CorporateAction{
+			Symbol:      symbol,
+			Date:        exDate(split.Date, result.Meta.GmtOffset),
+			Type:        models.ActionSplit,
+			Numerator:   split.Numerator,
+			Denominator: split.Denominator,
+		})
+	}
+	for _, dividend := range result.Events.Dividends {
+		actions = append(actions, models.CorporateAction{
+			Symbol: symbol,
+			Date:   exDate(dividend.Date, result.Meta.GmtOffset),
+			Type:   models.ActionDividend,
+			Amount: dividend.Amount,
+		})
+	}
+	
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].Date.Before(actions[j].Date)
+	})
+	
+	return actions, nil
+}
+
+// exDate converts an event timestamp to its exchange-local calendar date
+func exDate(timestamp, gmtOffset int64) time.Time {
+	local := time.Unix(timestamp+gmtOffset, 0).UTC()
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// lastSessions keeps intraday bars belonging to the last N exchange-local dates
+func lastSessions(bars []models.StockData, sessions int, gmtOffset int64) []models.StockData {
+	sessionDate := func(t time.Time) string {
+		return t.UTC().Add(time.Duration(gmtOffset) * time.Second).Format("2006-01-02")
+	}
+	
+	seen := 0
+	current := ""
+	for i := len(bars) - 1; i >= 0; i-- {
+		date := sessionDate(bars[i].Timestamp)
+		if date != current {
+			current = date
+			seen++
+			if seen > sessions {
+				return bars[i+1:]
+			}
+		}
 	}
 	
-	return stockData, nil
+	return bars
 }
 
 // HealthCheck checks if the Yahoo Finance API is accessible
-func (c *Client) HealthCheck() error {
+func (c *Client) HealthCheck(ctx context.Context) error {
 	// Try to fetch a simple quote for a well-known symbol
-	_, err := c.FetchLatestPrice("AAPL")
+	_, err := c.FetchLatestPrice(ctx, "AAPL")
 	return err
 }
@@ -0,0 +1,186 @@
+package yahoo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-prediction-us/internal/config"
+	"stock-prediction-us/internal/metrics"
+	"stock-prediction-us/internal/models"
+	"stock-prediction-us/internal/services/fixtures"
+	"stock-prediction-us/internal/services/marketdata"
+)
+
+// testMetrics is shared because metrics register with the global registry
+var testMetrics = metrics.NewMetrics()
+
+const chartFixture = `{"chart":{"result":[{"meta":{"symbol":"NVDA","gmtoffset":-14400},
+"timestamp":[1717421400,1717507800,1717594200],
+"indicators":{"quote":[{"open":[110,null,112],"high":[111,null,113],"low":[109,null,111],
+"close":[110.5,null,112.5],"volume":[1000,null,1200]}]}}],"error":null}}`
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := &config.Config{}
+	cfg.API.BaseURL = server.URL
+	cfg.API.Timeout = 5 * time.Second
+	cfg.API.AttemptTimeout = time.Second
+	cfg.API.MaxRetryAfter = 2 * time.Second
+	cfg.Stock.MaxRetries = 3
+	cfg.Stock.RequestsPerSec = 100
+	cfg.MarketData.GapPolicy = "forward_fill"
+
+	return NewClient(cfg, logrus.New(), testMetrics)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3", now))
+	assert.Equal(t, 90*time.Second, parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+}
+
+func TestFetchHistoricalDataFillsNullBars(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(chartFixture))
+	})
+
+	bars, err := client.FetchHistoricalData(context.Background(), "NVDA", 5, models.Interval1d)
+	require.NoError(t, err)
+	require.Len(t, bars, 3)
+	assert.True(t, bars[1].Filled)
+	assert.Equal(t, []float64{110.5, 110.5, 112.5}, models.ClosePrices(bars, false))
+}
+
+func TestFetchHonoursRetryAfter(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(chartFixture))
+	})
+
+	start := time.Now()
+	closes, err := client.FetchStockData(context.Background(), "NVDA", "7d", models.Interval1d)
+	require.NoError(t, err)
+	assert.Len(t, closes, 3)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// Retry-After of 1s replaces the 2s exponential backoff
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, time.Second)
+	assert.Less(t, elapsed, 2*time.Second)
+}
+
+func TestFetchCancelledDuringBackoff(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.FetchStockData(ctx, "NVDA", "7d", models.Interval1d)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestFetchEscapesSymbolInPath(t *testing.T) {
+	var path, rawQuery string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		rawQuery = r.URL.RawQuery
+		w.Write([]byte(chartFixture))
+	})
+
+	_, err := client.FetchStockData(context.Background(), "^GSPC", "7d", models.Interval1d)
+	require.NoError(t, err)
+	assert.Equal(t, "/v8/finance/chart/%5EGSPC", path)
+	assert.Contains(t, rawQuery, "range=7d")
+}
+
+func TestSymbolMetadataAndSearch(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/finance/search":
+			assert.Equal(t, "tsmc", r.URL.Query().Get("q"))
+			w.Write([]byte(`{"quotes":[
+				{"symbol":"2330.TW","shortname":"TAIWAN SEMICONDUCTOR MANUFACTUR","longname":"Taiwan Semiconductor Manufacturing Company Limited","exchDisp":"Taiwan","quoteType":"EQUITY"},
+				{"symbol":"TSFA.F","shortname":"TSMC","exchDisp":"Frankfurt","quoteType":"EQUITY"},
+				{"symbol":"TSM","shortname":"Taiwan Semiconductor Manufactur","exchDisp":"NYSE","quoteType":"EQUITY"}]}`))
+		case r.URL.Path == "/v8/finance/chart/ZZZZ":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.Write([]byte(`{"chart":{"result":[{"meta":{"symbol":"NVDA","currency":"USD","fullExchangeName":"NasdaqGS",
+"instrumentType":"EQUITY","firstTradeDate":917015400,"exchangeTimezoneName":"America/New_York","longName":"NVIDIA Corporation"},
+"timestamp":[1717421400],"indicators":{"quote":[{"open":[110],"high":[111],"low":[109],"close":[110.5],"volume":[1000]}]}}],"error":null}}`))
+		}
+	})
+	ctx := context.Background()
+
+	metadata, err := client.FetchSymbolMetadata(ctx, "NVDA")
+	require.NoError(t, err)
+	assert.Equal(t, "NVIDIA Corporation", metadata.LongName)
+	assert.Equal(t, "NasdaqGS", metadata.Exchange)
+	assert.Equal(t, "US", metadata.ExchangeCode)
+	require.NotNil(t, metadata.FirstTradeDate)
+	assert.Equal(t, 1999, metadata.FirstTradeDate.Year())
+
+	_, err = client.FetchSymbolMetadata(ctx, "ZZZZ")
+	assert.ErrorIs(t, err, marketdata.ErrSymbolNotFound)
+
+	// Listings on exchanges the service cannot parse are skipped
+	results, err := client.SearchSymbols(ctx, "tsmc", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "2330.TW", results[0].Symbol)
+	assert.Equal(t, "TWD", results[0].Currency)
+	assert.Equal(t, "TSM", results[1].Symbol)
+}
+
+func TestReplayFixturesOffline(t *testing.T) {
+	dir := t.TempDir()
+
+	// Record against a live server
+	recorder := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(chartFixture))
+	})
+	recorder.config.API.FixtureMode = "record"
+	recorder.config.API.FixtureDir = dir
+	recorder = NewClient(recorder.config, logrus.New(), testMetrics)
+
+	recorded, err := recorder.FetchHistoricalData(context.Background(), "NVDA", 5, models.Interval1d)
+	require.NoError(t, err)
+
+	// Replay with no server at all
+	cfg := *recorder.config
+	cfg.API.BaseURL = "http://127.0.0.1:1"
+	cfg.API.FixtureMode = "replay"
+	replayer := NewClient(&cfg, logrus.New(), testMetrics)
+
+	replayed, err := replayer.FetchHistoricalData(context.Background(), "NVDA", 5, models.Interval1d)
+	require.NoError(t, err)
+	assert.Equal(t, recorded, replayed)
+
+	// A miss fails fast instead of retrying
+	start := time.Now()
+	_, err = replayer.FetchStockData(context.Background(), "TSLA", "1mo", models.Interval1d)
+	assert.ErrorIs(t, err, fixtures.ErrNoFixture)
+	assert.Less(t, time.Since(start), time.Second)
+}
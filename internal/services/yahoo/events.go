@@ -0,0 +1,101 @@
+package yahoo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"stock-prediction-us/internal/models"
+	"stock-prediction-us/internal/services/marketdata"
+)
+
+var _ marketdata.EventCalendar = (*Client)(nil)
+
+// FetchUpcomingEvents reads the next earnings date and ex-dividend date from
+// the quoteSummary calendarEvents module. When Yahoo only has an estimated
+// earnings window the start of the window is used.
+func (c *Client) FetchUpcomingEvents(ctx context.Context, symbol string) ([]models.CorporateEvent, error) {
+	if err := models.ValidateSymbol(symbol); err != nil {
+		return nil, fmt.Errorf("invalid symbol: %w", err)
+	}
+
+	summaryURL := fmt.Sprintf("%s/v10/finance/quoteSummary/%s?modules=calendarEvents",
+		c.config.API.BaseURL, url.PathEscape(symbol))
+
+	var response models.YahooQuoteSummaryResponse
+	err := c.withRetry(ctx, symbol, func(ctx context.Context) error {
+		body, err := c.get(ctx, summaryURL)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(body, &response); err != nil {
+			return fmt.Errorf("failed to parse quote summary response: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if response.QuoteSummary.Error != nil {
+		return nil, fmt.Errorf("API error: %s - %s",
+			response.QuoteSummary.Error.Code, response.QuoteSummary.Error.Description)
+	}
+	if len(response.QuoteSummary.Result) == 0 {
+		return []models.CorporateEvent{}, nil
+	}
+
+	calendar := response.QuoteSummary.Result[0].CalendarEvents
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	events := []models.CorporateEvent{}
+	if dates := calendar.Earnings.EarningsDate; len(dates) > 0 {
+		event := models.CorporateEvent{
+			Symbol:      symbol,
+			Date:        summaryDate(dates[0]),
+			Type:        models.EventEarnings,
+			Description: "Earnings release",
+			Source:      c.Name(),
+			UpdatedAt:   now,
+		}
+		if calendar.Earnings.IsEarningsDateEstimate || len(dates) > 1 {
+			event.Description = fmt.Sprintf("Estimated earnings window to %s", summaryDate(dates[len(dates)-1]).Format("2006-01-02"))
+		}
+		events = append(events, event)
+	}
+	if calendar.ExDividendDate != nil && calendar.ExDividendDate.Raw > 0 {
+		events = append(events, models.CorporateEvent{
+			Symbol:      symbol,
+			Date:        summaryDate(*calendar.ExDividendDate),
+			Type:        models.EventExDividend,
+			Description: "Ex-dividend date",
+			Source:      c.Name(),
+			UpdatedAt:   now,
+		})
+	}
+
+	// Yahoo keeps the last ex-dividend date until the next one is announced
+	upcoming := events[:0]
+	for _, event := range events {
+		if !event.Date.Before(today) {
+			upcoming = append(upcoming, event)
+		}
+	}
+	sort.Slice(upcoming, func(i, j int) bool {
+		return upcoming[i].Date.Before(upcoming[j].Date)
+	})
+
+	return upcoming, nil
+}
+
+// summaryDate prefers the formatted exchange-local date over the timestamp
+func summaryDate(value models.YahooDateValue) time.Time {
+	if date, err := time.Parse("2006-01-02", value.Fmt); err == nil {
+		return date
+	}
+	return exDate(value.Raw, 0)
+}
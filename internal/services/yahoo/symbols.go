@@ -0,0 +1,140 @@
+package yahoo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"stock-prediction-us/internal/models"
+	"stock-prediction-us/internal/services/marketdata"
+)
+
+// FetchSymbolMetadata returns metadata captured from the last chart response
+// for the symbol, fetching a short chart when none has been seen yet
+func (c *Client) FetchSymbolMetadata(ctx context.Context, symbol string) (*models.SymbolMetadata, error) {
+	if err := models.ValidateSymbol(symbol); err != nil {
+		return nil, fmt.Errorf("invalid symbol: %w", err)
+	}
+	
+	if metadata, ok := c.cachedMetadata(symbol); ok {
+		return &metadata, nil
+	}
+	
+	err := c.withRetry(ctx, symbol, func(ctx context.Context) error {
+		_, err := c.fetchChart(ctx, symbol, "5d", models.Interval1d)
+		return err
+	})
+	if err != nil {
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", marketdata.ErrSymbolNotFound, symbol)
+		}
+		return nil, err
+	}
+	
+	metadata, ok := c.cachedMetadata(symbol)
+	if !ok {
+		return nil, fmt.Errorf("no metadata in chart response for %s", symbol)
+	}
+	return &metadata, nil
+}
+
+// SearchSymbols queries the Yahoo Finance search endpoint. Results whose
+// ticker the service cannot parse (e.g. unsupported exchanges) are skipped.
+func (c *Client) SearchSymbols(ctx context.Context, query string, limit int) ([]models.SymbolMetadata, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("quotesCount", strconv.Itoa(limit))
+	params.Set("newsCount", "0")
+	searchURL := fmt.Sprintf("%s/v1/finance/search?%s", c.config.API.BaseURL, params.Encode())
+	
+	var response models.YahooSearchResponse
+	err := c.withRetry(ctx, query, func(ctx context.Context) error {
+		body, err := c.get(ctx, searchURL)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(body, &response); err != nil {
+			return fmt.Errorf("failed to parse search response: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	results := make([]models.SymbolMetadata, 0, len(response.Quotes))
+	for _, quote := range response.Quotes {
+		symbol, err := models.ParseSymbol(quote.Symbol)
+		if err == nil && symbol.Ticker != quote.Symbol {
+			err = fmt.Errorf("ticker would be rewritten to %s", symbol.Ticker)
+		}
+		if err != nil {
+			c.logger.WithFields(logrus.Fields{
+				"symbol": quote.Symbol,
+				"error":  err,
+			}).Debug("Skipping unsupported search result")
+			continue
+		}
+		
+		metadata := models.NewSymbolMetadata(symbol, c.Name())
+		metadata.LongName = quote.LongName
+		metadata.ShortName = quote.ShortName
+		metadata.Exchange = quote.ExchDisp
+		metadata.InstrumentType = quote.QuoteType
+		results = append(results, metadata)
+		
+		if len(results) == limit {
+			break
+		}
+	}
+	
+	return results, nil
+}
+
+// rememberMetadata caches the instrument metadata of a chart response
+func (c *Client) rememberMetadata(symbol string, result *models.YahooChartResult) {
+	parsed, err := models.ParseSymbol(symbol)
+	if err != nil {
+		return
+	}
+	
+	meta := result.Meta
+	metadata := models.NewSymbolMetadata(parsed, c.Name())
+	metadata.LongName = meta.LongName
+	metadata.ShortName = meta.ShortName
+	metadata.Exchange = meta.FullExchangeName
+	if metadata.Exchange == "" {
+		metadata.Exchange = meta.ExchangeName
+	}
+	if meta.Currency != "" {
+		metadata.Currency = meta.Currency
+	}
+	if meta.ExchangeTimezoneName != "" {
+		metadata.Timezone = meta.ExchangeTimezoneName
+	}
+	metadata.InstrumentType = meta.InstrumentType
+	if meta.FirstTradeDate != nil {
+		firstTrade := time.Unix(*meta.FirstTradeDate, 0).UTC()
+		metadata.FirstTradeDate = &firstTrade
+	}
+	
+	c.metadataMutex.Lock()
+	c.metadata[parsed.Ticker] = metadata
+	c.metadataMutex.Unlock()
+}
+
+// cachedMetadata returns previously captured metadata for a symbol
+func (c *Client) cachedMetadata(symbol string) (models.SymbolMetadata, bool) {
+	c.metadataMutex.RLock()
+	defer c.metadataMutex.RUnlock()
+	metadata, ok := c.metadata[symbol]
+	return metadata, ok
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
@@ -22,17 +23,30 @@ import (
 	"stock-prediction-us/internal/metrics"
 	"stock-prediction-us/internal/services"
 	"stock-prediction-us/internal/services/cache"
+	"stock-prediction-us/internal/services/events"
+	"stock-prediction-us/internal/services/marketdata"
 	"stock-prediction-us/internal/services/prediction"
+	"stock-prediction-us/internal/services/stream"
 	"stock-prediction-us/internal/services/yahoo"
 )
 
 func main() {
+	mode := flag.String("mode", "", "service mode: live or sandbox (overrides SERVICE_MODE)")
+	flag.Parse()
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
 		fmt.Printf("Failed to load configuration: %v\n", err)
 		os.Exit(1)
 	}
+	if *mode != "" {
+		cfg.Mode = *mode
+	}
+	if cfg.Mode != "live" && !cfg.IsSandbox() {
+		fmt.Printf("Invalid service mode: %s (valid options: live, sandbox)\n", cfg.Mode)
+		os.Exit(1)
+	}
 
 	// Setup logger
 	logger := setupLogger(cfg)
@@ -59,14 +73,45 @@ func main() {
 	logger.WithField("db_path", dbPath).Info("Database initialized successfully")
 
 	// Initialize services
-	yahooClient := yahoo.NewClient(cfg, logger, metricsCollector)
+	var marketDataProvider marketdata.MarketDataProvider
+	if cfg.IsSandbox() {
+		// Synthetic bars are cheap to regenerate and must not reach the bar store
+		logger.Warn("Sandbox mode: serving synthetic market data, all responses are marked synthetic")
+		marketDataProvider = marketdata.NewSyntheticProvider(marketdata.SyntheticSettings{
+			Drift:         cfg.Sandbox.Drift,
+			Volatility:    cfg.Sandbox.Volatility,
+			JumpIntensity: cfg.Sandbox.JumpIntensity,
+			JumpSize:      cfg.Sandbox.JumpSize,
+			BaseVolume:    cfg.Sandbox.BaseVolume,
+			Seed:          cfg.Sandbox.Seed,
+		})
+	} else {
+		marketDataProvider = buildMarketDataProvider(cfg, logger, metricsCollector)
+	}
+	if cfg.MarketData.StoreEnabled && !cfg.IsSandbox() {
+		marketDataProvider = marketdata.NewBarStore(db.GetDB(), marketDataProvider, logger,
+			cfg.MarketData.SyncInterval, cfg.MarketData.BackfillDays)
+	}
+	batchFetcher := marketdata.NewBatchFetcher(marketDataProvider, cfg.MarketData.BatchWorkers, logger)
+	fxRates := marketdata.NewFXRates(marketDataProvider, logger, cfg.MarketData.FXRateTTL)
 	predictionCache := cache.NewPredictionCache(cfg.ML.PredictionTTL, metricsCollector)
-	predictionService := prediction.NewService(cfg, logger, metricsCollector, predictionCache)
+	predictionService := prediction.NewEnhancedPredictionService(cfg, logger, metricsCollector, predictionCache)
+	defer predictionService.Close()
 
 	// Initialize new prediction tracking services
 	marketCalendarService := services.NewMarketCalendarService(db.GetDB())
-	predictionTrackerService := services.NewPredictionTrackerService(db.GetDB(), marketCalendarService, predictionService)
-	accuracyCalculatorService := services.NewAccuracyCalculatorService(db.GetDB())
+	eventCalendar := events.NewCalendar(db.GetDB(), marketDataProvider, marketCalendarService, events.Settings{
+		PostEventDays:    cfg.Events.PostEventDays,
+		DownWeight:       cfg.Events.DownWeight,
+		ConfidenceFactor: cfg.Events.ConfidenceFactor,
+		RefreshInterval:  cfg.Events.RefreshInterval,
+	}, logger)
+	predictionTrackerService := services.NewPredictionTrackerService(db.GetDB(), marketCalendarService, predictionService, marketDataProvider, batchFetcher, fxRates, eventCalendar)
+	accuracyCalculatorService := services.NewAccuracyCalculatorService(db.GetDB(), fxRates)
+	predictionService.SetResidualSource(accuracyCalculatorService)
+	predictionService.SetMemberAccuracySource(accuracyCalculatorService)
+	modelRegistryService := services.NewModelRegistryService(db.GetDB(), cfg.ML.ModelRoot)
+	predictionService.SetVersionSource(modelRegistryService)
 
 	// Initialize market calendar for current year
 	if err := marketCalendarService.InitializeCurrentYear(); err != nil {
@@ -74,11 +119,22 @@ func main() {
 	}
 
 	// Initialize handlers
-	handler := handlers.NewHandler(cfg, logger, metricsCollector, yahooClient, predictionService)
+	handler := handlers.NewHandler(cfg, logger, metricsCollector, marketDataProvider, batchFetcher, fxRates, eventCalendar, predictionService)
 	predictionTrackingHandler := handlers.NewPredictionTrackingHandler(predictionTrackerService, accuracyCalculatorService)
+	eventsHandler := handlers.NewEventsHandler(cfg, logger, eventCalendar, handler.AdminMiddleware)
+	modelRegistryHandler := handlers.NewModelRegistryHandler(logger, modelRegistryService, predictionService, handler.AdminMiddleware)
+
+	// Quote stream: one poller per symbol shared by all subscribers
+	quoteHub := stream.NewHub(marketDataProvider, marketCalendarService, stream.Settings{
+		OpenInterval:   cfg.Stream.OpenInterval,
+		ClosedInterval: cfg.Stream.ClosedInterval,
+		BufferSize:     cfg.Stream.BufferSize,
+		History:        cfg.Stream.History,
+	}, logger, metricsCollector)
+	streamHandler := handlers.NewStreamHandler(cfg, logger, quoteHub)
 
 	// Setup router
-	router := setupRouter(handler, predictionTrackingHandler)
+	router := setupRouter(cfg, handler, predictionTrackingHandler, streamHandler, eventsHandler, modelRegistryHandler)
 
 	// Create HTTP server
 	server := &http.Server{
@@ -87,6 +143,8 @@ func main() {
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 	}
+	// Open streams never go idle; end them so Shutdown can complete
+	server.RegisterOnShutdown(quoteHub.Close)
 
 	// Start system monitoring
 	go startSystemMonitoring(metricsCollector, logger)
@@ -128,6 +186,46 @@ func getDBPath() string {
 	return "./database/predictions.db"
 }
 
+// buildMarketDataProvider assembles the configured providers into a failover chain
+func buildMarketDataProvider(cfg *config.Config, logger *logrus.Logger, metrics *metrics.Metrics) marketdata.MarketDataProvider {
+	var providers []marketdata.MarketDataProvider
+	for _, name := range cfg.MarketData.Providers {
+		switch name {
+		case "yahoo":
+			providers = append(providers, newYahooProvider(cfg, logger, metrics))
+		case "csv":
+			providers = append(providers, marketdata.NewCSVProvider(cfg.MarketData.CSVDir, logger))
+		default:
+			logger.WithField("provider", name).Warn("Unknown market data provider, ignoring")
+		}
+	}
+
+	if len(providers) == 0 {
+		logger.Warn("No valid market data providers configured, falling back to Yahoo Finance")
+		return newYahooProvider(cfg, logger, metrics)
+	}
+
+	if len(providers) == 1 {
+		return providers[0]
+	}
+
+	return marketdata.NewChainProvider(logger, providers...)
+}
+
+// newYahooProvider creates the Yahoo Finance client, behind a circuit breaker when enabled
+func newYahooProvider(cfg *config.Config, logger *logrus.Logger, metrics *metrics.Metrics) marketdata.MarketDataProvider {
+	client := yahoo.NewClient(cfg, logger, metrics)
+	if !cfg.MarketData.BreakerEnabled {
+		return client
+	}
+
+	return marketdata.NewCircuitBreaker(client, marketdata.BreakerSettings{
+		FailureThreshold: cfg.MarketData.BreakerFailureThreshold,
+		OpenTimeout:      cfg.MarketData.BreakerOpenTimeout,
+		HalfOpenRequests: cfg.MarketData.BreakerHalfOpenRequests,
+	}, logger, metrics)
+}
+
 func setupLogger(cfg *config.Config) *logrus.Logger {
 	logger := logrus.New()
 
@@ -153,12 +251,15 @@ func setupLogger(cfg *config.Config) *logrus.Logger {
 	return logger
 }
 
-func setupRouter(handler *handlers.Handler, predictionTrackingHandler *handlers.PredictionTrackingHandler) *mux.Router {
+func setupRouter(cfg *config.Config, handler *handlers.Handler, predictionTrackingHandler *handlers.PredictionTrackingHandler, streamHandler *handlers.StreamHandler, eventsHandler *handlers.EventsHandler, modelRegistryHandler *handlers.ModelRegistryHandler) *mux.Router {
 	router := mux.NewRouter()
 
 	// Add middleware
 	router.Use(handler.LoggingMiddleware)
 	router.Use(handler.CORSMiddleware)
+	if cfg.IsSandbox() {
+		router.Use(handler.SandboxMiddleware)
+	}
 
 	// Add catch-all OPTIONS handler for CORS preflight
 	router.PathPrefix("/").HandlerFunc(handler.OptionsHandler).Methods("OPTIONS")
@@ -169,14 +270,22 @@ func setupRouter(handler *handlers.Handler, predictionTrackingHandler *handlers.
 	// Original prediction endpoints
 	api.HandleFunc("/predict/{symbol}", handler.PredictHandler).Methods("GET", "OPTIONS")
 	api.HandleFunc("/historical/{symbol}", handler.HistoricalDataHandler).Methods("GET", "OPTIONS")
+	api.HandleFunc("/quotes", handler.QuotesHandler).Methods("GET", "OPTIONS")
+	api.HandleFunc("/symbols/search", handler.SymbolSearchHandler).Methods("GET", "OPTIONS")
+	api.HandleFunc("/symbols/{symbol}", handler.SymbolHandler).Methods("GET", "OPTIONS")
 	
 	// Management endpoints
 	api.HandleFunc("/health", handler.HealthHandler).Methods("GET", "OPTIONS")
 	api.HandleFunc("/stats", handler.StatsHandler).Methods("GET", "OPTIONS")
 	api.HandleFunc("/cache/clear", handler.ClearCacheHandler).Methods("POST", "OPTIONS")
+	api.HandleFunc("/models", handler.ModelsHandler).Methods("GET", "OPTIONS")
+	api.Handle("/admin/model", handler.AdminMiddleware(http.HandlerFunc(handler.SwitchModelHandler))).Methods("PUT", "OPTIONS")
 
 	// Register new prediction tracking routes
 	predictionTrackingHandler.RegisterRoutes(router)
+	streamHandler.RegisterRoutes(router)
+	eventsHandler.RegisterRoutes(router)
+	modelRegistryHandler.RegisterRoutes(router)
 
 	// Metrics endpoint for Prometheus
 	router.Handle("/metrics", promhttp.Handler())
@@ -187,18 +296,43 @@ func setupRouter(handler *handlers.Handler, predictionTrackingHandler *handlers.
 			"service": "Stock Prediction API",
 			"version": "v3.4.0",
 			"status":  "running",
+			"mode":    cfg.Mode,
 			"time":    time.Now().Format(time.RFC3339),
 			"features": []string{
 				"Real-time predictions",
+				"Per-request model selection",
+				"Multi-horizon forecasts",
+				"Prediction intervals",
+				"Accuracy-weighted ensemble",
+				"Model registry with promotion and rollback",
 				"Historical data",
+				"Streaming quotes",
+				"Corporate event calendar",
 				"Daily prediction tracking",
 				"Accuracy analysis",
 				"Performance metrics",
 			},
 			"endpoints": map[string]interface{}{
 				"predictions": map[string]string{
-					"predict":     "/api/v1/predict/{symbol}",
+					"predict":     "/api/v1/predict/{symbol}?model=enhanced&horizons=1,5,20",
+					"models":      "/api/v1/models",
 					"historical":  "/api/v1/historical/{symbol}",
+					"quotes":      "/api/v1/quotes?symbols=AAPL,MSFT&currency=USD",
+					"stream":      "/api/v1/stream/quotes?symbols=AAPL,MSFT",
+				},
+				"symbols": map[string]string{
+					"lookup": "/api/v1/symbols/{symbol}",
+					"search": "/api/v1/symbols/search?q=nvidia",
+				},
+				"events": map[string]string{
+					"upcoming": "/api/v1/events/{symbol}?days=90",
+					"import":   "/api/v1/events/import?format=csv",
+				},
+				"model_registry": map[string]string{
+					"versions": "/api/v1/models/registry?name=enhanced",
+					"register": "/api/v1/models/registry",
+					"promote":  "/api/v1/models/registry/{name}/{version}/promote",
+					"rollback": "/api/v1/models/registry/{name}/rollback",
 				},
 				"tracking": map[string]string{
 					"daily_run":        "/api/v1/predictions/daily-run",
@@ -211,6 +345,7 @@ func setupRouter(handler *handlers.Handler, predictionTrackingHandler *handlers.
 					"health":      "/api/v1/health",
 					"stats":       "/api/v1/stats",
 					"clear_cache": "/api/v1/cache/clear",
+					"model":       "/api/v1/admin/model",
 					"metrics":     "/metrics",
 				},
 			},
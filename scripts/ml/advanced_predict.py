@@ -10,6 +10,10 @@ import math
 import statistics
 from typing import List, Dict, Tuple, Optional, Union
 
+from model_output import Timer, emit, flatten_features, normal_quantiles
+
+MODEL_VERSION = "advanced-1.0.0"
+
 class OHLCVData:
     """Represents OHLCV (Open, High, Low, Close, Volume) data point."""
     
@@ -398,11 +402,18 @@ class AdvancedPredictor:
         return list(set(support_levels))  # Remove duplicates
 
 def parse_input(input_str: str) -> List[OHLCVData]:
-    """Parse input - either simple prices or JSON OHLCV data."""
+    """Parse input - simple prices, a JSON list of OHLCV bars, or the
+    structured model input object sent by the Go service:
+    {"version": 1, "symbol": {...}, "interval": "1d", "adjusted": true,
+     "bars": [{"timestamp", "open", "high", "low", "close", "adj_close", "volume"}]}
+    When "adjusted" is set the bars are already on the adjusted close basis."""
     try:
         # Try to parse as JSON first (advanced mode)
         json_data = json.loads(input_str)
         
+        if isinstance(json_data, dict):
+            json_data = json_data.get('bars', [])
+        
         if isinstance(json_data, list) and len(json_data) > 0:
             if isinstance(json_data[0], dict):
                 # OHLCV JSON format
@@ -444,14 +455,32 @@ def main():
                 sys.exit(1)
         
         # Create predictor and make prediction
+        timer = Timer()
         predictor = AdvancedPredictor()
         result = predictor.ensemble_ohlcv_prediction(ohlcv_data)
-        
-        # Output just the prediction (for compatibility with existing Go service)
-        print(f"{result['prediction']:.2f}")
-        
-        # Optionally output detailed results to stderr for debugging
-        # print(f"DEBUG: {json.dumps(result, indent=2)}", file=sys.stderr)
+        timer.mark('predict')
+        
+        factors = result.get('confidence_factors', {})
+        warnings = []
+        if result['method'] == 'fallback':
+            warnings.append("insufficient data, returned fallback prediction")
+        if not factors.get('volume_available', True):
+            warnings.append("no volume data, volume analysis skipped")
+        if all(c.open == c.high == c.low == c.close for c in ohlcv_data):
+            warnings.append("close prices only, OHLC analysis degraded")
+        
+        emit(
+            result['prediction'],
+            model_version=MODEL_VERSION,
+            quantiles=normal_quantiles(result['prediction'], factors.get('atr', 0)),
+            features=flatten_features({
+                'prediction': result.get('individual_predictions', {}),
+                'weight': result.get('weights', {}),
+                **factors,
+            }),
+            warnings=warnings,
+            timing_ms=timer.timing_ms(),
+        )
         
     except ValueError as e:
         print(f"Error parsing input: {e}", file=sys.stderr)
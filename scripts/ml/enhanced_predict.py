@@ -8,6 +8,10 @@ import sys
 import json
 import math
 import statistics
+
+from model_output import Timer, emit, flatten_features, normal_quantiles
+
+MODEL_VERSION = "enhanced-1.0.0"
 from typing import List, Dict, Tuple, Optional
 
 class TechnicalIndicators:
@@ -402,14 +406,30 @@ def main():
                 sys.exit(1)
         
         # Create predictor and make prediction
+        timer = Timer()
         predictor = EnhancedPredictor()
         result = predictor.ensemble_prediction(prices)
-        
-        # Output just the prediction (for compatibility with existing Go service)
-        print(f"{result['prediction']:.2f}")
-        
-        # Optionally output detailed results to stderr for debugging
-        # print(f"DEBUG: {json.dumps(result, indent=2)}", file=sys.stderr)
+        timer.mark('predict')
+        
+        warnings = []
+        if result['method'] == 'fallback':
+            warnings.append("insufficient data, returned fallback prediction")
+        elif len(prices) < 10:
+            warnings.append(f"only {len(prices)} prices, at least 10 recommended")
+        
+        volatility = result.get('confidence_factors', {}).get('volatility', 0.02)
+        emit(
+            result['prediction'],
+            model_version=MODEL_VERSION,
+            quantiles=normal_quantiles(result['prediction'], prices[-1] * volatility),
+            features=flatten_features({
+                'prediction': result.get('individual_predictions', {}),
+                'weight': result.get('weights', {}),
+                **result.get('confidence_factors', {}),
+            }),
+            warnings=warnings,
+            timing_ms=timer.timing_ms(),
+        )
         
     except ValueError as e:
         print(f"Error parsing prices: {e}", file=sys.stderr)
@@ -405,11 +405,11 @@ def main():
         
         # Output prediction (for compatibility with Go service)
         if isinstance(result, dict):
-            print(f"{result['prediction']:.2f}")
+            print(repr(float(result['prediction'])))
             # Optionally output detailed results to stderr for debugging
             # print(f"DEBUG: {json.dumps(result, indent=2, default=str)}", file=sys.stderr)
         else:
-            print(f"{result:.2f}")
+            print(repr(float(result)))
         
     except ValueError as e:
         print(f"Error parsing prices: {e}", file=sys.stderr)
@@ -0,0 +1,90 @@
+#!/usr/bin/env python3
+"""
+Versioned JSON output contract shared by the model scripts.
+
+The Go service reads the last stdout line holding a JSON object with a
+"version" field, so stray prints before it do not break parsing. Version 1:
+
+    {"version": 1, "predicted_price": 101.23,
+     "quantiles": [{"level": 0.1, "price": 99.8}, {"level": 0.9, "price": 102.5}],
+     "model_version": "enhanced-1", "features": {"volatility": 0.012},
+     "warnings": ["insufficient data"], "timing_ms": {"total": 1.8}}
+
+Only predicted_price is required. Scripts printing a bare float are still
+read as legacy output.
+"""
+
+import json
+import math
+import time
+from statistics import NormalDist
+from typing import Dict, List, Optional
+
+OUTPUT_VERSION = 1
+
+# Quantile levels reported by the built-in models: the median and the
+# bounds of the central 80% and 95% intervals
+QUANTILE_LEVELS = (0.025, 0.1, 0.5, 0.9, 0.975)
+
+
+class Timer:
+    """Collects named stage durations in milliseconds."""
+
+    def __init__(self):
+        self.started = time.perf_counter()
+        self.last = self.started
+        self.stages: Dict[str, float] = {}
+
+    def mark(self, stage: str):
+        now = time.perf_counter()
+        self.stages[stage] = (now - self.last) * 1000
+        self.last = now
+
+    def timing_ms(self) -> Dict[str, float]:
+        timing = dict(self.stages)
+        timing['total'] = (time.perf_counter() - self.started) * 1000
+        return timing
+
+
+def normal_quantiles(center: float, sigma: float) -> List[Dict[str, float]]:
+    """Quantiles of a normal forecast distribution around center."""
+    if sigma <= 0 or not math.isfinite(sigma):
+        return []
+    dist = NormalDist()
+    return [{'level': level, 'price': center + dist.inv_cdf(level) * sigma} for level in QUANTILE_LEVELS]
+
+
+def _finite(value) -> bool:
+    return isinstance(value, (int, float)) and not isinstance(value, bool) and math.isfinite(value)
+
+
+def flatten_features(values: Dict, prefix: str = '') -> Dict[str, float]:
+    """Flatten nested numeric values into {"a_b": 1.0}; other values are dropped."""
+    features = {}
+    for key, value in values.items():
+        name = f"{prefix}{key}"
+        if isinstance(value, dict):
+            features.update(flatten_features(value, name + '_'))
+        elif _finite(value):
+            features[name] = float(value)
+    return features
+
+
+def emit(predicted_price: float, model_version: Optional[str] = None,
+         quantiles: Optional[List[Dict[str, float]]] = None,
+         features: Optional[Dict[str, float]] = None,
+         warnings: Optional[List[str]] = None,
+         timing_ms: Optional[Dict[str, float]] = None):
+    """Print the prediction as one JSON line."""
+    output = {'version': OUTPUT_VERSION, 'predicted_price': float(predicted_price)}
+    if model_version:
+        output['model_version'] = model_version
+    if quantiles:
+        output['quantiles'] = [q for q in quantiles if _finite(q.get('price'))]
+    if features:
+        output['features'] = {k: float(v) for k, v in features.items() if _finite(v)}
+    if warnings:
+        output['warnings'] = list(warnings)
+    if timing_ms:
+        output['timing_ms'] = timing_ms
+    print(json.dumps(output))
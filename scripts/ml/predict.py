@@ -9,6 +9,13 @@ import sys
 import random
 import math
 
+from model_output import Timer, emit, normal_quantiles
+
+MODEL_VERSION = "simple-1.0.0"
+
+# Standard deviation of the deterministic noise, as a fraction of the price
+NOISE_FACTOR = 0.02
+
 def simple_linear_regression(x_values, y_values):
     """
     Simple linear regression implementation using only standard library.
@@ -58,7 +65,7 @@ def predict_price(prices):
     
     # Add some realistic randomness (simulate model uncertainty)
     # In production, this would be actual model uncertainty
-    noise_factor = 0.02  # 2% noise
+    noise_factor = NOISE_FACTOR  # 2% noise
     random.seed(int(sum(prices) * 1000) % 2147483647)  # Deterministic seed for consistency
     noise = random.gauss(0, predicted_price * noise_factor)
     predicted_price += noise
@@ -101,10 +108,26 @@ def main():
                 sys.exit(1)
         
         # Make prediction
+        timer = Timer()
         predicted_price = predict_price(prices)
+        timer.mark('predict')
+        
+        warnings = []
+        if len(prices) < 5:
+            warnings.append(f"only {len(prices)} prices, at least 5 recommended")
         
-        # Output prediction (Go service expects just the number)
-        print(f"{predicted_price:.2f}")
+        emit(
+            predicted_price,
+            model_version=MODEL_VERSION,
+            quantiles=normal_quantiles(predicted_price, prices[-1] * NOISE_FACTOR),
+            features={
+                'last_price': prices[-1],
+                'data_points': len(prices),
+                'change_pct': (predicted_price - prices[-1]) / prices[-1] * 100,
+            },
+            warnings=warnings,
+            timing_ms=timer.timing_ms(),
+        )
         
     except ValueError as e:
         print(f"Error parsing prices: {e}", file=sys.stderr)
@@ -0,0 +1,123 @@
+#!/usr/bin/env python3
+"""
+Persistent prediction worker for the Go worker pool.
+
+Reads one JSON request per line on stdin and writes one JSON response per
+line on stdout, so the interpreter and imported model libraries are loaded
+once instead of for every prediction. Model scripts are used unchanged:
+each request runs the script's main() with the usual command line argument
+and returns what it printed. A request naming an artifact runs the script
+with ML_MODEL_PATH set to it, so the registry's production version is the
+one loaded.
+
+Request:  {"id": 1, "script": "scripts/ml/predict.py", "input": "101.5,102.25"}
+          {"id": 3, "script": "scripts/ml/lstm_model.py", "input": "...",
+           "artifact": "persistent_data/ml_models/lstm/1.2.0"}
+          {"id": 2, "ping": true}
+Response: {"id": 1, "output": "103.10"}
+          {"id": 1, "error": "Prediction error: ..."}
+          {"id": 2, "ok": true}
+"""
+
+import contextlib
+import importlib.util
+import io
+import json
+import os
+import sys
+
+# Loaded model scripts by absolute path
+_modules = {}
+
+
+def load_script(path):
+    """Import a model script once and keep it loaded."""
+    path = os.path.abspath(path)
+    module = _modules.get(path)
+    if module is not None:
+        return module
+
+    script_dir = os.path.dirname(path)
+    if script_dir not in sys.path:
+        # Scripts import their siblings, e.g. lstm_model
+        sys.path.insert(0, script_dir)
+
+    name = "worker_" + os.path.splitext(os.path.basename(path))[0]
+    spec = importlib.util.spec_from_file_location(name, path)
+    if spec is None or spec.loader is None:
+        raise ImportError(f"cannot load model script: {path}")
+    module = importlib.util.module_from_spec(spec)
+    spec.loader.exec_module(module)
+    if not hasattr(module, "main"):
+        raise ImportError(f"model script has no main(): {path}")
+
+    _modules[path] = module
+    return module
+
+
+def run_script(path, model_input, artifact=None):
+    """Run a script's main() as if invoked from the command line."""
+    module = load_script(path)
+
+    stdout = io.StringIO()
+    stderr = io.StringIO()
+    argv = sys.argv
+    sys.argv = [path, model_input]
+    model_path = os.environ.get("ML_MODEL_PATH")
+    if artifact:
+        os.environ["ML_MODEL_PATH"] = artifact
+    try:
+        with contextlib.redirect_stdout(stdout), contextlib.redirect_stderr(stderr):
+            try:
+                module.main()
+            except SystemExit as e:
+                if e.code not in (None, 0):
+                    message = stderr.getvalue().strip() or f"exit status {e.code}"
+                    raise RuntimeError(message) from None
+    finally:
+        sys.argv = argv
+        if artifact:
+            if model_path is None:
+                os.environ.pop("ML_MODEL_PATH", None)
+            else:
+                os.environ["ML_MODEL_PATH"] = model_path
+
+    return stdout.getvalue()
+
+
+def handle(request):
+    """Build the response for one request."""
+    response = {"id": request.get("id")}
+    if request.get("ping"):
+        response["ok"] = True
+        return response
+
+    try:
+        response["output"] = run_script(request["script"], request["input"], request.get("artifact"))
+    except Exception as e:
+        response["error"] = str(e) or e.__class__.__name__
+    return response
+
+
+def main():
+    # Model output goes to the response; only protocol lines reach stdout
+    protocol = sys.stdout
+    sys.stdout = sys.stderr
+
+    for line in sys.stdin:
+        line = line.strip()
+        if not line:
+            continue
+        try:
+            request = json.loads(line)
+        except ValueError as e:
+            response = {"id": None, "error": f"invalid request: {e}"}
+        else:
+            response = handle(request)
+
+        protocol.write(json.dumps(response) + "\n")
+        protocol.flush()
+
+
+if __name__ == "__main__":
+    main()